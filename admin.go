@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var adminTokenArg = flag.String("adminToken", "", "token that authorizes requests to the web server's /admin endpoints, and can read everything else too; empty disables them")
+
+// scanControls runs the scanner's work on demand rather than on its schedule. Each returns a short description of
+// what it did.
+type scanControls struct {
+	scan   func() (string, error)
+	flush  func(ctx context.Context) (string, error)
+	digest func(kind string) (string, error)
+}
+
+// errTooSoon is returned when a scan is asked for before the leaderboard can be downloaded again.
+type errTooSoon struct {
+	next time.Time
+}
+
+func (e errTooSoon) Error() string {
+	return fmt.Sprintf("too soon since the last download; the next one is allowed at %s", e.next.Format(time.RFC3339))
+}
+
+// adminHandler wraps an /admin endpoint so that it only answers authorized POSTs, reporting the outcome as json.
+func (s *server) adminHandler(action func(r *http.Request) (string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(*adminTokenArg) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		if grantedAccess(r) < accessAdmin {
+			writeUnauthorized(w, "a valid admin token is required")
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "only POST is supported")
+			return
+		}
+
+		if s.scanner == nil {
+			writeAPIError(w, http.StatusConflict, "this server isn't scanning; run it with serve -scan to use the admin endpoints")
+			return
+		}
+
+		logInfo("Admin request for", r.URL.Path, "from", r.RemoteAddr)
+		result, err := action(r)
+		if err != nil {
+			var tooSoon errTooSoon
+			var reqErr apiRequestError
+			switch {
+			case errors.As(err, &tooSoon):
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(tooSoon.next).Seconds()))))
+				writeAPIError(w, http.StatusTooManyRequests, err.Error())
+			case errors.As(err, &reqErr):
+				writeAPIError(w, reqErr.status, reqErr.message)
+			case errors.Is(err, errNoData):
+				writeAPIError(w, http.StatusConflict, err.Error())
+			default:
+				writeAPIError(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+
+		writeJSON(w, http.StatusOK, struct {
+			Result string `json:"result"`
+		}{result})
+	}
+}
+
+func (s *server) handleAdminScan(r *http.Request) (string, error) {
+	return s.scanner.scan()
+}
+
+func (s *server) handleAdminFlush(r *http.Request) (string, error) {
+	return s.scanner.flush(r.Context())
+}
+
+func (s *server) handleAdminDigest(r *http.Request) (string, error) {
+	kind := r.URL.Query().Get("kind")
+	switch kind {
+	case "":
+		kind = "daily"
+	case "daily", "weekly", "final", "combined":
+	default:
+		return "", apiRequestError{http.StatusBadRequest, fmt.Sprintf("unknown digest kind %q; use daily, weekly, final, or combined", kind)}
+	}
+
+	return s.scanner.digest(kind)
+}
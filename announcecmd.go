@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// announcementMember is a member's standing as seen by an announcement template.
+type announcementMember struct {
+	Rank  int
+	ID    int
+	Name  string
+	Stars int
+	Score int
+}
+
+// announcementData is what an announcement template is executed with.
+type announcementData struct {
+	Year        string
+	Leaderboard string
+	URL         string
+	// Members are in standings order, so the first is the leader.
+	Members    []announcementMember
+	Leader     announcementMember
+	TotalStars int
+	// MaxStars is how many stars each member can earn this year.
+	MaxStars int
+	// Now is the current time in the leaderboard's timezone.
+	Now time.Time
+}
+
+func newAnnouncementData(leaderboard *leaderboardData, year string, board leaderboardSettings) announcementData {
+	data := announcementData{
+		Year:        year,
+		Leaderboard: board.ID,
+		URL:         fmt.Sprintf("https://adventofcode.com/%s/leaderboard/private/view/%s", year, board.ID),
+		MaxStars:    maxStars(year),
+		Now:         time.Now().In(board.Location),
+	}
+
+	for idx, member := range rankedStandings(leaderboard, year) {
+		data.Members = append(data.Members, announcementMember{
+			Rank:  idx + 1,
+			ID:    member.ID,
+			Name:  displayName(member),
+			Stars: member.Stars,
+			Score: member.LocalScore,
+		})
+		data.TotalStars += member.Stars
+	}
+	if len(data.Members) > 0 {
+		data.Leader = data.Members[0]
+	}
+
+	return data
+}
+
+var announcementFuncs = template.FuncMap{
+	// top returns the first n members, e.g. {{range top 3 .Members}}
+	"top": func(n int, members []announcementMember) []announcementMember {
+		if n < len(members) {
+			return members[:n]
+		}
+		return members
+	},
+}
+
+// renderAnnouncement executes an announcement template against the leaderboard's current standings.
+func renderAnnouncement(text string, data announcementData) (string, error) {
+	tmpl, parseErr := template.New("announcement").Funcs(announcementFuncs).Parse(text)
+	if parseErr != nil {
+		return "", fmt.Errorf("error parsing announcement template: %w", parseErr)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("error rendering announcement: %w", err)
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func runAnnounceCommand(args []string) error {
+	fs := flag.NewFlagSet("announce", flag.ContinueOnError)
+	file := fs.String("file", "", "read the message template from this file instead of the arguments")
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	dryRun := fs.Bool("dryRun", false, "print the message instead of sending it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	text := strings.Join(fs.Args(), " ")
+	if len(*file) > 0 {
+		if fs.NArg() > 0 {
+			return errors.New("usage: announce [-cached] [-dryRun] <message> | announce [-cached] [-dryRun] -file <template>")
+		}
+		contents, readErr := os.ReadFile(*file)
+		if readErr != nil {
+			return fmt.Errorf("error reading %s: %w", *file, readErr)
+		}
+		text = string(contents)
+	}
+	if len(strings.TrimSpace(text)) == 0 {
+		return errors.New("usage: announce [-cached] [-dryRun] <message> | announce [-cached] [-dryRun] -file <template>")
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	var s *scanner
+	if !*dryRun {
+		// announcements are recorded in the store the same way a scan's notifications are
+		var scannerErr error
+		if s, scannerErr = newScanner(store); scannerErr != nil {
+			return scannerErr
+		}
+	}
+
+	leaderboard, board, loadErr := loadStoredLeaderboard(store, partition, *cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	message, renderErr := renderAnnouncement(text, newAnnouncementData(leaderboard, *yearArg, board))
+	if renderErr != nil {
+		return renderErr
+	}
+	if len(message) == 0 {
+		return errors.New("the announcement rendered to an empty message; nothing to send")
+	}
+
+	if *dryRun {
+		fmt.Println(message)
+		return nil
+	}
+
+	return s.sendNotification(context.Background(), message)
+}
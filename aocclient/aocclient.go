@@ -0,0 +1,185 @@
+// Package aocclient downloads private leaderboards from adventofcode.com.
+package aocclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"pernicious.games/advent-of-code-scanner/leaderboard"
+)
+
+// ErrSessionRejected is returned when adventofcode.com refuses a session cookie, usually by serving the login page
+// instead of the leaderboard json.
+var ErrSessionRejected = errors.New("session cookie was rejected")
+
+// ErrNotModified is returned by FetchIfModified when the leaderboard hasn't changed since the version it was given.
+var ErrNotModified = errors.New("leaderboard hasn't changed")
+
+// StatusError is returned when the site answers with a status other than 200 OK or 304 Not Modified.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d downloading leaderboard", e.StatusCode)
+}
+
+// Validators identify a version of a leaderboard download, so that asking for it again only downloads it if it's
+// changed. The zero value matches nothing.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// DefaultBaseURL is where the site lives.
+const DefaultBaseURL = "https://adventofcode.com"
+
+// Client downloads leaderboards. The zero value is ready to use. The site asks that a leaderboard not be downloaded more
+// than once every 15 minutes, which it's up to the caller to respect.
+type Client struct {
+	// HTTPClient makes the requests. Nil means http.DefaultClient.
+	HTTPClient *http.Client
+	// BaseURL replaces DefaultBaseURL, for tests.
+	BaseURL string
+	// OnResponse, if set, is given every response along with its body, whatever its status.
+	OnResponse func(resp *http.Response, body []byte)
+}
+
+// Fetch downloads the json of a private leaderboard using the given session cookie. Cancelling ctx abandons the download.
+func (c *Client) Fetch(ctx context.Context, year, leaderboardID, session string) ([]byte, error) {
+	body, _, err := c.FetchIfModified(ctx, year, leaderboardID, session, Validators{})
+	return body, err
+}
+
+// FetchIfModified is Fetch, except that it returns ErrNotModified instead of downloading the leaderboard again if it
+// hasn't changed since the version that since came from. It also returns the validators of what it downloaded, to
+// pass to the next call.
+func (c *Client) FetchIfModified(ctx context.Context, year, leaderboardID, session string, since Validators) ([]byte, Validators, error) {
+	resp, err := c.get(ctx, year, leaderboardID, session, since)
+	if err != nil {
+		return nil, Validators{}, err
+	}
+	defer resp.Body.Close()
+
+	read, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		return nil, Validators{}, fmt.Errorf("error reading response body: %w", readErr)
+	}
+	if c.OnResponse != nil {
+		c.OnResponse(resp, read)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, since, ErrNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, Validators{}, StatusError{StatusCode: resp.StatusCode}
+	}
+	// an invalid or expired session gets redirected to an html page asking the user to log in rather than an error
+	if trimmed := bytes.TrimSpace(read); len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, Validators{}, ErrSessionRejected
+	}
+
+	return read, Validators{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}, nil
+}
+
+// get requests a leaderboard, leaving it to the caller to check the response and close its body.
+func (c *Client) get(ctx context.Context, year, leaderboardID, session string, since Validators) (*http.Response, error) {
+	base := c.BaseURL
+	if len(base) == 0 {
+		base = DefaultBaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s/leaderboard/private/view/%s.json", base, year, leaderboardID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for leaderboard: %w", err)
+	}
+	if len(since.ETag) > 0 {
+		req.Header.Set("If-None-Match", since.ETag)
+	}
+	if len(since.LastModified) > 0 {
+		req.Header.Set("If-Modified-Since", since.LastModified)
+	}
+
+	req.AddCookie(&http.Cookie{
+		Name:     "session",
+		Value:    session,
+		Path:     "/",
+		Domain:   ".adventofcode.com",
+		Secure:   true,
+		HttpOnly: true,
+	})
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, reqErr := client.Do(req)
+	if reqErr != nil {
+		return nil, fmt.Errorf("error attempting to download leaderboard: %w", reqErr)
+	}
+	return resp, nil
+}
+
+// Leaderboard downloads and parses a private leaderboard. Unless OnResponse is set, which needs the whole body, the
+// leaderboard is decoded as it's downloaded rather than after reading the whole response.
+func (c *Client) Leaderboard(ctx context.Context, year, leaderboardID, session string) (*leaderboard.Leaderboard, error) {
+	if c.OnResponse == nil {
+		return c.decodeLeaderboard(ctx, year, leaderboardID, session)
+	}
+
+	body, fetchErr := c.Fetch(ctx, year, leaderboardID, session)
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	lb, parseErr := leaderboard.Parse(body)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return &lb, nil
+}
+
+func (c *Client) decodeLeaderboard(ctx context.Context, year, leaderboardID, session string) (*leaderboard.Leaderboard, error) {
+	resp, err := c.get(ctx, year, leaderboardID, session, Validators{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, StatusError{StatusCode: resp.StatusCode}
+	}
+
+	// as with FetchIfModified, anything but json means the session was rejected
+	body := bufio.NewReader(resp.Body)
+	if !startsWithObject(body) {
+		return nil, ErrSessionRejected
+	}
+
+	lb, decodeErr := leaderboard.Decode(body)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	return &lb, nil
+}
+
+// startsWithObject reports whether the first thing in r other than whitespace is the start of a json object, leaving
+// it to be read.
+func startsWithObject(r *bufio.Reader) bool {
+	for {
+		next, err := r.Peek(1)
+		if err != nil {
+			return false
+		}
+		switch next[0] {
+		case ' ', '\t', '\r', '\n':
+			r.Discard(1)
+		default:
+			return next[0] == '{'
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package aocclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// GlobalDay is the site's public leaderboard for one day: how long after the puzzle unlocked each of the first hundred
+// people to get each star did, fastest first.
+type GlobalDay struct {
+	Part1 []time.Duration
+	Part2 []time.Duration
+}
+
+// globalEntryTime matches an entry's time on a day's global leaderboard, which is the date and time of day it was
+// earned in the puzzle's timezone, e.g. "Dec 05  00:03:58".
+var globalEntryTime = regexp.MustCompile(`class="leaderboard-time">[A-Z][a-z]{2} (\d{2})\s+(\d{2}):(\d{2}):(\d{2})<`)
+
+// globalFirstStars marks where the list of people who got both stars ends and the list of those who got the first
+// begins.
+var globalFirstStars = []byte(`leaderboard-daydesc-first`)
+
+// GlobalDay downloads the public leaderboard of a day, which doesn't need a session. The site answers with a 404 for
+// days that haven't unlocked, and for events whose global leaderboard it doesn't run.
+func (c *Client) GlobalDay(ctx context.Context, year string, day int) (GlobalDay, error) {
+	base := c.BaseURL
+	if len(base) == 0 {
+		base = DefaultBaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s/leaderboard/day/%d", base, year, day), nil)
+	if err != nil {
+		return GlobalDay{}, fmt.Errorf("error creating request for global leaderboard: %w", err)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, reqErr := client.Do(req)
+	if reqErr != nil {
+		return GlobalDay{}, fmt.Errorf("error attempting to download global leaderboard: %w", reqErr)
+	}
+	defer resp.Body.Close()
+
+	body, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		return GlobalDay{}, fmt.Errorf("error reading response body: %w", readErr)
+	}
+	if c.OnResponse != nil {
+		c.OnResponse(resp, body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return GlobalDay{}, StatusError{StatusCode: resp.StatusCode}
+	}
+
+	return ParseGlobalDay(body, day)
+}
+
+// ParseGlobalDay reads the times out of the html of a day's public leaderboard.
+func ParseGlobalDay(page []byte, day int) (GlobalDay, error) {
+	var global GlobalDay
+	split := bytes.Index(page, globalFirstStars)
+	if split < 0 {
+		split = len(page)
+	}
+
+	for idx, match := range globalEntryTime.FindAllSubmatchIndex(page, -1) {
+		var fields [4]int
+		for field := range fields {
+			value, err := strconv.Atoi(string(page[match[2+field*2]:match[3+field*2]]))
+			if err != nil {
+				return GlobalDay{}, fmt.Errorf("error parsing time of global leaderboard entry %d: %w", idx+1, err)
+			}
+			fields[field] = value
+		}
+		elapsed := time.Duration(fields[0]-day)*24*time.Hour + time.Duration(fields[1])*time.Hour + time.Duration(fields[2])*time.Minute + time.Duration(fields[3])*time.Second
+
+		if match[0] < split {
+			global.Part2 = append(global.Part2, elapsed)
+		} else {
+			global.Part1 = append(global.Part1, elapsed)
+		}
+	}
+
+	if len(global.Part1) == 0 && len(global.Part2) == 0 {
+		return GlobalDay{}, fmt.Errorf("no entries found on the day %d global leaderboard", day)
+	}
+	return global, nil
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// apiLeaderboard is the response to /api/leaderboard.
+type apiLeaderboard struct {
+	Year        string           `json:"year"`
+	Leaderboard string           `json:"leaderboard"`
+	Updated     time.Time        `json:"updated"`
+	Members     []exportedMember `json:"members"`
+}
+
+// apiStandings is the response to /api/standings.
+type apiStandings struct {
+	Year        string         `json:"year"`
+	Leaderboard string         `json:"leaderboard"`
+	Mode        string         `json:"mode"`
+	Updated     time.Time      `json:"updated"`
+	Members     []scoredMember `json:"members"`
+}
+
+// apiFinisher is one member's completion of one part of a day, in /api/days/{n}.
+type apiFinisher struct {
+	// Rank is 0 for a member in excludeMembers.
+	Rank int       `json:"rank"`
+	ID   int       `json:"id"`
+	Name string    `json:"name"`
+	At   time.Time `json:"at"`
+	// Elapsed is how many seconds after the puzzle unlocked the star was earned.
+	Elapsed int64 `json:"elapsed_seconds"`
+}
+
+// apiDay is the response to /api/days/{n}.
+type apiDay struct {
+	Day    int           `json:"day"`
+	Unlock time.Time     `json:"unlock"`
+	Part1  []apiFinisher `json:"part1"`
+	Part2  []apiFinisher `json:"part2"`
+}
+
+// apiSolveTime is one member's times on one day, in /api/days/{n}/times. Times are seconds from the puzzle's unlock,
+// and are null for parts the member hasn't finished.
+type apiSolveTime struct {
+	ID    int        `json:"id"`
+	Name  string     `json:"name"`
+	Part1 *int64     `json:"part1_seconds"`
+	Part2 *int64     `json:"part2_seconds"`
+	Delta *int64     `json:"delta_seconds"`
+	At1   *time.Time `json:"part1_at"`
+	At2   *time.Time `json:"part2_at"`
+}
+
+// apiDayTimes is the response to /api/days/{n}/times.
+type apiDayTimes struct {
+	Day     int            `json:"day"`
+	Unlock  time.Time      `json:"unlock"`
+	Members []apiSolveTime `json:"members"`
+}
+
+// apiPartStats is the spread of one part's solve times in /api/days/{n}/stats, in seconds from the puzzle's unlock.
+// The quartiles are null when nobody has finished the part.
+type apiPartStats struct {
+	Solvers int    `json:"solvers"`
+	P25     *int64 `json:"p25_seconds"`
+	Median  *int64 `json:"median_seconds"`
+	P75     *int64 `json:"p75_seconds"`
+}
+
+// apiPlacing is where one member fell on one day in /api/days/{n}/stats. Times and percentiles are null for parts the
+// member hasn't finished.
+type apiPlacing struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Part1       *int64   `json:"part1_seconds"`
+	Percentile1 *float64 `json:"part1_percentile"`
+	Part2       *int64   `json:"part2_seconds"`
+	Percentile2 *float64 `json:"part2_percentile"`
+}
+
+// apiDayStats is the response to /api/days/{n}/stats.
+type apiDayStats struct {
+	Day     int          `json:"day"`
+	Unlock  time.Time    `json:"unlock"`
+	Part1   apiPartStats `json:"part1"`
+	Part2   apiPartStats `json:"part2"`
+	Members []apiPlacing `json:"members"`
+}
+
+// apiSnapshot is one entry in the response to /api/snapshots.
+type apiSnapshot struct {
+	ID        int64     `json:"id"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// errNoData is returned when the store doesn't have what a request asked for.
+var errNoData = errors.New("no leaderboard data has been scanned yet")
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{message})
+}
+
+// apiHandler wraps an API endpoint so that it only answers GETs and reports errors as json.
+func apiHandler(handle func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeAPIError(w, http.StatusMethodNotAllowed, "only GET is supported")
+			return
+		}
+
+		if err := handle(w, r); err != nil {
+			var reqErr apiRequestError
+			switch {
+			case errors.As(err, &reqErr):
+				writeAPIError(w, reqErr.status, reqErr.message)
+			case errors.Is(err, errNoData):
+				writeAPIError(w, http.StatusNotFound, err.Error())
+			default:
+				logError("Error serving", r.URL.Path, ":", err)
+				writeAPIError(w, http.StatusInternalServerError, "error loading leaderboard")
+			}
+		}
+	}
+}
+
+// apiRequestError is an error that's the client's fault, reported with its own status.
+type apiRequestError struct {
+	status  int
+	message string
+}
+
+func (e apiRequestError) Error() string {
+	return e.message
+}
+
+// requestedLeaderboard returns the leaderboard a request is asking about: the cached one, or the one in the snapshot
+// given as ?snapshot=<id>.
+func (s *server) requestedLeaderboard(r *http.Request) (*leaderboardData, time.Time, error) {
+	if id := r.URL.Query().Get("snapshot"); len(id) > 0 {
+		snapID, parseErr := strconv.ParseInt(id, 10, 64)
+		if parseErr != nil {
+			return nil, time.Time{}, apiRequestError{http.StatusBadRequest, fmt.Sprintf("invalid snapshot id %q", id)}
+		}
+		return s.snapshotLeaderboard(snapID)
+	}
+
+	return s.latestLeaderboard()
+}
+
+// snapshotLeaderboard returns the leaderboard as it was in one of its stored snapshots, and when that was fetched.
+func (s *server) snapshotLeaderboard(snapID int64) (*leaderboardData, time.Time, error) {
+	history := historyFor(s.store)
+	if history == nil {
+		return nil, time.Time{}, apiRequestError{http.StatusNotFound, "the store doesn't keep leaderboard history"}
+	}
+
+	// stores don't agree on how to report a missing snapshot, so check it's one of this leaderboard's first
+	snaps, listErr := history.Snapshots(s.partition)
+	if listErr != nil {
+		return nil, time.Time{}, listErr
+	}
+	if !arrayContains(snaps, func(snap snapshot) bool { return snap.ID == snapID }) {
+		return nil, time.Time{}, apiRequestError{http.StatusNotFound, fmt.Sprintf("no snapshot %d of this leaderboard", snapID)}
+	}
+
+	snap, leaderboard, loadErr := loadSnapshotLeaderboard(history, snapID)
+	if loadErr != nil {
+		return nil, time.Time{}, loadErr
+	}
+	return leaderboard, snap.FetchedAt, nil
+}
+
+// latestLeaderboard is cachedLeaderboard for the API, which reports errNoData when nothing has been scanned yet.
+func (s *server) latestLeaderboard() (*leaderboardData, time.Time, error) {
+	leaderboard, state, loadErr := s.cachedLeaderboard()
+	if loadErr != nil {
+		return nil, time.Time{}, loadErr
+	}
+	if leaderboard == nil {
+		return nil, time.Time{}, errNoData
+	}
+	return leaderboard, time.Unix(state.LastRead, 0), nil
+}
+
+// pathID returns what follows prefix in a request's path, parsed as a number.
+func pathID(path, prefix string) (int, error) {
+	rest := strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	id, err := strconv.Atoi(rest)
+	if err != nil || strings.Contains(rest, "/") {
+		return 0, apiRequestError{http.StatusNotFound, "not found"}
+	}
+	return id, nil
+}
+
+func (s *server) handleAPILeaderboard(w http.ResponseWriter, r *http.Request) error {
+	leaderboard, updated, loadErr := s.requestedLeaderboard(r)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	writeJSON(w, http.StatusOK, s.apiLeaderboard(leaderboard, updated))
+	return nil
+}
+
+func (s *server) apiLeaderboard(leaderboard *leaderboardData, updated time.Time) apiLeaderboard {
+	return apiLeaderboard{
+		Year:        s.partition.Year,
+		Leaderboard: s.partition.Leaderboard,
+		Updated:     updated.In(s.board.Location),
+		Members:     exportMembers(leaderboard, s.board),
+	}
+}
+
+// handleAPIStandings ranks the leaderboard under the scoring mode given as ?mode=, or the one set by scoring.
+func (s *server) handleAPIStandings(w http.ResponseWriter, r *http.Request) error {
+	name := r.URL.Query().Get("mode")
+	if len(name) == 0 {
+		name = *scoringArg
+	}
+	mode, ok := scoringModes[name]
+	if !ok {
+		return apiRequestError{http.StatusBadRequest, fmt.Sprintf("unknown scoring mode %q; expected one of %s", name, strings.Join(scoringModeNames(), ", "))}
+	}
+	leaderboard, updated, loadErr := s.requestedLeaderboard(r)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	writeJSON(w, http.StatusOK, s.apiStandings(leaderboard, updated, name, mode))
+	return nil
+}
+
+func (s *server) apiStandings(leaderboard *leaderboardData, updated time.Time, name string, mode scoringMode) apiStandings {
+	return apiStandings{
+		Year:        s.partition.Year,
+		Leaderboard: s.partition.Leaderboard,
+		Mode:        name,
+		Updated:     updated.In(s.board.Location),
+		Members:     scoreStandings(leaderboard, s.partition.Year, mode),
+	}
+}
+
+func (s *server) handleAPIMember(w http.ResponseWriter, r *http.Request) error {
+	id, idErr := pathID(r.URL.Path, "/api/members/")
+	if idErr != nil {
+		return idErr
+	}
+	leaderboard, _, loadErr := s.requestedLeaderboard(r)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	member := arrayFind(exportMembers(leaderboard, s.board), func(m exportedMember) bool { return m.ID == id })
+	if member == nil {
+		return apiRequestError{http.StatusNotFound, fmt.Sprintf("member %d isn't on the leaderboard", id)}
+	}
+
+	writeJSON(w, http.StatusOK, member)
+	return nil
+}
+
+func (s *server) handleAPIDay(w http.ResponseWriter, r *http.Request) error {
+	if strings.HasSuffix(r.URL.Path, "/times") {
+		return s.handleAPIDayTimes(w, r)
+	}
+	if strings.HasSuffix(r.URL.Path, "/stats") {
+		return s.handleAPIDayStats(w, r)
+	}
+
+	day, dayErr := pathID(r.URL.Path, "/api/days/")
+	if dayErr != nil {
+		return dayErr
+	}
+	if day < 1 || day > eventDays(s.partition.Year) {
+		return apiRequestError{http.StatusNotFound, fmt.Sprintf("%s doesn't have a day %d", s.partition.Year, day)}
+	}
+	leaderboard, _, loadErr := s.requestedLeaderboard(r)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	writeJSON(w, http.StatusOK, s.apiDay(leaderboard, day))
+	return nil
+}
+
+func (s *server) apiDay(leaderboard *leaderboardData, day int) apiDay {
+	return apiDay{
+		Day:    day,
+		Unlock: dayUnlock(s.partition.Year, day).In(s.board.Location),
+		Part1:  s.apiFinishers(leaderboard, s.partition.Year, day, 1),
+		Part2:  s.apiFinishers(leaderboard, s.partition.Year, day, 2),
+	}
+}
+
+// handleAPIDayTimes serves /api/days/{n}/times: how long everyone who has started a day took on each part.
+func (s *server) handleAPIDayTimes(w http.ResponseWriter, r *http.Request) error {
+	day, dayErr := pathID(strings.TrimSuffix(r.URL.Path, "/times"), "/api/days/")
+	if dayErr != nil {
+		return dayErr
+	}
+	if day < 1 || day > eventDays(s.partition.Year) {
+		return apiRequestError{http.StatusNotFound, fmt.Sprintf("%s doesn't have a day %d", s.partition.Year, day)}
+	}
+	leaderboard, _, loadErr := s.requestedLeaderboard(r)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	writeJSON(w, http.StatusOK, s.apiDayTimes(leaderboard, day))
+	return nil
+}
+
+func (s *server) apiDayTimes(leaderboard *leaderboardData, day int) apiDayTimes {
+	unlock := dayUnlock(s.partition.Year, day)
+	since := func(part *completionPartData) (*int64, *time.Time) {
+		if part == nil {
+			return nil, nil
+		}
+		at := time.Unix(part.GotStarAt, 0).In(s.board.Location)
+		elapsed := int64(at.Sub(unlock) / time.Second)
+		return &elapsed, &at
+	}
+
+	times := []apiSolveTime{}
+	for _, f := range dayFinishers(leaderboard, day, 1) {
+		completion := f.Member.CompletionDayLevel[day-1]
+		t := apiSolveTime{ID: f.Member.ID, Name: displayName(f.Member)}
+		t.Part1, t.At1 = since(completion.Part1)
+		t.Part2, t.At2 = since(completion.Part2)
+		if t.Part2 != nil {
+			delta := *t.Part2 - *t.Part1
+			t.Delta = &delta
+		}
+		times = append(times, t)
+	}
+	// whoever finished the whole day first comes first, then everyone who's only got the first star
+	sort.SliceStable(times, func(i, j int) bool {
+		if (times[i].Part2 == nil) != (times[j].Part2 == nil) {
+			return times[i].Part2 != nil
+		}
+		return times[i].Part2 != nil && *times[i].Part2 < *times[j].Part2
+	})
+
+	return apiDayTimes{Day: day, Unlock: unlock.In(s.board.Location), Members: times}
+}
+
+// handleAPIDayStats serves /api/days/{n}/stats: the median and quartiles of a day's solve times, and each member's
+// percentile.
+func (s *server) handleAPIDayStats(w http.ResponseWriter, r *http.Request) error {
+	day, dayErr := pathID(strings.TrimSuffix(r.URL.Path, "/stats"), "/api/days/")
+	if dayErr != nil {
+		return dayErr
+	}
+	if day < 1 || day > eventDays(s.partition.Year) {
+		return apiRequestError{http.StatusNotFound, fmt.Sprintf("%s doesn't have a day %d", s.partition.Year, day)}
+	}
+	leaderboard, _, loadErr := s.requestedLeaderboard(r)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	writeJSON(w, http.StatusOK, newAPIDayStats(computeDayStats(leaderboard, s.partition.Year, day), s.partition.Year, s.board.Location))
+	return nil
+}
+
+func newAPIDayStats(stats daySolveStats, year string, loc *time.Location) apiDayStats {
+	seconds := func(d time.Duration) *int64 {
+		s := int64(d / time.Second)
+		return &s
+	}
+	partStats := func(p partSolveStats) apiPartStats {
+		out := apiPartStats{Solvers: p.Solvers}
+		if p.Solvers > 0 {
+			out.P25, out.Median, out.P75 = seconds(p.P25), seconds(p.Median), seconds(p.P75)
+		}
+		return out
+	}
+	placing := func(p *solvePlacing) (*int64, *float64) {
+		if p == nil {
+			return nil, nil
+		}
+		percentile := math.Round(p.Percentile*10) / 10
+		return seconds(p.Time), &percentile
+	}
+
+	out := apiDayStats{
+		Day:     stats.Day,
+		Unlock:  dayUnlock(year, stats.Day).In(loc),
+		Part1:   partStats(stats.Part1),
+		Part2:   partStats(stats.Part2),
+		Members: []apiPlacing{},
+	}
+	for _, member := range stats.Members {
+		p := apiPlacing{ID: member.Member.ID, Name: displayName(member.Member)}
+		p.Part1, p.Percentile1 = placing(member.Part1)
+		p.Part2, p.Percentile2 = placing(member.Part2)
+		out.Members = append(out.Members, p)
+	}
+	return out
+}
+
+// apiFinishers returns everyone who has completed the given part of the given day, fastest first.
+func (s *server) apiFinishers(leaderboard *leaderboardData, year string, day, part int) []apiFinisher {
+	unlock := dayUnlock(year, day)
+	out := []apiFinisher{}
+	finishers := dayFinishers(leaderboard, day, part)
+	ranks := finisherRanks(finishers)
+	for idx, f := range finishers {
+		out = append(out, apiFinisher{
+			Rank:    ranks[idx],
+			ID:      f.Member.ID,
+			Name:    displayName(f.Member),
+			At:      f.At.In(s.board.Location),
+			Elapsed: int64(f.At.Sub(unlock) / time.Second),
+		})
+	}
+	return out
+}
+
+func (s *server) handleAPISnapshots(w http.ResponseWriter, r *http.Request) error {
+	history := historyFor(s.store)
+	if history == nil {
+		return apiRequestError{http.StatusNotFound, "the store doesn't keep leaderboard history"}
+	}
+
+	snaps, listErr := history.Snapshots(s.partition)
+	if listErr != nil {
+		return listErr
+	}
+
+	out := []apiSnapshot{}
+	for _, snap := range snaps {
+		out = append(out, apiSnapshot{ID: snap.ID, FetchedAt: snap.FetchedAt.In(s.board.Location)})
+	}
+	writeJSON(w, http.StatusOK, out)
+	return nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var archiveDirArg = flag.String("archiveDir", "", "directory to keep a gzip-compressed copy of every downloaded leaderboard in")
+
+const archiveSuffix = ".json.gz"
+
+// archiveSecondIDs is the first snapshot ID that's in milliseconds rather than seconds. Archives written before IDs
+// had to be unique across leaderboards named their files by the unix time alone.
+const archiveSecondIDs = 100_000_000_000
+
+// archiveMu keeps scan workers saving snapshots of different leaderboards at once from taking the same ID.
+var archiveMu sync.Mutex
+
+// archiveHistory is a historyStore that keeps every downloaded leaderboard body as its own gzip-compressed,
+// timestamped file in a directory. Snapshot IDs are the unix time the body was fetched in milliseconds, counting up
+// from there for each other leaderboard fetched in the same second, so that every file in the directory has its own.
+type archiveHistory struct {
+	dir string
+}
+
+// historyFor returns where leaderboard history should be recorded and read from: the archive directory when one is
+// configured, otherwise the store itself if it keeps history, otherwise nil.
+func historyFor(store stateStore) historyStore {
+	if len(*archiveDirArg) > 0 {
+		return &archiveHistory{dir: *archiveDirArg}
+	}
+
+	if history, ok := store.(historyStore); ok {
+		return history
+	}
+
+	return nil
+}
+
+func (a *archiveHistory) fileName(snap snapshot) string {
+	return fmt.Sprintf("%d-%s-%s%s", snap.ID, snap.Year, snap.Leaderboard, archiveSuffix)
+}
+
+// snapshotFiles is the files in the directory holding the snapshot with the given ID.
+func (a *archiveHistory) snapshotFiles(id int64) []string {
+	matches, _ := filepath.Glob(filepath.Join(a.dir, fmt.Sprintf("%d-*%s", id, archiveSuffix)))
+	return matches
+}
+
+// snapshotID picks the ID to save a snapshot under: the first one from when it was fetched that no other leaderboard's
+// snapshot has, or the one its partition already has for that second, so that saving it again replaces it. The
+// caller holds archiveMu.
+func (a *archiveHistory) snapshotID(snap snapshot) (int64, error) {
+	// an archive written before IDs were in milliseconds names the partition's snapshot for the second by the second
+	legacy := snap
+	legacy.ID = snap.FetchedAt.Unix()
+	if _, err := os.Stat(filepath.Join(a.dir, a.fileName(legacy))); err == nil {
+		return legacy.ID, nil
+	}
+
+	for id := snap.FetchedAt.Unix() * 1000; id < (snap.FetchedAt.Unix()+1)*1000; id++ {
+		snap.ID = id
+		matches := a.snapshotFiles(id)
+		if len(matches) == 0 || slices.Contains(matches, filepath.Join(a.dir, a.fileName(snap))) {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("too many snapshots fetched at %s to archive another", snap.FetchedAt)
+}
+
+// parseFileName reverses fileName, returning false for files that aren't snapshots.
+func (a *archiveHistory) parseFileName(name string) (snapshot, bool) {
+	trimmed, ok := strings.CutSuffix(name, archiveSuffix)
+	if !ok {
+		return snapshot{}, false
+	}
+
+	parts := strings.SplitN(trimmed, "-", 3)
+	if len(parts) != 3 {
+		return snapshot{}, false
+	}
+
+	id, parseErr := strconv.ParseInt(parts[0], 10, 64)
+	if parseErr != nil {
+		return snapshot{}, false
+	}
+
+	fetchedAt := time.Unix(id, 0)
+	if id >= archiveSecondIDs {
+		fetchedAt = time.Unix(id/1000, 0)
+	}
+	return snapshot{ID: id, FetchedAt: fetchedAt, Year: parts[1], Leaderboard: parts[2]}, true
+}
+
+func (a *archiveHistory) SaveSnapshot(snap snapshot) error {
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return fmt.Errorf("error creating archive directory %s: %w", a.dir, err)
+	}
+
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+	id, idErr := a.snapshotID(snap)
+	if idErr != nil {
+		return idErr
+	}
+	snap.ID = id
+
+	path := filepath.Join(a.dir, a.fileName(snap))
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.ModTime = snap.FetchedAt
+	if _, err := gz.Write(snap.Body); err != nil {
+		return fmt.Errorf("error compressing archive file %s: %w", path, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error compressing archive file %s: %w", path, err)
+	}
+
+	sealed, sealErr := sealBytes(compressed.Bytes())
+	if sealErr != nil {
+		return sealErr
+	}
+
+	if err := writeFileAtomic(path, sealed, 0644); err != nil {
+		return fmt.Errorf("error writing archive file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (a *archiveHistory) Snapshots(p statePartition) ([]snapshot, error) {
+	entries, readErr := os.ReadDir(a.dir)
+	if errors.Is(readErr, os.ErrNotExist) {
+		return nil, nil
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("error reading archive directory %s: %w", a.dir, readErr)
+	}
+
+	var snaps []snapshot
+	for _, entry := range entries {
+		if snap, ok := a.parseFileName(entry.Name()); ok && !entry.IsDir() && snap.Year == p.Year && snap.Leaderboard == p.Leaderboard {
+			snaps = append(snaps, snap)
+		}
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].ID < snaps[j].ID })
+
+	return snaps, nil
+}
+
+func (a *archiveHistory) LoadSnapshot(id int64) (snapshot, error) {
+	matches := a.snapshotFiles(id)
+	if len(matches) == 0 {
+		return snapshot{}, fmt.Errorf("no archived snapshot with id %d", id)
+	}
+
+	snap, _ := a.parseFileName(filepath.Base(matches[0]))
+	data, readErr := os.ReadFile(matches[0])
+	if readErr != nil {
+		return snap, fmt.Errorf("error reading archive file %s: %w", matches[0], readErr)
+	}
+	if data, readErr = openBytes(data); readErr != nil {
+		return snap, fmt.Errorf("error reading archive file %s: %w", matches[0], readErr)
+	}
+
+	gz, gzErr := gzip.NewReader(bytes.NewReader(data))
+	if gzErr != nil {
+		return snap, fmt.Errorf("error decompressing archive file %s: %w", matches[0], gzErr)
+	}
+	defer gz.Close()
+
+	body, readErr := io.ReadAll(gz)
+	if readErr != nil {
+		return snap, fmt.Errorf("error decompressing archive file %s: %w", matches[0], readErr)
+	}
+	snap.Body = body
+
+	return snap, nil
+}
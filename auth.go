@@ -0,0 +1,70 @@
+package main
+
+import (
+	"crypto/subtle"
+	"flag"
+	"net/http"
+	"strings"
+)
+
+var readTokenArg = flag.String("readToken", "", "token required to read anything from the web server; empty leaves reading open to anyone")
+
+// accessLevel is what a request's token allows it to do.
+type accessLevel int
+
+const (
+	accessNone accessLevel = iota
+	accessRead
+	accessAdmin
+)
+
+// publicPaths don't need a token: health probes can't send one, and chat commands, pings, and federated scans are
+// verified by their own tokens and signatures.
+var publicPaths = []string{"/healthz", "/readyz", "/slack/command", "/discord/interactions", "/federation", "/ping"}
+
+// requestToken returns the token a request was made with, given either as a bearer token or as the password of basic
+// auth (whose username is ignored), so browsers can be prompted for it.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	return ""
+}
+
+func tokenMatches(token, expected string) bool {
+	return len(expected) > 0 && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// grantedAccess returns what the request is allowed to do. The admin token can do everything the read token can.
+func grantedAccess(r *http.Request) accessLevel {
+	token := requestToken(r)
+	switch {
+	case tokenMatches(token, *adminTokenArg):
+		return accessAdmin
+	case len(*readTokenArg) == 0 || tokenMatches(token, *readTokenArg):
+		return accessRead
+	}
+	return accessNone
+}
+
+// writeUnauthorized asks for a token, offering basic auth so that a browser visiting the dashboard prompts for it.
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Add("WWW-Authenticate", `Bearer realm="Advent of Code leaderboard"`)
+	w.Header().Add("WWW-Authenticate", `Basic realm="Advent of Code leaderboard"`)
+	writeAPIError(w, http.StatusUnauthorized, message)
+}
+
+// requireReadAccess wraps the server's routes so that everything but the public paths needs a token when readToken is
+// set. The admin endpoints check for the admin token themselves.
+func requireReadAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !arrayContains(publicPaths, func(p string) bool { return p == r.URL.Path }) && grantedAccess(r) < accessRead {
+			writeUnauthorized(w, "a valid token is required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
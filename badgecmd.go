@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	badgeColorDone    = "#4c1"
+	badgeColorPartial = "#dfb317"
+	badgeColorNone    = "#9f9f9f"
+)
+
+// maxStars is how many stars there are to earn in an event.
+func maxStars(year string) int {
+	return eventDays(year) * 2
+}
+
+// badgeTextWidth approximates how wide text is in the 11px Verdana that shields-style badges use.
+func badgeTextWidth(text string) int {
+	return utf8.RuneCountInString(text)*7 + 10
+}
+
+// renderBadge returns a shields-style flat badge with a grey label on the left and a colored message on the right.
+func renderBadge(label, message, color string) []byte {
+	labelWidth := badgeTextWidth(label)
+	messageWidth := badgeTextWidth(message)
+	width := labelWidth + messageWidth
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, width, labelWidth, messageWidth, label, message, color, labelWidth/2, labelWidth+messageWidth/2))
+}
+
+// memberBadge shows a member's stars out of the most they could have.
+func memberBadge(member memberData, year string) []byte {
+	total := maxStars(year)
+	color := badgeColorPartial
+	switch {
+	case member.Stars >= total:
+		color = badgeColorDone
+	case member.Stars == 0:
+		color = badgeColorNone
+	}
+
+	return renderBadge("AoC "+year, fmt.Sprintf("%d/%d ⭐", member.Stars, total), color)
+}
+
+// leaderboardBadge shows how many stars the whole leaderboard has earned.
+func leaderboardBadge(leaderboard *leaderboardData, year string) []byte {
+	stars := 0
+	for _, member := range leaderboard.Members {
+		stars += member.Stars
+	}
+
+	return renderBadge("AoC "+year+" leaderboard", fmt.Sprintf("%d ⭐ · %d members", stars, len(leaderboard.Members)), badgeColorDone)
+}
+
+// handleBadge serves /badge/total and /badge/{memberID} from the cached leaderboard. Errors are badges too, so that
+// an embedded image still shows something useful.
+func (s *server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	write := func(status int, badge []byte) {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		// image proxies like GitHub's camo honor this, so badges stay reasonably fresh
+		w.Header().Set("Cache-Control", "max-age=300")
+		w.WriteHeader(status)
+		w.Write(badge)
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/badge/"), ".svg")
+	id, idErr := strconv.Atoi(name)
+	if name != "total" && idErr != nil {
+		write(http.StatusNotFound, renderBadge("AoC "+s.partition.Year, "not found", badgeColorNone))
+		return
+	}
+
+	leaderboard, _, loadErr := s.cachedLeaderboard()
+	if loadErr != nil {
+		logError("Error loading leaderboard for badge:", loadErr)
+		write(http.StatusInternalServerError, renderBadge("AoC "+s.partition.Year, "error", badgeColorNone))
+		return
+	}
+	if leaderboard == nil {
+		write(http.StatusOK, renderBadge("AoC "+s.partition.Year, "not scanned yet", badgeColorNone))
+		return
+	}
+
+	if name == "total" {
+		write(http.StatusOK, leaderboardBadge(leaderboard, s.partition.Year))
+		return
+	}
+
+	member := arrayFind(leaderboard.Members, func(m memberData) bool { return m.ID == id })
+	if member == nil {
+		write(http.StatusNotFound, renderBadge("AoC "+s.partition.Year, "unknown member", badgeColorNone))
+		return
+	}
+	write(http.StatusOK, memberBadge(*member, s.partition.Year))
+}
+
+func runBadgeCommand(args []string) error {
+	fs := flag.NewFlagSet("badge", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	out := fs.String("o", "badges", "the directory to write badges to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	leaderboard, _, loadErr := loadLeaderboard(*cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", *out, err)
+	}
+
+	write := func(name string, badge []byte) error {
+		path := filepath.Join(*out, name)
+		if err := writeFileAtomic(path, badge, 0644); err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+		return nil
+	}
+
+	if err := write("leaderboard.svg", leaderboardBadge(leaderboard, *yearArg)); err != nil {
+		return err
+	}
+	for _, member := range leaderboard.Members {
+		if err := write(fmt.Sprintf("member-%d.svg", member.ID), memberBadge(member, *yearArg)); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Wrote %d badges to %s\n", len(leaderboard.Members)+1, *out)
+	return nil
+}
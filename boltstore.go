@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltStateBucket         = []byte("state")
+	boltScansBucket         = []byte("scans")
+	boltMembersBucket       = []byte("members")
+	boltNotificationsBucket = []byte("notifications")
+	boltDeliveriesBucket    = []byte("deliveries")
+)
+
+// boltScan is how a snapshot is serialized into the scans bucket.
+type boltScan struct {
+	FetchedAt   int64  `json:"fetched_at"`
+	Year        string `json:"year"`
+	Leaderboard string `json:"leaderboard"`
+	Body        string `json:"body"`
+}
+
+// boltMemberState is how a member's state as of one scan is serialized into their bucket under the members bucket.
+type boltMemberState struct {
+	FetchedAt         int64  `json:"fetched_at"`
+	Name              string `json:"name"`
+	Stars             int    `json:"stars"`
+	LocalScore        int    `json:"local_score"`
+	GlobalScore       int    `json:"global_score"`
+	LastStarTimestamp int    `json:"last_star_ts"`
+}
+
+// boltStore keeps state, scan history, per-member history, and delivered notifications in a single local bbolt file,
+// without requiring any external services.
+type boltStore struct {
+	db *bolt.DB
+}
+
+func newBoltStore(spec string) (stateStore, error) {
+	_, path, _ := strings.Cut(spec, ":")
+	path = strings.TrimPrefix(path, "//")
+	if len(path) == 0 {
+		path = "aoc.bolt"
+	}
+
+	db, openErr := bolt.Open(path, 0644, &bolt.Options{Timeout: 10 * time.Second})
+	if openErr != nil {
+		return nil, fmt.Errorf("error opening bolt database %s: %w", path, openErr)
+	}
+
+	createErr := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{boltStateBucket, boltScansBucket, boltMembersBucket, boltNotificationsBucket, boltDeliveriesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if createErr != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating bolt buckets: %w", createErr)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+func boltKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
+
+func (s *boltStore) Load(p statePartition) (scanState, error) {
+	var state scanState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltStateBucket).Get([]byte(p.String()))
+		if data == nil {
+			return nil
+		}
+
+		var unmarshalErr error
+		state, unmarshalErr = unmarshalState(data)
+		return unmarshalErr
+	})
+
+	return state, err
+}
+
+func (s *boltStore) Save(p statePartition, state scanState) error {
+	data, marshalErr := marshalState(state)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltStateBucket).Put([]byte(p.String()), data)
+	})
+}
+
+func (s *boltStore) SaveSnapshot(snap snapshot) error {
+	leaderboard, leaderboardErr := buildLeaderboard(snap.Body)
+	if leaderboardErr != nil {
+		return leaderboardErr
+	}
+
+	body, sealErr := sealString(string(snap.Body))
+	if sealErr != nil {
+		return sealErr
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		scans := tx.Bucket(boltScansBucket)
+		id, seqErr := scans.NextSequence()
+		if seqErr != nil {
+			return seqErr
+		}
+
+		scan, _ := json.Marshal(boltScan{FetchedAt: snap.FetchedAt.Unix(), Year: snap.Year, Leaderboard: snap.Leaderboard, Body: body})
+		if err := scans.Put(boltKey(id), scan); err != nil {
+			return fmt.Errorf("error recording scan: %w", err)
+		}
+
+		members := tx.Bucket(boltMembersBucket)
+		for _, member := range leaderboard.Members {
+			memberBucket, bucketErr := members.CreateBucketIfNotExists([]byte(strconv.Itoa(member.ID)))
+			if bucketErr != nil {
+				return bucketErr
+			}
+
+			name, nameErr := sealString(member.Name)
+			if nameErr != nil {
+				return nameErr
+			}
+
+			memberState, _ := json.Marshal(boltMemberState{
+				FetchedAt:         snap.FetchedAt.Unix(),
+				Name:              name,
+				Stars:             member.Stars,
+				LocalScore:        member.LocalScore,
+				GlobalScore:       member.GlobalScore,
+				LastStarTimestamp: member.LastStarTimestamp,
+			})
+			if err := memberBucket.Put(boltKey(id), memberState); err != nil {
+				return fmt.Errorf("error recording state of member %d: %w", member.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *boltStore) Snapshots(p statePartition) ([]snapshot, error) {
+	var snaps []snapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltScansBucket).ForEach(func(k, v []byte) error {
+			var scan boltScan
+			if err := json.Unmarshal(v, &scan); err != nil {
+				return fmt.Errorf("error parsing scan: %w", err)
+			}
+			if scan.Year != p.Year || scan.Leaderboard != p.Leaderboard {
+				return nil
+			}
+
+			snaps = append(snaps, snapshot{
+				ID:          int64(binary.BigEndian.Uint64(k)),
+				FetchedAt:   time.Unix(scan.FetchedAt, 0),
+				Year:        scan.Year,
+				Leaderboard: scan.Leaderboard,
+			})
+			return nil
+		})
+	})
+
+	return snaps, err
+}
+
+func (s *boltStore) LoadSnapshot(id int64) (snapshot, error) {
+	snap := snapshot{ID: id}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltScansBucket).Get(boltKey(uint64(id)))
+		if data == nil {
+			return errors.New("not found")
+		}
+
+		var scan boltScan
+		if err := json.Unmarshal(data, &scan); err != nil {
+			return err
+		}
+
+		snap.FetchedAt = time.Unix(scan.FetchedAt, 0)
+		snap.Year = scan.Year
+		snap.Leaderboard = scan.Leaderboard
+		body, openErr := openString(scan.Body)
+		snap.Body = []byte(body)
+		return openErr
+	})
+	if err != nil {
+		return snap, fmt.Errorf("error reading scan %d: %w", id, err)
+	}
+
+	return snap, nil
+}
+
+func (s *boltStore) RecordNotification(n deliveredNotification) error {
+	content, sealErr := sealString(n.Content)
+	if sealErr != nil {
+		return sealErr
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltNotificationsBucket)
+		id, seqErr := bucket.NextSequence()
+		if seqErr != nil {
+			return seqErr
+		}
+
+		data, _ := json.Marshal(map[string]any{"sent_at": n.SentAt.Unix(), "destination": n.Destination, "content": content})
+		return bucket.Put(boltKey(id), data)
+	})
+}
+
+func (s *boltStore) RecentNotifications(destination string, limit int) ([]deliveredNotification, error) {
+	var notifications []deliveredNotification
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltNotificationsBucket).Cursor()
+		for k, v := c.Last(); k != nil && len(notifications) < limit; k, v = c.Prev() {
+			var stored struct {
+				SentAt      int64  `json:"sent_at"`
+				Destination string `json:"destination"`
+				Content     string `json:"content"`
+			}
+			if err := json.Unmarshal(v, &stored); err != nil {
+				return err
+			}
+			if stored.Destination != destination {
+				continue
+			}
+
+			content, openErr := openString(stored.Content)
+			if openErr != nil {
+				return openErr
+			}
+			notifications = append(notifications, deliveredNotification{SentAt: time.Unix(stored.SentAt, 0), Destination: stored.Destination, Content: content})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (s *boltStore) HasDelivered(key string) (bool, error) {
+	delivered := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		delivered = tx.Bucket(boltDeliveriesBucket).Get([]byte(key)) != nil
+		return nil
+	})
+
+	return delivered, err
+}
+
+func (s *boltStore) MarkDelivered(key string, at time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltDeliveriesBucket).Put([]byte(key), []byte(strconv.FormatInt(at.Unix(), 10)))
+	})
+}
+
+func (s *boltStore) Deliveries() (map[string]int64, error) {
+	deliveries := make(map[string]int64)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltDeliveriesBucket).ForEach(func(k, v []byte) error {
+			deliveries[string(k)], _ = strconv.ParseInt(string(v), 10, 64)
+			return nil
+		})
+	})
+
+	return deliveries, err
+}
+
+func (s *boltStore) DeleteSnapshots(ids []int64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		scans := tx.Bucket(boltScansBucket)
+		members := tx.Bucket(boltMembersBucket)
+		for _, id := range ids {
+			if err := scans.Delete(boltKey(uint64(id))); err != nil {
+				return fmt.Errorf("error deleting scan %d: %w", id, err)
+			}
+
+			memberErr := members.ForEachBucket(func(k []byte) error {
+				return members.Bucket(k).Delete(boltKey(uint64(id)))
+			})
+			if memberErr != nil {
+				return fmt.Errorf("error deleting member states for scan %d: %w", id, memberErr)
+			}
+		}
+
+		return nil
+	})
+}
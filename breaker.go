@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/notify"
+)
+
+var (
+	breakerThresholdArg = flag.Int("breakerThreshold", 5, "how many deliveries in a row can fail before a destination is left alone for breakerCooldown; 0 keeps trying every time")
+	breakerCooldownArg  = flag.Duration("breakerCooldown", 15*time.Minute, "how long to stop sending to a destination that keeps failing before trying it again")
+)
+
+// errCircuitOpen is returned instead of trying a destination that's failed too many times in a row.
+var errCircuitOpen = errors.New("destination has been failing, so it isn't being tried until its cooldown is over")
+
+// breakerNotifier stops sending to a destination that keeps failing until a cooldown has passed, so that a dead
+// webhook isn't hit again for every notification and every scan. Notifications that aren't sent meanwhile stay in the
+// outbox. The admin destination is told when the circuit opens and when the destination recovers.
+type breakerNotifier struct {
+	notify.Notifier
+	name string
+	// alert tells the admin destination about the circuit opening and closing. It's nil for the admin destination
+	// itself, which has nowhere else to report its own failures.
+	alert func(ctx context.Context, content string) error
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func newBreakerNotifier(n notify.Notifier, name string, alert func(ctx context.Context, content string) error) *breakerNotifier {
+	return &breakerNotifier{Notifier: n, name: name, alert: alert}
+}
+
+func (b *breakerNotifier) Send(ctx context.Context, event notify.Event) error {
+	if *breakerThresholdArg <= 0 {
+		return b.Notifier.Send(ctx, event)
+	}
+
+	b.mu.Lock()
+	if time.Now().Before(b.openUntil) {
+		b.mu.Unlock()
+		return errCircuitOpen
+	}
+	b.mu.Unlock()
+
+	err := b.Notifier.Send(ctx, event)
+	if ctx.Err() != nil {
+		// being cut off says nothing about the destination
+		return err
+	}
+
+	b.mu.Lock()
+	var alert string
+	switch {
+	case err == nil:
+		if b.failures >= *breakerThresholdArg {
+			alert = fmt.Sprintf(":white_check_mark: The %s is accepting notifications again.", b.name)
+			logInfo("The", b.name, "recovered; closing its circuit")
+		}
+		b.failures = 0
+	default:
+		b.failures++
+		if b.failures >= *breakerThresholdArg {
+			// after it opens, each failed attempt once the cooldown is over opens it again
+			b.openUntil = time.Now().Add(*breakerCooldownArg)
+			if b.failures == *breakerThresholdArg {
+				// the error isn't included, since it can have the webhook's secret URL in it
+				alert = fmt.Sprintf(":warning: The last %d notifications to the %s failed, so it won't be tried again for %s. Notifications are being held until it recovers; the scanner's log has the errors.", b.failures, b.name, *breakerCooldownArg)
+				logWarn("The", b.name, "failed", b.failures, "times in a row; opening its circuit for", *breakerCooldownArg, "after:", err)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	if len(alert) > 0 && b.alert != nil {
+		if alertErr := b.alert(ctx, alert); alertErr != nil {
+			logError("Error sending circuit breaker alert:", alertErr)
+		}
+	}
+	return err
+}
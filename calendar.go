@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+// icalEscape escapes text for use in an iCalendar property value.
+func icalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// buildCalendar returns an iCalendar file with each of the event's puzzle unlocks, and the daily digest during the
+// event if one is scheduled. Times are in UTC so calendars show them in whatever timezone their owner is in, but the
+// descriptions give the leaderboard's own time too.
+func buildCalendar(year string, board leaderboardSettings, now time.Time) string {
+	var sb strings.Builder
+	// lines longer than 75 octets have to be folded onto continuation lines that start with a space
+	line := func(format string, args ...any) {
+		text := fmt.Sprintf(format, args...)
+		for limit := 75; len(text) > limit; limit = 74 {
+			cut := limit
+			for !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+			sb.WriteString(text[:cut] + "\r\n ")
+			text = text[cut:]
+		}
+		sb.WriteString(text + "\r\n")
+	}
+	event := func(uid, summary, description string, start time.Time, length time.Duration) {
+		line("BEGIN:VEVENT")
+		line("UID:%s", uid)
+		line("DTSTAMP:%s", now.UTC().Format(icalTimeFormat))
+		line("DTSTART:%s", start.UTC().Format(icalTimeFormat))
+		line("DTEND:%s", start.Add(length).UTC().Format(icalTimeFormat))
+		line("SUMMARY:%s", icalEscape(summary))
+		line("DESCRIPTION:%s", icalEscape(description))
+		line("END:VEVENT")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//pernicious.games//Advent of Code leaderboard scanner//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:%s", icalEscape(fmt.Sprintf("Advent of Code %s", year)))
+	line("X-WR-TIMEZONE:%s", board.Location.String())
+
+	var digestAt time.Time
+	if len(board.DigestTime) > 0 {
+		digestAt, _ = time.Parse("15:04", board.DigestTime)
+	}
+
+	for day := 1; day <= eventDays(year); day++ {
+		unlock := dayUnlock(year, day)
+		event(
+			fmt.Sprintf("%s-day%d-unlock@%s.adventofcode.com", year, day, board.ID),
+			fmt.Sprintf("Advent of Code %s day %d unlocks", year, day),
+			fmt.Sprintf("Day %d's puzzle unlocks at %s leaderboard time.\nhttps://adventofcode.com/%s/day/%d", day, unlock.In(board.Location).Format("Jan 2 3:04pm MST"), year, day),
+			unlock,
+			30*time.Minute,
+		)
+
+		if !digestAt.IsZero() {
+			local := unlock.In(board.Location)
+			digest := time.Date(local.Year(), local.Month(), local.Day(), digestAt.Hour(), digestAt.Minute(), 0, 0, board.Location)
+			if digest.Before(unlock) {
+				digest = digest.AddDate(0, 0, 1)
+			}
+			event(
+				fmt.Sprintf("%s-day%d-digest@%s.adventofcode.com", year, day, board.ID),
+				"Advent of Code standings digest",
+				fmt.Sprintf("The standings digest is posted at %s leaderboard time.", digest.Format("Jan 2 3:04pm MST")),
+				digest,
+				15*time.Minute,
+			)
+		}
+	}
+
+	line("END:VCALENDAR")
+	return sb.String()
+}
+
+// handleCalendar serves the calendar of the server's leaderboard, or with ?leaderboard=, of another configured one in
+// its own timezone and digest time.
+func (s *server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	board := s.board
+	if id := r.URL.Query().Get("leaderboard"); len(id) > 0 && id != board.ID {
+		if !slices.Contains(configuredLeaderboards(), id) {
+			http.Error(w, fmt.Sprintf("leaderboard %s isn't configured", id), http.StatusNotFound)
+			return
+		}
+		var boardErr error
+		if board, boardErr = configuredBoard(id); boardErr != nil {
+			http.Error(w, boardErr.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="aoc-%s.ics"`, s.partition.Year))
+	w.Write([]byte(buildCalendar(s.partition.Year, board, time.Now())))
+}
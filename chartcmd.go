@@ -0,0 +1,147 @@
+//go:build !nocharts
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// starProgressChart plots each member's cumulative star count over time.
+func starProgressChart(leaderboard *leaderboardData, year string, members []memberData) chart.Chart {
+	var series []chart.Series
+	for _, member := range members {
+		var times []time.Time
+		for _, day := range member.CompletionDayLevel {
+			for _, part := range []*completionPartData{day.Part1, day.Part2} {
+				if part != nil {
+					times = append(times, time.Unix(part.GotStarAt, 0))
+				}
+			}
+		}
+		if len(times) < 2 {
+			continue
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+		ts := chart.TimeSeries{Name: displayName(member)}
+		for idx, at := range times {
+			ts.XValues = append(ts.XValues, at)
+			ts.YValues = append(ts.YValues, float64(idx+1))
+		}
+		series = append(series, ts)
+	}
+
+	return chart.Chart{
+		Title:      fmt.Sprintf("Advent of Code %s: stars over time", year),
+		Background: chart.Style{Padding: chart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20}},
+		XAxis:      chart.XAxis{ValueFormatter: chart.TimeValueFormatterWithFormat("Jan 2")},
+		YAxis:      chart.YAxis{Name: "Stars", ValueFormatter: formatWholeNumber},
+		Series:     series,
+	}
+}
+
+// solveTimeChart plots how long after unlock each member finished each day, in minutes.
+func solveTimeChart(leaderboard *leaderboardData, year string, members []memberData) chart.Chart {
+	var series []chart.Series
+	for _, member := range members {
+		cs := chart.ContinuousSeries{Name: displayName(member)}
+		for dayIdx, day := range member.CompletionDayLevel {
+			if day.Part2 == nil {
+				continue
+			}
+			elapsed := time.Unix(day.Part2.GotStarAt, 0).Sub(dayUnlock(year, dayIdx+1))
+			cs.XValues = append(cs.XValues, float64(dayIdx+1))
+			cs.YValues = append(cs.YValues, elapsed.Minutes())
+		}
+		if len(cs.XValues) < 2 {
+			continue
+		}
+		series = append(series, cs)
+	}
+
+	return chart.Chart{
+		Title:      fmt.Sprintf("Advent of Code %s: time to finish each day", year),
+		Background: chart.Style{Padding: chart.Box{Top: 50, Left: 20, Right: 20, Bottom: 20}},
+		XAxis:      chart.XAxis{Name: "Day", ValueFormatter: formatWholeNumber},
+		YAxis:      chart.YAxis{Name: "Minutes after unlock", ValueFormatter: formatWholeNumber},
+		Series:     series,
+	}
+}
+
+func formatWholeNumber(v interface{}) string {
+	return fmt.Sprintf("%.0f", v)
+}
+
+func runChartCommand(args []string) error {
+	fs := flag.NewFlagSet("chart", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	out := fs.String("o", "charts", "the directory to write charts to")
+	format := fs.String("format", "png", "the image format: png or svg")
+	top := fs.Int("top", 10, "how many of the top-ranked members to plot")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	renderer := chart.PNG
+	switch *format {
+	case "png":
+	case "svg":
+		renderer = chart.SVG
+	default:
+		return fmt.Errorf("unknown chart format %q; expected png or svg", *format)
+	}
+
+	leaderboard, _, loadErr := loadLeaderboard(*cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	members := rankedStandings(leaderboard, *yearArg)
+	if len(members) > *top {
+		members = members[:*top]
+	}
+
+	charts := map[string]chart.Chart{
+		"stars":       starProgressChart(leaderboard, *yearArg, members),
+		"solve-times": solveTimeChart(leaderboard, *yearArg, members),
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", *out, err)
+	}
+
+	written := 0
+	for name, c := range charts {
+		if len(c.Series) == 0 {
+			logWarn("Not enough data yet to chart", name)
+			continue
+		}
+		c.Elements = []chart.Renderable{chart.Legend(&c)}
+
+		var buf bytes.Buffer
+		if err := c.Render(renderer, &buf); err != nil {
+			return fmt.Errorf("error rendering %s chart: %w", name, err)
+		}
+
+		path := filepath.Join(*out, name+"."+*format)
+		if err := writeFileAtomic(path, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+		written++
+	}
+	if written == 0 {
+		return errors.New("nothing to chart yet; members need at least two stars")
+	}
+
+	fmt.Printf("Wrote %d charts to %s\n", written, *out)
+	return nil
+}
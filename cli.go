@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// command is one of the CLI's subcommands. Every option registered with the flag package is global and can be given
+// either before the command's name or among its own arguments; anything else is left for the command to parse, usually
+// with its own flag.FlagSet.
+type command struct {
+	name string
+	// usage is the command's arguments, shown after its name in help output.
+	usage   string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"config", "print-effective", "print every option's effective value and where it came from", runConfigCommand},
+	{"state", "export <file> | import <file>", "move persisted state between hosts or stores", runStateCommand},
+	{"badge", "[-cached] [-o dir]", "write SVG badges for each member and the leaderboard", runBadgeCommand},
+	{"chart", "[-cached] [-o dir] [-format png|svg] [-top 10]", "render star progress and solve time charts", runChartCommand},
+	{"heatmap", "[-cached] [-by time|rank] [-format svg|html|text] [-o file]", "render a grid of how quickly each member finished each day", runHeatmapCommand},
+	{"announce", "[-cached] [-dryRun] <message> | -file <template>", "send a custom message, with access to the standings", runAnnounceCommand},
+	{"digest", "[-daily | -weekly | -final | -combined] [-cached] [-dryRun]", "post a standings digest right away", runDigestCommand},
+	{"init", "[-format env|json] [-o file]", "interactively write a starter config", runInitCommand},
+	{"summary", "[-cached]", "print the current standings", runSummaryCommand},
+	{"stats", "[-cached] [-json]", "print per-member solve time analytics", runStatsCommand},
+	{"export", "[-cached] [-format csv|json|md] [-o file]", "export standings and completion times", runExportCommand},
+	{"timeline", "[-cached] [-format csv|jsonl] [-o file]", "export every star as a row, for analysis", runTimelineCommand},
+	{"top", "[-cached] [-day N] [-part 1|2]", "rank the finishers of a day", runTopCommand},
+	{"score", "[-cached] [-mode name] [-format table|csv|json] [-o file] | -list", "rank the leaderboard under a scoring mode", runScoreCommand},
+	{"members", "[-cached] [-json]", "list every member's ID, name, stars, and welcome status", runMembersCommand},
+	{"history", "[list] | show <id>", "list stored snapshots or show the standings in one", runHistoryCommand},
+	{"diff", "<id> <id> | -since <duration>", "print what changed between two snapshots", runDiffCommand},
+	{"prune", "-olderThan <duration> [-dryRun]", "remove old history, stuck notifications, and recordings", runPruneCommand},
+	{"replay", "[-since <duration> | -from <id>] [-to <id>] [-send [-yes]]", "regenerate the notifications between stored snapshots", runReplayCommand},
+	{"doctor", "", "check connectivity, sessions, webhooks, and the store", runDoctorCommand},
+	{"simulate", "[-members N] [-rounds N] [-seed N] [-send]", "run made-up leaderboard changes through change detection", runSimulateCommand},
+	{"validate-session", "", "check whether each session is accepted", runValidateSessionCommand},
+	{"send-test", "[-message text]", "send a test message to every destination", runSendTestCommand},
+	{"publish", "[-o dir]", "render the dashboard and api as a static site", runPublishCommand},
+	{"serve", "[-addr :8080] [-tlsCert file -tlsKey file] [-scan]", "run the web server", runServeCommand},
+	{"tui", "", "show a live terminal dashboard", runTUICommand},
+	{"lambda", "", "handle AWS Lambda invocations, scanning once for each", runLambdaCommand},
+	{"version", "", "print version information", func(args []string) error { printVersion(); return nil }},
+}
+
+func findCommand(name string) *command {
+	return arrayFind(commands, func(c command) bool { return c.name == name })
+}
+
+// splitGlobalFlags separates any global options given among a command's arguments from the arguments meant for the
+// command itself.
+func splitGlobalFlags(args []string) (global, rest []string) {
+	for idx := 0; idx < len(args); idx++ {
+		arg := args[idx]
+		if arg == "--" {
+			return global, append(rest, args[idx:]...)
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if len(name) == len(arg) || len(arg)-len(name) > 2 {
+			rest = append(rest, arg)
+			continue
+		}
+		name, _, hasValue := strings.Cut(name, "=")
+		f := flag.Lookup(name)
+		if f == nil {
+			rest = append(rest, arg)
+			continue
+		}
+
+		global = append(global, arg)
+		if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(ok && boolFlag.IsBoolFlag()) && idx+1 < len(args) {
+			idx++
+			global = append(global, args[idx])
+		}
+	}
+
+	return global, rest
+}
+
+// printUsage lists the global options and every command, and is also what -h shows.
+func printUsage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [options] [command [command options]]\n\n", os.Args[0])
+	fmt.Fprintln(out, "With no command, scans the leaderboard once, or on a schedule with -d.")
+	fmt.Fprintln(out, "\nCommands:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s\t%s\n", c.name, c.summary)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\nRun '%s help <command>' for a command's arguments, or '<command> -h' for its options.\n", os.Args[0])
+	fmt.Fprintln(out, "\nOptions (these can also be given after the command):")
+	flag.PrintDefaults()
+}
+
+func runHelpCommand(args []string) error {
+	if len(args) == 0 {
+		flag.CommandLine.SetOutput(os.Stdout)
+		printUsage()
+		return nil
+	}
+
+	c := findCommand(args[0])
+	if c == nil {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	fmt.Printf("Usage: %s [options] %s %s\n\n%s.\n", os.Args[0], c.name, c.usage, strings.ToUpper(c.summary[:1])+c.summary[1:])
+	return nil
+}
+
+func runCommand(args []string) error {
+	// help is dispatched here rather than listed with the others, since it needs to read the list itself
+	if args[0] == "help" {
+		return runHelpCommand(args[1:])
+	}
+
+	c := findCommand(args[0])
+	if c == nil {
+		return fmt.Errorf("unknown command %q; run '%s help' for a list", args[0], os.Args[0])
+	}
+
+	if err := c.run(args[1:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+// Package client reads a leaderboard from the scanner's web server, as described by the OpenAPI document it serves
+// at /openapi.json.
+//
+//	c := client.New("https://aoc.example.com")
+//	lb, err := c.Leaderboard(ctx)
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Leaderboard is the leaderboard's members in standings order.
+type Leaderboard struct {
+	Year        string `json:"year"`
+	Leaderboard string `json:"leaderboard"`
+	// Updated is when this copy of the leaderboard was fetched.
+	Updated time.Time `json:"updated"`
+	Members []Member  `json:"members"`
+}
+
+// Member is one member of a leaderboard.
+type Member struct {
+	// Rank is 0 for a member the server excludes from ranking.
+	Rank        int    `json:"rank"`
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Stars       int    `json:"stars"`
+	LocalScore  int    `json:"local_score"`
+	GlobalScore int    `json:"global_score"`
+	// CustomScore is the member's score under the server's scoring table, or nil if it doesn't have one.
+	CustomScore *int `json:"custom_score,omitempty"`
+	// LastStar is nil if the member has no stars.
+	LastStar *time.Time  `json:"last_star,omitempty"`
+	Days     []MemberDay `json:"days"`
+}
+
+// MemberDay is when a member finished each part of a day. Parts they haven't finished are nil.
+type MemberDay struct {
+	Day   int        `json:"day"`
+	Part1 *time.Time `json:"part1,omitempty"`
+	Part2 *time.Time `json:"part2,omitempty"`
+}
+
+// Standings is the leaderboard ranked under one of the server's scoring modes.
+type Standings struct {
+	Year        string `json:"year"`
+	Leaderboard string `json:"leaderboard"`
+	Mode        string `json:"mode"`
+	// Updated is when this copy of the leaderboard was fetched.
+	Updated time.Time      `json:"updated"`
+	Members []ScoredMember `json:"members"`
+}
+
+// ScoredMember is one member's place in Standings. Members share a rank only if their score, stars, and last star
+// time are all equal.
+type ScoredMember struct {
+	Rank  int    `json:"rank"`
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Stars int    `json:"stars"`
+	Score int    `json:"score"`
+}
+
+// Day is everyone who has finished each part of a day, fastest first.
+type Day struct {
+	Day    int        `json:"day"`
+	Unlock time.Time  `json:"unlock"`
+	Part1  []Finisher `json:"part1"`
+	Part2  []Finisher `json:"part2"`
+}
+
+// Finisher is one member's completion of one part of a day.
+type Finisher struct {
+	// Rank is 0 for a member the server excludes from ranking.
+	Rank int       `json:"rank"`
+	ID   int       `json:"id"`
+	Name string    `json:"name"`
+	At   time.Time `json:"at"`
+	// ElapsedSeconds is how long after the puzzle unlocked the star was earned.
+	ElapsedSeconds int64 `json:"elapsed_seconds"`
+}
+
+// DayTimes is every member who has started a day, whoever finished it first first.
+type DayTimes struct {
+	Day     int         `json:"day"`
+	Unlock  time.Time   `json:"unlock"`
+	Members []SolveTime `json:"members"`
+}
+
+// SolveTime is one member's times on one day, in seconds from the puzzle unlocking. Parts they haven't finished are
+// nil.
+type SolveTime struct {
+	ID           int        `json:"id"`
+	Name         string     `json:"name"`
+	Part1Seconds *int64     `json:"part1_seconds"`
+	Part2Seconds *int64     `json:"part2_seconds"`
+	DeltaSeconds *int64     `json:"delta_seconds"`
+	Part1At      *time.Time `json:"part1_at"`
+	Part2At      *time.Time `json:"part2_at"`
+}
+
+// DayStats is the spread of a day's solve times across the leaderboard, and where each member who started it fell in
+// them, whoever finished it first first.
+type DayStats struct {
+	Day     int       `json:"day"`
+	Unlock  time.Time `json:"unlock"`
+	Part1   PartStats `json:"part1"`
+	Part2   PartStats `json:"part2"`
+	Members []Placing `json:"members"`
+}
+
+// PartStats is the spread of one part's solve times, in seconds from the puzzle unlocking. The quartiles are nil when
+// nobody has finished the part.
+type PartStats struct {
+	Solvers       int    `json:"solvers"`
+	P25Seconds    *int64 `json:"p25_seconds"`
+	MedianSeconds *int64 `json:"median_seconds"`
+	P75Seconds    *int64 `json:"p75_seconds"`
+}
+
+// Placing is where one member fell on one day. A percentile is the share of the part's other solvers, from 0 to 100,
+// who were slower than the member. Times and percentiles are nil for parts the member hasn't finished.
+type Placing struct {
+	ID              int      `json:"id"`
+	Name            string   `json:"name"`
+	Part1Seconds    *int64   `json:"part1_seconds"`
+	Part1Percentile *float64 `json:"part1_percentile"`
+	Part2Seconds    *int64   `json:"part2_seconds"`
+	Part2Percentile *float64 `json:"part2_percentile"`
+}
+
+// Snapshot is a stored copy of the leaderboard.
+type Snapshot struct {
+	ID        int64     `json:"id"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Error is returned when the server answers a request with an error. A StatusCode of 404 means the member, day, or
+// snapshot doesn't exist, or that nothing has been scanned yet, and 401 means the server needs a token.
+type Error struct {
+	StatusCode int
+	Message    string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Client makes requests to a scanner's web server.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	snapshot   int64
+}
+
+// New returns a client for the server at baseURL, e.g. "https://aoc.example.com".
+func New(baseURL string) *Client {
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), httpClient: http.DefaultClient}
+}
+
+// WithHTTPClient returns a copy of the client that makes its requests with httpClient.
+func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
+	copied := *c
+	copied.httpClient = httpClient
+	return &copied
+}
+
+// WithToken returns a copy of the client that authenticates with token, for servers with a readToken set.
+func (c *Client) WithToken(token string) *Client {
+	copied := *c
+	copied.token = token
+	return &copied
+}
+
+// AtSnapshot returns a copy of the client that reads the given stored snapshot instead of the latest scan.
+func (c *Client) AtSnapshot(id int64) *Client {
+	copied := *c
+	copied.snapshot = id
+	return &copied
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	u := c.baseURL + path
+	if c.snapshot != 0 {
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("snapshot", strconv.FormatInt(c.snapshot, 10))
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if reqErr != nil {
+		return reqErr
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(c.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, respErr := c.httpClient.Do(req)
+	if respErr != nil {
+		return respErr
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(apiErr); err != nil || len(apiErr.Message) == 0 {
+			apiErr.Message = "unexpected response"
+		}
+		return apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding %s: %w", path, err)
+	}
+	return nil
+}
+
+// Leaderboard returns the leaderboard's members in standings order.
+func (c *Client) Leaderboard(ctx context.Context) (*Leaderboard, error) {
+	var out Leaderboard
+	if err := c.get(ctx, "/api/leaderboard", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Standings returns the leaderboard ranked under the named scoring mode, or under the server's own scoring setting if
+// mode is empty. An unknown mode is an Error with a StatusCode of 400.
+func (c *Client) Standings(ctx context.Context, mode string) (*Standings, error) {
+	var query url.Values
+	if len(mode) > 0 {
+		query = url.Values{"mode": {mode}}
+	}
+	var out Standings
+	if err := c.get(ctx, "/api/standings", query, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Member returns a single member of the leaderboard.
+func (c *Client) Member(ctx context.Context, id int) (*Member, error) {
+	var out Member
+	if err := c.get(ctx, fmt.Sprintf("/api/members/%d", id), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Day returns everyone who has finished each part of the given day, fastest first.
+func (c *Client) Day(ctx context.Context, day int) (*Day, error) {
+	var out Day
+	if err := c.get(ctx, fmt.Sprintf("/api/days/%d", day), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DayTimes returns every member who has started the given day with their time to each star.
+func (c *Client) DayTimes(ctx context.Context, day int) (*DayTimes, error) {
+	var out DayTimes
+	if err := c.get(ctx, fmt.Sprintf("/api/days/%d/times", day), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DayStats returns the median and quartiles of the given day's solve times, and where each member fell in them.
+func (c *Client) DayStats(ctx context.Context, day int) (*DayStats, error) {
+	var out DayStats
+	if err := c.get(ctx, fmt.Sprintf("/api/days/%d/stats", day), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Snapshots returns the stored leaderboard snapshots, oldest first. The snapshot the client is reading, if any,
+// doesn't affect it.
+func (c *Client) Snapshots(ctx context.Context) ([]Snapshot, error) {
+	var out []Snapshot
+	if err := c.AtSnapshot(0).get(ctx, "/api/snapshots", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/valyala/fastjson"
+)
+
+var configArg = flag.String("config", "", "path to a json config file whose keys match the argument names")
+
+// Each option can come from (in order of precedence) an argument, an environment variable, a config file, or its
+// default. The environment variable for an option is AOC_ followed by its upper-snake-cased name unless overridden here.
+var envOverrides = map[string]string{
+	"webhookURL":      "AOC_WEBHOOK",
+	"adminWebhookURL": "AOC_ADMIN_WEBHOOK",
+	"d":               "AOC_DAEMONIZE",
+	// there's no need to say AOC twice
+	"aocRequestSpacing": "AOC_REQUEST_SPACING",
+}
+
+// secretOptions are redacted when printing the effective configuration.
+var secretOptions = map[string]bool{
+	"session":            true,
+	"webhookURL":         true,
+	"adminWebhookURL":    true,
+	"storeToken":         true,
+	"stateKey":           true,
+	"slackSigningSecret": true,
+	"adminToken":         true,
+	"readToken":          true,
+	"influxToken":        true,
+	"federationSecret":   true,
+	"pingToken":          true,
+}
+
+const (
+	sourceDefault  = "default"
+	sourceArgument = "argument"
+	sourceEnv      = "env"
+	sourceConfig   = "config"
+)
+
+// optionSources records where each option's effective value came from.
+var optionSources = map[string]string{}
+
+// configValues is what the config file held when it was last read, to tell what's changed when it's reloaded.
+var configValues = map[string]string{}
+
+func envVarForOption(name string) string {
+	if env, ok := envOverrides[name]; ok {
+		return env
+	}
+
+	var sb strings.Builder
+	sb.WriteString("AOC_")
+	for idx, r := range name {
+		if idx > 0 && r >= 'A' && r <= 'Z' {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(r)
+	}
+
+	return strings.ToUpper(sb.String())
+}
+
+// resolveOptions fills in every option that wasn't given as an argument from the environment or the config file.
+// It must be called after flag.Parse and after any .env file has been loaded.
+func resolveOptions() error {
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	// the config file location itself can only come from an argument or the environment
+	if !explicit["config"] {
+		if env := os.Getenv(envVarForOption("config")); len(env) > 0 {
+			*configArg = env
+		}
+	}
+
+	var configErr error
+	configValues, configErr = loadConfigFile(*configArg)
+	if configErr != nil {
+		return configErr
+	}
+
+	var resolveErr error
+	flag.VisitAll(func(f *flag.Flag) {
+		source := sourceDefault
+		value := ""
+		if explicit[f.Name] {
+			source = sourceArgument
+		} else if env := os.Getenv(envVarForOption(f.Name)); len(env) > 0 {
+			source, value = sourceEnv, env
+		} else if cfg, ok := configValues[f.Name]; ok {
+			source, value = sourceConfig, cfg
+		}
+		optionSources[f.Name] = source
+
+		if source == sourceEnv || source == sourceConfig {
+			if err := flag.Set(f.Name, value); err != nil {
+				resolveErr = errors.Join(resolveErr, fmt.Errorf("invalid value %q for %s from %s: %w", value, f.Name, source, err))
+			}
+		}
+	})
+
+	for name := range configValues {
+		if flag.Lookup(name) == nil {
+			resolveErr = errors.Join(resolveErr, fmt.Errorf("unknown option %q in config file %s", name, *configArg))
+		}
+	}
+
+	return resolveErr
+}
+
+func loadConfigFile(path string) (map[string]string, error) {
+	values := map[string]string{}
+	if len(path) == 0 {
+		return values, nil
+	}
+
+	contents, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", path, readErr)
+	}
+
+	obj, parseErr := fastjson.ParseBytes(contents)
+	if parseErr != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, parseErr)
+	}
+
+	configObj, objErr := obj.Object()
+	if objErr != nil {
+		return nil, fmt.Errorf("config file %s must contain a json object: %w", path, objErr)
+	}
+
+	configObj.Visit(func(key []byte, v *fastjson.Value) {
+		if v.Type() == fastjson.TypeString {
+			values[string(key)] = string(v.GetStringBytes())
+		} else {
+			values[string(key)] = v.String()
+		}
+	})
+
+	return values, nil
+}
+
+func redactOption(name, value string) string {
+	if !secretOptions[name] || len(value) == 0 {
+		return value
+	}
+
+	if len(value) <= 8 {
+		return "<redacted>"
+	}
+
+	return value[:4] + "…<redacted>"
+}
+
+func printEffectiveConfig() {
+	var names []string
+	flag.VisitAll(func(f *flag.Flag) { names = append(names, f.Name) })
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OPTION\tENV VAR\tSOURCE\tVALUE")
+	for _, name := range names {
+		f := flag.Lookup(name)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, envVarForOption(name), optionSources[name], redactOption(name, f.Value.String()))
+	}
+	w.Flush()
+}
+
+func runConfigCommand(args []string) error {
+	if len(args) == 0 || args[0] != "print-effective" {
+		return errors.New("usage: config print-effective")
+	}
+
+	printEffectiveConfig()
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+)
+
+var stateKeyArg = flag.String("stateKey", "", "encrypt persisted state (cache, ledger, history) with this key; a long random value is recommended")
+
+// sealedPrefix marks data encrypted by sealBytes so that plaintext written before a key was configured can still be read.
+var sealedPrefix = []byte("AOCENC1:")
+
+var errNoStateKey = errors.New("persisted state is encrypted, but no stateKey was provided")
+
+func stateCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(*stateKeyArg))
+	block, blockErr := aes.NewCipher(key[:])
+	if blockErr != nil {
+		return nil, blockErr
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// sealBytes encrypts data with AES-GCM using the configured state key. Without a key, data is returned unchanged.
+func sealBytes(data []byte) ([]byte, error) {
+	if len(*stateKeyArg) == 0 {
+		return data, nil
+	}
+
+	gcm, gcmErr := stateCipher()
+	if gcmErr != nil {
+		return nil, fmt.Errorf("error initializing state encryption: %w", gcmErr)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	sealed := append([]byte{}, sealedPrefix...)
+	sealed = append(sealed, nonce...)
+	return gcm.Seal(sealed, nonce, data, nil), nil
+}
+
+// openBytes reverses sealBytes. Data that was never sealed is returned unchanged.
+func openBytes(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, sealedPrefix) {
+		return data, nil
+	}
+	if len(*stateKeyArg) == 0 {
+		return nil, errNoStateKey
+	}
+
+	gcm, gcmErr := stateCipher()
+	if gcmErr != nil {
+		return nil, fmt.Errorf("error initializing state encryption: %w", gcmErr)
+	}
+
+	data = data[len(sealedPrefix):]
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted state is truncated")
+	}
+
+	opened, openErr := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if openErr != nil {
+		return nil, fmt.Errorf("error decrypting state, is stateKey correct? %w", openErr)
+	}
+
+	return opened, nil
+}
+
+// sealString is sealBytes for text columns, base64-encoding the result when a key is configured.
+func sealString(s string) (string, error) {
+	if len(*stateKeyArg) == 0 {
+		return s, nil
+	}
+
+	sealed, err := sealBytes([]byte(s))
+	if err != nil {
+		return "", err
+	}
+
+	return string(sealedPrefix) + base64.StdEncoding.EncodeToString(sealed[len(sealedPrefix):]), nil
+}
+
+// openString reverses sealString.
+func openString(s string) (string, error) {
+	encoded, ok := bytes.CutPrefix([]byte(s), sealedPrefix)
+	if !ok {
+		return s, nil
+	}
+
+	raw, decodeErr := base64.StdEncoding.DecodeString(string(encoded))
+	if decodeErr != nil {
+		return "", fmt.Errorf("error decoding encrypted value: %w", decodeErr)
+	}
+
+	opened, err := openBytes(append(append([]byte{}, sealedPrefix...), raw...))
+	return string(opened), err
+}
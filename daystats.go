@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// partSolveStats is how long a part of a day took the members who finished it, measured from the puzzle's unlock.
+type partSolveStats struct {
+	Solvers int
+	P25     time.Duration
+	Median  time.Duration
+	P75     time.Duration
+}
+
+// solvePlacing is where one member's time on one part fell among everyone else's.
+type solvePlacing struct {
+	Time time.Duration
+	// Percentile is the share of the part's other solvers, from 0 to 100, who were slower than the member, with ties
+	// counting half. A member who was the only one to finish is at 100.
+	Percentile float64
+}
+
+// memberDayPlacing is where a member fell on each part of a day. Parts they haven't finished are nil.
+type memberDayPlacing struct {
+	Member memberData
+	Part1  *solvePlacing
+	Part2  *solvePlacing
+}
+
+// daySolveStats is the spread of a day's solve times and where each member who started it fell in them, ordered the
+// same way as the day's times in the API: whoever finished the whole day first, then everyone with only the first star.
+type daySolveStats struct {
+	Day     int
+	Part1   partSolveStats
+	Part2   partSolveStats
+	Members []memberDayPlacing
+}
+
+// computeDayStats works out the spread of the day's solve times across the leaderboard.
+func computeDayStats(leaderboard *leaderboardData, year string, day int) daySolveStats {
+	unlock := dayUnlock(year, day)
+	stats := daySolveStats{Day: day}
+
+	placings := map[int]*memberDayPlacing{}
+	for part, partStats := range []*partSolveStats{&stats.Part1, &stats.Part2} {
+		finishers := dayFinishers(leaderboard, day, part+1)
+		times := make([]time.Duration, len(finishers))
+		for idx, f := range finishers {
+			times[idx] = f.At.Sub(unlock)
+		}
+		*partStats = summarizeSolveTimes(times)
+
+		for idx, f := range finishers {
+			placing := placings[f.Member.ID]
+			if placing == nil {
+				placing = &memberDayPlacing{Member: f.Member}
+				placings[f.Member.ID] = placing
+			}
+			solved := &solvePlacing{Time: times[idx], Percentile: solvePercentile(times, times[idx])}
+			if part == 0 {
+				placing.Part1 = solved
+			} else {
+				placing.Part2 = solved
+			}
+		}
+	}
+
+	for _, placing := range placings {
+		stats.Members = append(stats.Members, *placing)
+	}
+	sort.Slice(stats.Members, func(i, j int) bool {
+		a, b := stats.Members[i], stats.Members[j]
+		if (a.Part2 == nil) != (b.Part2 == nil) {
+			return a.Part2 != nil
+		}
+		if a.Part2 != nil && a.Part2.Time != b.Part2.Time {
+			return a.Part2.Time < b.Part2.Time
+		}
+		if a.Part1 != nil && b.Part1 != nil && a.Part1.Time != b.Part1.Time {
+			return a.Part1.Time < b.Part1.Time
+		}
+		return a.Member.ID < b.Member.ID
+	})
+
+	return stats
+}
+
+// summarizeSolveTimes gives the quartiles of times, which are sorted fastest first. Quartiles fall between times when
+// there isn't one exactly at them, the same way spreadsheets work them out.
+func summarizeSolveTimes(times []time.Duration) partSolveStats {
+	stats := partSolveStats{Solvers: len(times)}
+	if len(times) == 0 {
+		return stats
+	}
+
+	quantile := func(q float64) time.Duration {
+		pos := q * float64(len(times)-1)
+		lower := int(math.Floor(pos))
+		upper := int(math.Ceil(pos))
+		frac := pos - float64(lower)
+		return (times[lower] + time.Duration(frac*float64(times[upper]-times[lower]))).Round(time.Second)
+	}
+	stats.P25, stats.Median, stats.P75 = quantile(0.25), quantile(0.5), quantile(0.75)
+	return stats
+}
+
+// solvePercentile is the share of the other times, from 0 to 100, that are slower than t, with ties counting half.
+func solvePercentile(times []time.Duration, t time.Duration) float64 {
+	if len(times) < 2 {
+		return 100
+	}
+
+	slower, ties := 0, -1 // t is in times, and doesn't tie with itself
+	for _, other := range times {
+		switch {
+		case other > t:
+			slower++
+		case other == t:
+			ties++
+		}
+	}
+	return 100 * (float64(slower) + float64(ties)/2) / float64(len(times)-1)
+}
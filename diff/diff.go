@@ -0,0 +1,237 @@
+// Package diff compares two downloads of a leaderboard and describes everything worth announcing about what changed
+// between them.
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/leaderboard"
+)
+
+// Options configures how changes are detected and described.
+type Options struct {
+	// Year is the event the leaderboards are for.
+	Year string
+	// LeaderboardID is the private leaderboard's ID, which announcements link to.
+	LeaderboardID string
+	// Location is the timezone completion times are given in. Nil means UTC.
+	Location *time.Location
+	// MinStars holds back announcements about a member until they have at least this many stars.
+	MinStars int
+	// Unranked is the IDs of members whose stars are announced without where they finished, and who don't count
+	// toward where anyone else did.
+	Unranked map[int]bool
+	// Debugf, if set, is told about every member as they're compared.
+	Debugf func(format string, args ...any)
+}
+
+// Event is something worth announcing.
+type Event struct {
+	// Key uniquely identifies the event, so it can be used to make sure it's only ever announced once.
+	Key     string
+	Content string
+	// At is the unix time the event happened, or 0 for joins, which the site doesn't timestamp.
+	At int64
+}
+
+// JoinKey is the key of the event for a member joining the leaderboard.
+func JoinKey(year, leaderboardID string, memberID int) string {
+	return fmt.Sprintf("%s/%s/%d/join", year, leaderboardID, memberID)
+}
+
+// StarKey is the key of the event for a member earning a star.
+func StarKey(year, leaderboardID string, memberID, day, part int) string {
+	return fmt.Sprintf("%s/%s/%d/star/%d/%d", year, leaderboardID, memberID, day, part)
+}
+
+var ordinals = []string{"th", "st", "nd", "rd"}
+
+// Ordinal spells out a place, e.g. 1st or 100th.
+func Ordinal(n int) string {
+	return fmt.Sprintf("%d%s", n, ordinalSuffix(n))
+}
+
+func ordinalSuffix(n int) string {
+	v := n % 100
+	if v >= 20 && len(ordinals) > (v-20)%10 {
+		return ordinals[(v-20)%10]
+	}
+	if len(ordinals) > v {
+		return ordinals[v]
+	}
+	return ordinals[0]
+}
+
+// Baseline is everything Events needs to know about the last copy of a leaderboard, which is much less than the copy
+// itself, so that the copy doesn't have to be kept while the next one is read.
+type Baseline struct {
+	members []baselineMember
+}
+
+// baselineMember is what's compared about one member of the last copy of a leaderboard.
+type baselineMember struct {
+	id    int
+	name  string
+	stars int
+	hash  uint64
+	// parts has a bit set for each part the member had finished: day 1's part 1 is the lowest bit, then its part 2,
+	// and so on
+	parts uint64
+}
+
+func (m *baselineMember) finished(dayIdx, partNum int) bool {
+	return m.parts&(1<<(dayIdx*2+partNum-1)) != 0
+}
+
+// NewBaseline summarizes a copy of a leaderboard for comparing the next copy against.
+func NewBaseline(last *leaderboard.Leaderboard) Baseline {
+	baseline := Baseline{members: make([]baselineMember, 0, len(last.Members))}
+	for idx := range last.Members {
+		member := &last.Members[idx]
+		summary := baselineMember{id: member.ID, name: member.Name, stars: member.Stars, hash: leaderboard.MemberHash(member)}
+		for dayIdx, day := range member.CompletionDayLevel {
+			if day.Part1 != nil {
+				summary.parts |= 1 << (dayIdx * 2)
+			}
+			if day.Part2 != nil {
+				summary.parts |= 1 << (dayIdx*2 + 1)
+			}
+		}
+		baseline.members = append(baseline.members, summary)
+	}
+	return baseline
+}
+
+// Events compares two copies of a leaderboard and returns everything worth announcing, oldest first.
+func Events(lastLeaderboard, curr *leaderboard.Leaderboard, opts Options) []Event {
+	return EventsSince(NewBaseline(lastLeaderboard), curr, opts)
+}
+
+// EventsSince is Events for when all that's been kept of the last copy of the leaderboard is its Baseline.
+func EventsSince(last Baseline, curr *leaderboard.Leaderboard, opts Options) []Event {
+	debugf := opts.Debugf
+	if debugf == nil {
+		debugf = func(string, ...any) {}
+	}
+	location := opts.Location
+	if location == nil {
+		location = time.UTC
+	}
+	year, boardID := opts.Year, opts.LeaderboardID
+	var events []Event
+
+	// where each star placed is counted among the ranked members only
+	ranked := curr
+	if len(opts.Unranked) > 0 {
+		without := *curr
+		without.Members = nil
+		for _, member := range curr.Members {
+			if !opts.Unranked[member.ID] {
+				without.Members = append(without.Members, member)
+			}
+		}
+		ranked = &without
+	}
+
+	debugf("Comparing %d cached members against %d downloaded members", len(last.members), len(curr.Members))
+	lastMembers := make(map[int]*baselineMember, len(last.members))
+	for idx := range last.members {
+		lastMembers[last.members[idx].id] = &last.members[idx]
+	}
+	currIDs := make(map[int]bool, len(curr.Members))
+	for _, member := range curr.Members {
+		currIDs[member.ID] = true
+	}
+	for _, lastMember := range last.members {
+		if !currIDs[lastMember.id] {
+			debugf("%s (%d) is no longer on the leaderboard; nothing to announce", lastMember.name, lastMember.id)
+		}
+	}
+
+	for _, member := range curr.Members {
+		if member.Stars < opts.MinStars {
+			debugf("%s (%d) has %d stars, fewer than the %d required to be announced; skipping", member.Name, member.ID, member.Stars, opts.MinStars)
+			continue
+		}
+
+		lastMember := lastMembers[member.ID]
+		// on a big leaderboard most members haven't done anything since the last scan, and there's no need to look any
+		// closer at them
+		if lastMember != nil && lastMember.hash == leaderboard.MemberHash(&member) {
+			debugf("%s (%d) is unchanged, still at %d stars", member.Name, member.ID, member.Stars)
+			continue
+		}
+		// members below the star threshold were never announced, so reaching it is when they "appear"
+		if lastMember == nil || lastMember.stars < opts.MinStars {
+			debugf("%s (%d) is new to the leaderboard with %d stars", member.Name, member.ID, member.Stars)
+			// todo: report if they've already got stars on the year
+			events = append(events, Event{
+				Key:     JoinKey(year, boardID, member.ID),
+				Content: fmt.Sprintf(":tada: A new challenger has appeared! Welcome, %s, to [the leaderboard](https://adventofcode.com/%s/leaderboard/private/view/%s)! :tada:", leaderboard.DisplayName(member), year, boardID),
+				// joins aren't timestamped, so announce them before any stars from the same scan
+				At: 0,
+			})
+
+			if lastMember == nil {
+				continue
+			}
+		}
+
+		if lastMember.stars == member.Stars {
+			debugf("No new stars for %s (%d), still at %d", member.Name, member.ID, member.Stars)
+			continue
+		}
+		debugf("%s (%d) went from %d to %d stars", member.Name, member.ID, lastMember.stars, member.Stars)
+
+		for dayIdx, day := range member.CompletionDayLevel {
+			s := func(part *leaderboard.Part, partNum int) {
+				// in case we get two updates at once, this prevents us from saying the same number of total stars for both parts.
+				// it's never possible to have part2 completed before part 1 for a day, so this is all we need to check.
+				skipPart2OfDay := -1
+				if partNum == 1 {
+					skipPart2OfDay = dayIdx
+				}
+				totalStars := leaderboard.TotalStars(&member, skipPart2OfDay)
+				totalStarsPlural := "s"
+				if totalStars == 1 {
+					totalStarsPlural = ""
+				}
+
+				completionTime := time.Unix(part.GotStarAt, 0).In(location).Format("3:04:05pm")
+				place := ""
+				if !opts.Unranked[member.ID] {
+					rank := leaderboard.CompletionRank(ranked, &member, dayIdx, partNum) + 1
+					place = " " + Ordinal(rank)
+				}
+				events = append(events, Event{
+					Key: StarKey(year, boardID, member.ID, dayIdx+1, partNum),
+					Content: fmt.Sprintf(
+						":tada: %s completed day %d part %d%s on [the leaderboard](https://adventofcode.com/%s/leaderboard/private/view/%s) at %s, and now has %d star%s on the year. :tada:",
+						leaderboard.DisplayName(member),
+						dayIdx+1,
+						partNum,
+						place,
+						year,
+						boardID,
+						completionTime,
+						totalStars,
+						totalStarsPlural,
+					),
+					At: part.GotStarAt,
+				})
+			}
+
+			if day.Part1 != nil && !lastMember.finished(dayIdx, 1) {
+				s(day.Part1, 1)
+			}
+			if day.Part2 != nil && !lastMember.finished(dayIdx, 2) {
+				s(day.Part2, 2)
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At < events[j].At })
+	return events
+}
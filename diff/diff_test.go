@@ -0,0 +1,146 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/leaderboard/leaderboardtest"
+)
+
+// unlock is when day 1 of the 2023 event unlocked.
+const unlock = 1701406800
+
+var (
+	ada       = leaderboardtest.Member{ID: 1, Name: "Ada", Days: map[int][2]int64{1: {unlock + 60, unlock + 120}}}
+	grace     = leaderboardtest.Member{ID: 2, Name: "Grace", Days: map[int][2]int64{1: {unlock + 30, 0}}}
+	graceDone = leaderboardtest.Member{ID: 2, Name: "Grace", Days: map[int][2]int64{1: {unlock + 30, unlock + 150}}}
+)
+
+func TestEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		last, curr []leaderboardtest.Member
+		opts       Options
+		want       []Event
+	}{
+		{
+			name: "unchanged",
+			last: []leaderboardtest.Member{ada, grace},
+			curr: []leaderboardtest.Member{ada, grace},
+		},
+		{
+			name: "left",
+			last: []leaderboardtest.Member{ada, grace},
+			curr: []leaderboardtest.Member{ada},
+		},
+		{
+			name: "joined",
+			last: []leaderboardtest.Member{ada},
+			curr: []leaderboardtest.Member{ada, {ID: 3}},
+			want: []Event{{
+				Key:     "2023/123/3/join",
+				Content: ":tada: A new challenger has appeared! Welcome, (anonymous user #3), to [the leaderboard](https://adventofcode.com/2023/leaderboard/private/view/123)! :tada:",
+			}},
+		},
+		{
+			name: "star",
+			last: []leaderboardtest.Member{ada, grace},
+			curr: []leaderboardtest.Member{ada, graceDone},
+			want: []Event{{
+				Key:     "2023/123/2/star/1/2",
+				Content: ":tada: Grace completed day 1 part 2 2nd on [the leaderboard](https://adventofcode.com/2023/leaderboard/private/view/123) at 5:02:30am, and now has 2 stars on the year. :tada:",
+				At:      unlock + 150,
+			}},
+		},
+		{
+			name: "both parts at once",
+			last: []leaderboardtest.Member{{ID: 1, Name: "Ada"}},
+			curr: []leaderboardtest.Member{ada},
+			opts: Options{Location: time.FixedZone("EST", -5*60*60)},
+			want: []Event{
+				{
+					Key:     "2023/123/1/star/1/1",
+					Content: ":tada: Ada completed day 1 part 1 1st on [the leaderboard](https://adventofcode.com/2023/leaderboard/private/view/123) at 12:01:00am, and now has 1 star on the year. :tada:",
+					At:      unlock + 60,
+				},
+				{
+					Key:     "2023/123/1/star/1/2",
+					Content: ":tada: Ada completed day 1 part 2 1st on [the leaderboard](https://adventofcode.com/2023/leaderboard/private/view/123) at 12:02:00am, and now has 2 stars on the year. :tada:",
+					At:      unlock + 120,
+				},
+			},
+		},
+		{
+			name: "below minStars",
+			last: []leaderboardtest.Member{{ID: 2, Name: "Grace"}},
+			curr: []leaderboardtest.Member{grace},
+			opts: Options{MinStars: 2},
+		},
+		{
+			name: "reaching minStars",
+			last: []leaderboardtest.Member{grace},
+			curr: []leaderboardtest.Member{graceDone},
+			opts: Options{MinStars: 2},
+			want: []Event{
+				{
+					Key:     "2023/123/2/join",
+					Content: ":tada: A new challenger has appeared! Welcome, Grace, to [the leaderboard](https://adventofcode.com/2023/leaderboard/private/view/123)! :tada:",
+				},
+				{
+					Key:     "2023/123/2/star/1/2",
+					Content: ":tada: Grace completed day 1 part 2 1st on [the leaderboard](https://adventofcode.com/2023/leaderboard/private/view/123) at 5:02:30am, and now has 2 stars on the year. :tada:",
+					At:      unlock + 150,
+				},
+			},
+		},
+		{
+			name: "unranked member",
+			last: []leaderboardtest.Member{{ID: 1, Name: "Ada"}, {ID: 2, Name: "Grace"}},
+			curr: []leaderboardtest.Member{ada, grace},
+			opts: Options{Unranked: map[int]bool{2: true}},
+			want: []Event{
+				{
+					Key:     "2023/123/2/star/1/1",
+					Content: ":tada: Grace completed day 1 part 1 on [the leaderboard](https://adventofcode.com/2023/leaderboard/private/view/123) at 5:00:30am, and now has 1 star on the year. :tada:",
+					At:      unlock + 30,
+				},
+				{
+					Key:     "2023/123/1/star/1/1",
+					Content: ":tada: Ada completed day 1 part 1 1st on [the leaderboard](https://adventofcode.com/2023/leaderboard/private/view/123) at 5:01:00am, and now has 1 star on the year. :tada:",
+					At:      unlock + 60,
+				},
+				{
+					Key:     "2023/123/1/star/1/2",
+					Content: ":tada: Ada completed day 1 part 2 1st on [the leaderboard](https://adventofcode.com/2023/leaderboard/private/view/123) at 5:02:00am, and now has 2 stars on the year. :tada:",
+					At:      unlock + 120,
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.opts.Year, test.opts.LeaderboardID = "2023", "123"
+			last, curr := leaderboardtest.New(t, "2023", test.last...), leaderboardtest.New(t, "2023", test.curr...)
+			got := Events(last, curr, test.opts)
+			if len(got) == 0 && len(test.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got events:\n%+v\nwant:\n%+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	tests := map[int]string{
+		1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 10: "10th", 11: "11th", 12: "12th", 13: "13th",
+		21: "21st", 22: "22nd", 23: "23rd", 100: "100th", 101: "101st", 111: "111th", 112: "112th",
+	}
+	for n, want := range tests {
+		if got := Ordinal(n); got != want {
+			t.Errorf("Ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func runDiffCommand(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	since := fs.Duration("since", 0, "compare the latest snapshot against the one from this long ago, e.g. 24h")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !(*since > 0 && fs.NArg() == 0) && !(*since == 0 && fs.NArg() == 2) {
+		return errors.New("usage: diff <snapshot id> <snapshot id> | diff -since <duration>")
+	}
+
+	history, partition, historyErr := openConfiguredHistory()
+	if historyErr != nil {
+		return historyErr
+	}
+
+	board, boardErr := configuredBoard(partition.Leaderboard)
+	if boardErr != nil {
+		return boardErr
+	}
+
+	var fromID, toID int64
+	if *since > 0 {
+		snaps, listErr := history.Snapshots(partition)
+		if listErr != nil {
+			return listErr
+		}
+		if len(snaps) < 2 {
+			return fmt.Errorf("need at least two snapshots of %s to compare", partition)
+		}
+
+		// the newest snapshot that's at least as old as requested, or the oldest one if history doesn't go back that far
+		cutoff := time.Now().Add(-*since)
+		fromID = snaps[0].ID
+		for _, snap := range snaps {
+			if snap.FetchedAt.After(cutoff) {
+				break
+			}
+			fromID = snap.ID
+		}
+		toID = snaps[len(snaps)-1].ID
+	} else {
+		var parseErr error
+		if fromID, parseErr = strconv.ParseInt(fs.Arg(0), 10, 64); parseErr != nil {
+			return fmt.Errorf("invalid snapshot id %q", fs.Arg(0))
+		}
+		if toID, parseErr = strconv.ParseInt(fs.Arg(1), 10, 64); parseErr != nil {
+			return fmt.Errorf("invalid snapshot id %q", fs.Arg(1))
+		}
+	}
+
+	fromSnap, from, loadErr := loadSnapshotLeaderboard(history, fromID)
+	if loadErr != nil {
+		return loadErr
+	}
+	toSnap, to, loadErr := loadSnapshotLeaderboard(history, toID)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	fmt.Printf("Changes from %s (snapshot %d) to %s (snapshot %d):\n",
+		fromSnap.FetchedAt.In(board.Location).Format(time.RFC3339), fromID,
+		toSnap.FetchedAt.In(board.Location).Format(time.RFC3339), toID,
+	)
+	changes := leaderboardChanges(from, to)
+	if len(changes) == 0 {
+		fmt.Println("Nothing changed.")
+	}
+	for _, change := range changes {
+		fmt.Println(" ", change)
+	}
+
+	return nil
+}
+
+// leaderboardChanges describes everything that differs between two copies of a leaderboard, one line per member.
+func leaderboardChanges(from, to *leaderboardData) []string {
+	var changes []string
+	for _, member := range sortedStandings(to) {
+		prev := arrayFind(from.Members, func(m memberData) bool { return m.ID == member.ID })
+		if prev == nil {
+			changes = append(changes, fmt.Sprintf("%s joined with %d stars and %d points", displayName(member), member.Stars, member.LocalScore))
+			continue
+		}
+		if prev.Stars == member.Stars && prev.LocalScore == member.LocalScore {
+			continue
+		}
+
+		var earned []string
+		for dayIdx, day := range member.CompletionDayLevel {
+			if day.Part1 != nil && prev.CompletionDayLevel[dayIdx].Part1 == nil {
+				earned = append(earned, fmt.Sprintf("%d-1", dayIdx+1))
+			}
+			if day.Part2 != nil && prev.CompletionDayLevel[dayIdx].Part2 == nil {
+				earned = append(earned, fmt.Sprintf("%d-2", dayIdx+1))
+			}
+		}
+
+		change := fmt.Sprintf("%s: %+d stars (%d to %d), %+d points (%d to %d)",
+			displayName(member),
+			member.Stars-prev.Stars, prev.Stars, member.Stars,
+			member.LocalScore-prev.LocalScore, prev.LocalScore, member.LocalScore,
+		)
+		if len(earned) > 0 {
+			change += "; earned " + strings.Join(earned, ", ")
+		}
+		changes = append(changes, change)
+	}
+
+	for _, prev := range from.Members {
+		if !arrayContains(to.Members, func(m memberData) bool { return m.ID == prev.ID }) {
+			changes = append(changes, fmt.Sprintf("%s left with %d stars and %d points", displayName(prev), prev.Stars, prev.LocalScore))
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func newLeaderboardSettings(id, timezone, digestTime string) (leaderboardSettings, error) {
+	if len(timezone) == 0 {
+		timezone = defaultTimezone
+	}
+
+	loc, locErr := time.LoadLocation(timezone)
+	if locErr != nil {
+		return leaderboardSettings{}, fmt.Errorf("unable to load timezone %q for leaderboard %s: %w", timezone, id, locErr)
+	}
+
+	if len(digestTime) > 0 {
+		if _, parseErr := time.Parse("15:04", digestTime); parseErr != nil {
+			return leaderboardSettings{}, fmt.Errorf("invalid digest time %q for leaderboard %s, expected HH:MM: %w", digestTime, id, parseErr)
+		}
+	}
+
+	return leaderboardSettings{
+		ID:         id,
+		Location:   loc,
+		DigestTime: digestTime,
+	}, nil
+}
+
+// digestSchedule returns the cron spec that fires once a day at the board's digest time in its own timezone.
+func (s leaderboardSettings) digestSchedule() string {
+	t, _ := time.Parse("15:04", s.DigestTime)
+	return fmt.Sprintf("CRON_TZ=%s %d %d * * *", s.Location.String(), t.Minute(), t.Hour())
+}
+
+// buildDigest is the daily standings, followed by the handicapped standings if handicaps isn't nil, the heatmap with
+// digestHeatmap, the highest-rated members if ratings isn't nil, and how the latest day compared to the global
+// leaderboard if global isn't nil.
+func buildDigest(leaderboard *leaderboardData, year string, board leaderboardSettings, ratings map[int]float64, global *globalComparison, handicaps map[int]float64) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, ":calendar: Standings for [the leaderboard](https://adventofcode.com/%s/leaderboard/private/view/%s) as of %s:\n\n",
+		year,
+		board.ID,
+		time.Now().In(board.Location).Format("Jan 2 3:04pm MST"),
+	)
+	custom := customScores(leaderboard, year)
+	customHeader, customDivider := customColumn(custom)
+	sb.WriteString("| Rank | Name | Stars | Score |" + customHeader + "\n")
+	sb.WriteString("| ---: | ---- | ----: | ----: |" + customDivider + "\n")
+	for idx, member := range rankedStandings(leaderboard, year) {
+		fmt.Fprintf(&sb, "| %d | %s | %d | %s |%s\n", idx+1, displayName(member), member.Stars, formatScore(member.LocalScore), customCell(custom, member.ID))
+	}
+	sb.WriteString(unrankedSection(leaderboard))
+	sb.WriteString(handicapSection(leaderboard, year, handicaps))
+	sb.WriteString(heatmapSection(leaderboard, year))
+	sb.WriteString(ratingsSection(leaderboard, ratings))
+	sb.WriteString(globalSection(leaderboard, year, global))
+
+	return sb.String()
+}
+
+// starsSince counts the stars a member has earned since the given time.
+func starsSince(member *memberData, since time.Time) int {
+	count := 0
+	for _, day := range member.CompletionDayLevel {
+		for _, part := range []*completionPartData{day.Part1, day.Part2} {
+			if part != nil && part.GotStarAt >= since.Unix() {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// buildWeeklyDigest is the standings along with how many stars and places each member gained over the past week, who
+// improved the most on their earlier days, the handicapped standings if handicaps isn't nil, and with weeklyPrediction,
+// who's likeliest to win.
+func buildWeeklyDigest(leaderboard *leaderboardData, year string, board leaderboardSettings, now time.Time, handicaps map[int]float64) string {
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, ":calendar: Weekly recap for [the leaderboard](https://adventofcode.com/%s/leaderboard/private/view/%s), %s to %s:\n\n",
+		year,
+		board.ID,
+		weekAgo.In(board.Location).Format("Jan 2"),
+		now.In(board.Location).Format("Jan 2"),
+	)
+	custom := customScores(leaderboard, year)
+	customHeader, customDivider := customColumn(custom)
+	sb.WriteString("| Rank | Name | Stars | Stars this week | Score |" + customHeader + "\n")
+	sb.WriteString("| ---: | ---- | ----: | --------------: | ----: |" + customDivider + "\n")
+	for idx, member := range rankedStandings(leaderboard, year) {
+		fmt.Fprintf(&sb, "| %d | %s | %d | +%d | %s |%s\n", idx+1, displayName(member), member.Stars, starsSince(&member, weekAgo), formatScore(member.LocalScore), customCell(custom, member.ID))
+	}
+	sb.WriteString(unrankedSection(leaderboard))
+	sb.WriteString(handicapSection(leaderboard, year, handicaps))
+	sb.WriteString(mostImprovedSection(leaderboard, year, weekAgo))
+	sb.WriteString(predictionSection(leaderboard, year, now))
+
+	return sb.String()
+}
+
+// buildFinalDigest is the end-of-event recap: the final standings, the handicapped standings if handicaps isn't nil, who
+// finished each day first, the heatmap with digestHeatmap, and the highest-rated members if ratings isn't nil.
+func buildFinalDigest(leaderboard *leaderboardData, year string, board leaderboardSettings, ratings map[int]float64, handicaps map[int]float64) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, ":trophy: Final standings for [the leaderboard](https://adventofcode.com/%s/leaderboard/private/view/%s):\n\n", year, board.ID)
+	custom := customScores(leaderboard, year)
+	customHeader, customDivider := customColumn(custom)
+	sb.WriteString("| Rank | Name | Stars | Score |" + customHeader + "\n")
+	sb.WriteString("| ---: | ---- | ----: | ----: |" + customDivider + "\n")
+	for idx, member := range rankedStandings(leaderboard, year) {
+		fmt.Fprintf(&sb, "| %d | %s | %d | %s |%s\n", idx+1, displayName(member), member.Stars, formatScore(member.LocalScore), customCell(custom, member.ID))
+	}
+	sb.WriteString(unrankedSection(leaderboard))
+	sb.WriteString(handicapSection(leaderboard, year, handicaps))
+
+	var winners []string
+	for day := 1; day <= 25; day++ {
+		finishers := dayFinishers(rankedLeaderboard(leaderboard), day, 2)
+		if len(finishers) == 0 {
+			continue
+		}
+		winners = append(winners, fmt.Sprintf("* Day %d: %s in %s", day, displayName(finishers[0].Member), formatElapsed(finishers[0].At.Sub(dayUnlock(year, day)))))
+	}
+	if len(winners) > 0 {
+		sb.WriteString("\nFirst to finish each day:\n")
+		sb.WriteString(strings.Join(winners, "\n"))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(heatmapSection(leaderboard, year))
+	sb.WriteString(ratingsSection(leaderboard, ratings))
+
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+func runDigestCommand(args []string) error {
+	fs := flag.NewFlagSet("digest", flag.ContinueOnError)
+	daily := fs.Bool("daily", false, "post the same standings digest as the daily scheduled one (the default)")
+	weekly := fs.Bool("weekly", false, "post a recap of the past week")
+	final := fs.Bool("final", false, "post the final standings and each day's winner")
+	combined := fs.Bool("combined", false, "post the standings combined with every board in federationBoards, from the store's cached scans")
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	dryRun := fs.Bool("dryRun", false, "print the digest instead of sending it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	chosen := 0
+	for _, kind := range []bool{*daily, *weekly, *final, *combined} {
+		if kind {
+			chosen++
+		}
+	}
+	if chosen > 1 {
+		return errors.New("usage: digest [-daily | -weekly | -final | -combined] [-cached] [-dryRun]")
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	var s *scanner
+	if !*dryRun {
+		// digests are recorded in the store the same way a scan's notifications are
+		var scannerErr error
+		if s, scannerErr = newScanner(store); scannerErr != nil {
+			return scannerErr
+		}
+	}
+
+	var leaderboard *leaderboardData
+	var board leaderboardSettings
+	var boards int
+	var loadErr error
+	if *combined {
+		if board, loadErr = configuredBoard(partition.Leaderboard); loadErr != nil {
+			return loadErr
+		}
+		leaderboard, boards, loadErr = combinedLeaderboard(store, partition)
+	} else {
+		leaderboard, board, loadErr = loadStoredLeaderboard(store, partition, *cachedOnly)
+	}
+	if loadErr != nil {
+		return loadErr
+	}
+
+	var digest string
+	switch {
+	case *combined:
+		digest = buildCombinedDigest(leaderboard, *yearArg, boards, board)
+	case *weekly:
+		digest = buildWeeklyDigest(leaderboard, *yearArg, board, time.Now(), digestHandicapsFor(store, partition, leaderboard))
+	case *final:
+		digest = buildFinalDigest(leaderboard, *yearArg, board, digestRatingsFor(store, partition, leaderboard), digestHandicapsFor(store, partition, leaderboard))
+	default:
+		digest = buildDigest(leaderboard, *yearArg, board, digestRatingsFor(store, partition, leaderboard), digestGlobalFor(context.Background(), leaderboard, *yearArg), digestHandicapsFor(store, partition, leaderboard))
+	}
+
+	if *dryRun {
+		fmt.Println(digest)
+		return nil
+	}
+
+	return s.sendNotification(context.Background(), digest)
+}
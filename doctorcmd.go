@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/notify"
+)
+
+// maxClockSkew is how far the local clock can be from adventofcode.com's before scheduling and "time after unlock"
+// numbers start to look wrong.
+const maxClockSkew = time.Minute
+
+// doctorCheck is one line of the doctor command's report. A check that returns errSkipCheck isn't counted as passing
+// or failing, e.g. because what it checks isn't configured.
+type doctorCheck struct {
+	name string
+	run  func() (string, error)
+}
+
+var errSkipCheck = errors.New("skipped")
+
+func runDoctorCommand(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// the checks shouldn't keep whoever's running them waiting as long as a scan would
+	client := &http.Client{Timeout: 10 * time.Second, Transport: httpClient().Transport}
+	var serverTime time.Time
+
+	checks := []doctorCheck{
+		{"adventofcode.com", func() (string, error) {
+			start := time.Now()
+			resp, err := client.Head("https://adventofcode.com/")
+			if err != nil {
+				return "", err
+			}
+			resp.Body.Close()
+			if date, dateErr := http.ParseTime(resp.Header.Get("Date")); dateErr == nil {
+				// the Date header only has whole seconds, and was generated partway through the request
+				serverTime = date.Add(time.Since(start) / 2)
+			}
+			return fmt.Sprintf("status %d in %s", resp.StatusCode, time.Since(start).Round(time.Millisecond)), nil
+		}},
+		{"clock", func() (string, error) {
+			if serverTime.IsZero() {
+				return "adventofcode.com's time isn't known", errSkipCheck
+			}
+			skew := time.Until(serverTime).Round(time.Second)
+			if skew.Abs() > maxClockSkew {
+				return "", fmt.Errorf("the local clock is %s off from adventofcode.com's", skew.Abs())
+			}
+			return fmt.Sprintf("within %s of adventofcode.com", skew.Abs()), nil
+		}},
+		{"session", func() (string, error) {
+			sessions := newSessionPool(*sessionArg, nil).sessions
+			if len(sessions) == 0 {
+				return "", errors.New("no session code provided")
+			}
+			numValid := 0
+			var lastErr error
+			for _, session := range sessions {
+				account, boards, checkErr := checkSession(*yearArg, session)
+				if checkErr != nil {
+					lastErr = fmt.Errorf("session %s: %w", sessionFingerprint(session), checkErr)
+					continue
+				}
+				usable := true
+				for _, id := range configuredLeaderboards() {
+					if !arrayContains(boards, func(b privateBoard) bool { return b.ID == id }) {
+						lastErr = fmt.Errorf("%s can't view leaderboard %s", account, id)
+						usable = false
+					}
+				}
+				if usable {
+					numValid++
+				}
+			}
+			if lastErr != nil {
+				return "", fmt.Errorf("%d of %d sessions aren't usable; last problem: %w", len(sessions)-numValid, len(sessions), lastErr)
+			}
+			return fmt.Sprintf("%d of %d sessions valid", numValid, len(sessions)), nil
+		}},
+		{"webhook", func() (string, error) {
+			return checkWebhookReachable(client, *webhookURLArg)
+		}},
+		{"admin webhook", func() (string, error) {
+			return checkWebhookReachable(client, *adminURLArg)
+		}},
+		{"timezone", func() (string, error) {
+			ids := configuredLeaderboards()
+			if len(ids) == 0 {
+				ids = []string{""}
+			}
+			var times []string
+			for _, id := range ids {
+				board, boardErr := configuredBoard(id)
+				if boardErr != nil {
+					return "", boardErr
+				}
+				detail := fmt.Sprintf("%s is currently %s", board.Location, time.Now().In(board.Location).Format("Jan 2 3:04pm MST"))
+				if len(ids) > 1 {
+					detail = fmt.Sprintf("leaderboard %s: %s", id, detail)
+				}
+				times = append(times, detail)
+			}
+			return strings.Join(times, "; "), nil
+		}},
+		{"store", func() (string, error) {
+			store, partition, storeErr := openConfiguredStore()
+			if storeErr != nil {
+				return "", storeErr
+			}
+			state, loadErr := store.Load(partition)
+			if loadErr != nil {
+				return "", fmt.Errorf("unable to read: %w", loadErr)
+			}
+			// writing back exactly what was read proves the store is writable without changing anything
+			if err := store.Save(partition, state); err != nil {
+				return "", fmt.Errorf("readable, but unable to write: %w", err)
+			}
+			if len(state.LastBody) == 0 {
+				return fmt.Sprintf("%s is readable and writable; nothing cached yet", partition), nil
+			}
+			return fmt.Sprintf("%s is readable and writable; last scanned %s", partition, time.Unix(state.LastRead, 0).Format(time.RFC3339)), nil
+		}},
+	}
+
+	numFailed := 0
+	for _, check := range checks {
+		detail, err := check.run()
+		switch {
+		case errors.Is(err, errSkipCheck):
+			fmt.Printf("[SKIP] %s: %s\n", check.name, detail)
+		case err != nil:
+			fmt.Printf("[FAIL] %s: %v\n", check.name, err)
+			numFailed++
+		default:
+			fmt.Printf("[PASS] %s: %s\n", check.name, detail)
+		}
+	}
+
+	if numFailed > 0 {
+		return fmt.Errorf("%d of %d checks failed", numFailed, len(checks))
+	}
+	return nil
+}
+
+// checkWebhookReachable makes sure something answers at a webhook's address without posting anything to it. Webhooks
+// rarely accept anything but a POST, so any HTTP response at all counts.
+func checkWebhookReachable(client *http.Client, webhook string) (string, error) {
+	if len(webhook) == 0 {
+		return "not configured", errSkipCheck
+	}
+	_, u, parseErr := notify.Parse(webhook)
+	if parseErr != nil {
+		return "", parseErr
+	}
+
+	resp, err := client.Head(u.String())
+	if err != nil {
+		// the full URL is usually a secret, so leave it out of the report
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("unable to reach %s: %w", u.Host, err)
+	}
+	resp.Body.Close()
+	return fmt.Sprintf("%s answered with status %d", u.Host, resp.StatusCode), nil
+}
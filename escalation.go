@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+var (
+	escalateAfterArg         = flag.Int("escalateAfter", 4, "how many scans in a row can fail to download the leaderboard or deliver its notifications before the admin destination is told, readiness fails, and scheduled scans slow to degradedFetchInterval; 0 never escalates")
+	degradedFetchIntervalArg = flag.Duration("degradedFetchInterval", time.Hour, "minimum time between scheduled downloads while scans keep failing")
+)
+
+// maxFailureLength is as much of a failure as is kept in the state, since it ends up in alerts and readiness checks.
+const maxFailureLength = 200
+
+// escalated reports whether enough scans in a row have failed that they're being treated as an outage rather than a
+// blip.
+func escalated(state *scanState) bool {
+	return *escalateAfterArg > 0 && state.Failures >= *escalateAfterArg
+}
+
+// scheduledInterval is how long a scheduled scan waits between downloads, which is longer while scans keep failing so
+// that a broken session or an unreachable site isn't hammered until someone fixes it.
+func scheduledInterval(state *scanState, interval time.Duration) time.Duration {
+	if escalated(state) && *degradedFetchIntervalArg > interval {
+		return *degradedFetchIntervalArg
+	}
+	return interval
+}
+
+// trackFailures records how a scan went, escalating to the admin destination once too many in a row have failed and
+// telling it again once one succeeds. A scan that was too soon to download anything, or that was cut short by
+// shutting down, doesn't count either way.
+func (s *scanner) trackFailures(ctx context.Context, board leaderboardSettings, state *scanState, scanErr error, saveState func(scanState)) {
+	var tooSoon errTooSoon
+	if errors.As(scanErr, &tooSoon) || ctx.Err() != nil {
+		return
+	}
+
+	failure := ""
+	switch {
+	case scanErr != nil:
+		failure = scanErr.Error()
+	case len(state.Outbox) > 0:
+		// delivery errors can include the webhook's url, which shouldn't end up in alerts or readiness checks
+		failure = fmt.Sprintf("%d notifications couldn't be delivered", len(state.Outbox))
+	}
+
+	if len(failure) == 0 {
+		if state.Failures == 0 {
+			return
+		}
+		if escalated(state) {
+			logInfo("Leaderboard", board.ID, "scanned successfully after", state.Failures, "failures in a row")
+			alert := fmt.Sprintf(":white_check_mark: Leaderboard %s is scanning successfully again after %d failures in a row.", board.ID, state.Failures)
+			if err := s.sendAdminNotification(ctx, alert); err != nil {
+				logError("Error sending admin notification:", err)
+			}
+		}
+		state.Failures, state.LastFailure = 0, ""
+		saveState(*state)
+		return
+	}
+
+	if len(failure) > maxFailureLength {
+		failure = failure[:maxFailureLength] + "..."
+	}
+	state.Failures++
+	state.LastFailure = failure
+	saveState(*state)
+
+	if *escalateAfterArg > 0 && state.Failures == *escalateAfterArg {
+		logWarn("Leaderboard", board.ID, "failed to scan", state.Failures, "times in a row; scheduled scans will wait at least", *degradedFetchIntervalArg, "between downloads until one succeeds. The last failure:", failure)
+		alert := fmt.Sprintf(":rotating_light: The last %d scans of leaderboard %s failed, most recently with: %s\nScheduled scans will wait at least %s between downloads until one succeeds.", state.Failures, board.ID, failure, *degradedFetchIntervalArg)
+		if err := s.sendAdminNotification(ctx, alert); err != nil {
+			logError("Error sending admin notification:", err)
+		}
+	}
+}
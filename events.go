@@ -0,0 +1,191 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/diff"
+)
+
+// liveEventPollInterval is how often the server checks the store for a new scan to stream events from. Scans are at
+// least 15 minutes apart, so this only bounds how late a live client hears about one.
+const liveEventPollInterval = 15 * time.Second
+
+// liveEvent is something that happened on the leaderboard, as streamed to live clients.
+type liveEvent struct {
+	// Type is "join", "star", or "rank".
+	Type     string    `json:"type"`
+	At       time.Time `json:"at"`
+	MemberID int       `json:"member_id"`
+	Name     string    `json:"name"`
+	Day      int       `json:"day,omitempty"`
+	Part     int       `json:"part,omitempty"`
+	// Rank is where the member finished the part for a star, or their new place in the standings for a rank change.
+	Rank int `json:"rank,omitempty"`
+	// PrevRank is the member's previous place in the standings, for a rank change.
+	PrevRank int `json:"prev_rank,omitempty"`
+	Stars    int `json:"stars"`
+	// Message is the notification sent for a join or star.
+	Message string `json:"message,omitempty"`
+}
+
+// scanBaseline is what's kept of a scan of a leaderboard to find live events in the next one: what detecting its
+// announcements needs, and everyone's place in the standings.
+type scanBaseline struct {
+	events diff.Baseline
+	ranks  map[int]int
+}
+
+// newScanBaseline summarizes a scan of a leaderboard. The standings are only worked out when withRanks is set, since
+// only live events need them.
+func newScanBaseline(leaderboard *leaderboardData, year string, withRanks bool) scanBaseline {
+	baseline := scanBaseline{events: diff.NewBaseline(leaderboard), ranks: map[int]int{}}
+	if withRanks {
+		for idx, member := range rankedStandings(leaderboard, year) {
+			baseline.ranks[member.ID] = idx + 1
+		}
+	}
+	return baseline
+}
+
+// liveEventsBetween returns everything that happened between two scans of a leaderboard: the same joins and stars that
+// are announced, followed by every change in the standings they caused. Joins and rank changes aren't timestamped by the
+// site, so they're given the time of the scan that found them.
+func liveEventsBetween(last scanBaseline, curr *leaderboardData, scannedAt time.Time, year string, board leaderboardSettings) []liveEvent {
+	messages := map[string]string{}
+	for _, entry := range detectEventsSince(last.events, curr, year, board) {
+		messages[entry.Key] = entry.Content
+	}
+
+	var events []liveEvent
+	for _, member := range curr.Members {
+		if message, ok := messages[joinKey(year, board.ID, member.ID)]; ok {
+			events = append(events, liveEvent{Type: "join", At: scannedAt, MemberID: member.ID, Name: displayName(member), Stars: member.Stars, Message: message})
+		}
+
+		for dayIdx, day := range member.CompletionDayLevel {
+			for partNum, part := range []*completionPartData{day.Part1, day.Part2} {
+				message, ok := messages[starKey(year, board.ID, member.ID, dayIdx+1, partNum+1)]
+				if part == nil || !ok {
+					continue
+				}
+				events = append(events, liveEvent{
+					Type:     "star",
+					At:       time.Unix(part.GotStarAt, 0),
+					MemberID: member.ID,
+					Name:     displayName(member),
+					Day:      dayIdx + 1,
+					Part:     partNum + 1,
+					Rank:     completionRank(curr, &member, dayIdx, partNum+1),
+					Stars:    member.Stars,
+					Message:  message,
+				})
+			}
+		}
+	}
+	sortLiveEvents(events)
+
+	for idx, member := range rankedStandings(curr, year) {
+		if prev, ok := last.ranks[member.ID]; ok && prev != idx+1 && member.Stars >= *minStarsArg {
+			events = append(events, liveEvent{Type: "rank", At: scannedAt, MemberID: member.ID, Name: displayName(member), Rank: idx + 1, PrevRank: prev, Stars: member.Stars})
+		}
+	}
+
+	return events
+}
+
+// sortLiveEvents puts joins before stars, and stars in the order they were earned, the same order they're announced in.
+func sortLiveEvents(events []liveEvent) {
+	at := func(e liveEvent) int64 {
+		if e.Type == "join" {
+			return 0
+		}
+		return e.At.Unix()
+	}
+	sort.SliceStable(events, func(i, j int) bool { return at(events[i]) < at(events[j]) })
+}
+
+// eventHub fans live events out to everyone who's listening.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[chan liveEvent]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: map[chan liveEvent]struct{}{}}
+}
+
+// subscribe returns a channel that receives every event published from now on, and a function to stop receiving them.
+func (h *eventHub) subscribe() (<-chan liveEvent, func()) {
+	ch := make(chan liveEvent, 64)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers, ch)
+		h.mu.Unlock()
+	}
+}
+
+// publish sends events to every subscriber. A subscriber that's too far behind to take any more misses them rather
+// than holding everyone else up.
+func (h *eventHub) publish(events []liveEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		for _, event := range events {
+			select {
+			case ch <- event:
+			default:
+				logDebug("Dropping live event for a subscriber that isn't keeping up")
+			}
+		}
+	}
+}
+
+// watchForEvents polls the store for new scans and publishes what changed in each one to the server's event hub,
+// until stop is closed. It works the same whether the scanner is in this process or another one sharing the store.
+func (s *server) watchForEvents(stop <-chan struct{}) {
+	var lastRead int64
+	var last *scanBaseline
+
+	check := func() {
+		leaderboard, state, loadErr := s.cachedLeaderboard()
+		if loadErr != nil {
+			logError("Error loading leaderboard for live events:", loadErr)
+			return
+		}
+		if leaderboard == nil || state.LastRead == lastRead {
+			return
+		}
+
+		if last != nil {
+			reloadMu.RLock()
+			events := liveEventsBetween(*last, leaderboard, time.Unix(state.LastRead, 0), s.partition.Year, s.board)
+			reloadMu.RUnlock()
+			if len(events) > 0 {
+				logDebugf("Publishing %d live events", len(events))
+				s.events.publish(events)
+			}
+		}
+		baseline := newScanBaseline(leaderboard, s.partition.Year, true)
+		last, lastRead = &baseline, state.LastRead
+	}
+
+	check()
+	ticker := time.NewTicker(liveEventPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
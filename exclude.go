@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var excludeMembersArg = flag.String("excludeMembers", "", "comma-separated IDs of members to leave out of ranks and scores, such as the organizer, while still announcing and showing their stars")
+
+// excludedMembers is the set of member IDs from excludeMembers.
+func excludedMembers() map[int]bool {
+	excluded := map[int]bool{}
+	for _, field := range strings.Split(*excludeMembersArg, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(field)); err == nil {
+			excluded[id] = true
+		}
+	}
+	return excluded
+}
+
+// rankedLeaderboard is the leaderboard without the members in excludeMembers, so that scoring it is as if they'd never
+// joined: nobody's star is worth less for an excluded member having beaten them to it. It's the leaderboard itself when
+// nobody is excluded from it.
+func rankedLeaderboard(leaderboard *leaderboardData) *leaderboardData {
+	excluded := excludedMembers()
+	if len(excluded) == 0 || arrayFind(leaderboard.Members, func(m memberData) bool { return excluded[m.ID] }) == nil {
+		return leaderboard
+	}
+
+	ranked := *leaderboard
+	ranked.Members = nil
+	for _, member := range leaderboard.Members {
+		if !excluded[member.ID] {
+			ranked.Members = append(ranked.Members, member)
+		}
+	}
+	return &ranked
+}
+
+// unrankedMembers is the members of the leaderboard in excludeMembers, in the site's order.
+func unrankedMembers(leaderboard *leaderboardData) []memberData {
+	excluded := excludedMembers()
+	var members []memberData
+	for _, member := range sortedStandings(leaderboard) {
+		if excluded[member.ID] {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// standing is a member's place in listedStandings.
+type standing struct {
+	memberData
+	// Rank is the member's place in rankedStandings, or 0 if they're in excludeMembers.
+	Rank int
+}
+
+// listedStandings is rankedStandings followed by the members in excludeMembers, for listings that show everyone on the
+// leaderboard. Excluded members aren't scored, so their LocalScore is the site's.
+func listedStandings(leaderboard *leaderboardData, year string) []standing {
+	var standings []standing
+	for idx, member := range rankedStandings(leaderboard, year) {
+		standings = append(standings, standing{memberData: member, Rank: idx + 1})
+	}
+	for _, member := range unrankedMembers(leaderboard) {
+		standings = append(standings, standing{memberData: member})
+	}
+	return standings
+}
+
+// rankText is a rank from listedStandings as it's shown in a table, with a dash for an excluded member.
+func rankText(rank int) string {
+	if rank == 0 {
+		return "-"
+	}
+	return strconv.Itoa(rank)
+}
+
+// listedScoreText is a member's score from listedStandings as it's shown in a table, with a dash for an excluded
+// member.
+func listedScoreText(s standing) string {
+	if s.Rank == 0 {
+		return "-"
+	}
+	return formatScore(s.LocalScore)
+}
+
+// completionRank is where a member finished a part of a day (an index) among the members who aren't in
+// excludeMembers, starting from 1, or 0 if they're excluded themselves.
+func completionRank(leaderboard *leaderboardData, member *memberData, dayIdx, partNum int) int {
+	if excludedMembers()[member.ID] {
+		return 0
+	}
+	return getCompletionRank(rankedLeaderboard(leaderboard), member, dayIdx, partNum) + 1
+}
+
+// finisherRanks is where each of the finishers of a day's part placed among the members who aren't in excludeMembers,
+// starting from 1, or 0 for those who are.
+func finisherRanks(finishers []dayFinisher) []int {
+	excluded := excludedMembers()
+	ranks := make([]int, len(finishers))
+	rank := 0
+	for idx, f := range finishers {
+		if !excluded[f.Member.ID] {
+			rank++
+			ranks[idx] = rank
+		}
+	}
+	return ranks
+}
+
+// unrankedSection lists the excluded members who have stars below a digest's standings, so that leaving them out of
+// the ranks doesn't look like they've left the leaderboard.
+func unrankedSection(leaderboard *leaderboardData) string {
+	var names []string
+	for _, member := range unrankedMembers(leaderboard) {
+		if member.Stars > 0 {
+			names = append(names, fmt.Sprintf("%s (%d stars)", displayName(member), member.Stars))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nNot ranked: %s\n", strings.Join(names, ", "))
+}
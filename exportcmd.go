@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+type exportedDay struct {
+	Day   int        `json:"day"`
+	Part1 *time.Time `json:"part1,omitempty"`
+	Part2 *time.Time `json:"part2,omitempty"`
+}
+
+type exportedMember struct {
+	// Rank is the member's place in the standings, or 0 if they're in excludeMembers.
+	Rank        int    `json:"rank"`
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Stars       int    `json:"stars"`
+	LocalScore  int    `json:"local_score"`
+	GlobalScore int    `json:"global_score"`
+	// CustomScore is the member's score under scoringTable, when there is one.
+	CustomScore *int          `json:"custom_score,omitempty"`
+	LastStar    *time.Time    `json:"last_star,omitempty"`
+	Days        []exportedDay `json:"days"`
+}
+
+func runExportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	format := fs.String("format", "csv", "output format: csv, json, or md")
+	out := fs.String("o", "", "file to write to instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var write func(io.Writer, *leaderboardData, leaderboardSettings) error
+	switch *format {
+	case "csv":
+		write = exportCSV
+	case "json":
+		write = exportJSON
+	case "md", "markdown":
+		write = exportMarkdown
+	default:
+		return fmt.Errorf("unknown export format %q; expected csv, json, or md", *format)
+	}
+
+	leaderboard, board, loadErr := loadLeaderboard(*cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if len(*out) == 0 {
+		return write(os.Stdout, leaderboard, board)
+	}
+
+	f, createErr := os.Create(*out)
+	if createErr != nil {
+		return fmt.Errorf("error creating %s: %w", *out, createErr)
+	}
+	if err := write(f, leaderboard, board); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// exportedDays is how many days of completion times are exported: up to the last day anyone has a star for.
+func exportedDays(leaderboard *leaderboardData) int {
+	days := 0
+	for _, member := range leaderboard.Members {
+		for dayIdx, day := range member.CompletionDayLevel {
+			if day.Part1 != nil && dayIdx+1 > days {
+				days = dayIdx + 1
+			}
+		}
+	}
+
+	return days
+}
+
+func exportMembers(leaderboard *leaderboardData, board leaderboardSettings) []exportedMember {
+	at := func(ts int64) *time.Time {
+		t := time.Unix(ts, 0).In(board.Location)
+		return &t
+	}
+
+	days := exportedDays(leaderboard)
+	custom := customScores(leaderboard, *yearArg)
+	localScores := map[int]int{}
+	for _, member := range leaderboard.Members {
+		localScores[member.ID] = member.LocalScore
+	}
+	var members []exportedMember
+	// members are ranked under the scoring mode, but local_score stays the site's whatever the mode
+	for _, member := range listedStandings(leaderboard, *yearArg) {
+		exported := exportedMember{
+			Rank:        member.Rank,
+			ID:          member.ID,
+			Name:        displayName(member.memberData),
+			Stars:       member.Stars,
+			LocalScore:  localScores[member.ID],
+			GlobalScore: member.GlobalScore,
+		}
+		if _, scored := custom[member.ID]; scored {
+			score := custom[member.ID]
+			exported.CustomScore = &score
+		}
+		if member.LastStarTimestamp > 0 {
+			exported.LastStar = at(int64(member.LastStarTimestamp))
+		}
+		for dayIdx := 0; dayIdx < days; dayIdx++ {
+			day := exportedDay{Day: dayIdx + 1}
+			if part := member.CompletionDayLevel[dayIdx].Part1; part != nil {
+				day.Part1 = at(part.GotStarAt)
+			}
+			if part := member.CompletionDayLevel[dayIdx].Part2; part != nil {
+				day.Part2 = at(part.GotStarAt)
+			}
+			exported.Days = append(exported.Days, day)
+		}
+		members = append(members, exported)
+	}
+
+	return members
+}
+
+func exportJSON(w io.Writer, leaderboard *leaderboardData, board leaderboardSettings) error {
+	data, _ := json.MarshalIndent(exportMembers(leaderboard, board), "", "  ")
+	_, err := fmt.Fprintln(w, string(data))
+	return err
+}
+
+func exportCSV(w io.Writer, leaderboard *leaderboardData, board leaderboardSettings) error {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{"rank", "id", "name", "stars", "local_score", "global_score", "last_star"}
+	if customScoring != nil {
+		header = append(header, "custom_score")
+	}
+	for day := 1; day <= exportedDays(leaderboard); day++ {
+		header = append(header, fmt.Sprintf("day%d_part1", day), fmt.Sprintf("day%d_part2", day))
+	}
+	cw.Write(header)
+
+	for _, member := range exportMembers(leaderboard, board) {
+		record := []string{
+			strconv.Itoa(member.Rank),
+			strconv.Itoa(member.ID),
+			member.Name,
+			strconv.Itoa(member.Stars),
+			strconv.Itoa(member.LocalScore),
+			strconv.Itoa(member.GlobalScore),
+			formatTime(member.LastStar),
+		}
+		if member.CustomScore != nil {
+			record = append(record, strconv.Itoa(*member.CustomScore))
+		}
+		for _, day := range member.Days {
+			record = append(record, formatTime(day.Part1), formatTime(day.Part2))
+		}
+		cw.Write(record)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportMarkdown writes a table with one column per day, showing how long after the puzzle unlocked each star was
+// earned, since full timestamps make the table far too wide to read.
+func exportMarkdown(w io.Writer, leaderboard *leaderboardData, board leaderboardSettings) error {
+	elapsed := func(t *time.Time, day int) string {
+		if t == nil {
+			return ""
+		}
+		return formatElapsed(t.Sub(dayUnlock(*yearArg, day)))
+	}
+
+	days := exportedDays(leaderboard)
+	customHeader, customDivider := customColumn(customScores(leaderboard, *yearArg))
+	var sb strings.Builder
+	sb.WriteString("| Rank | Name | Stars | Score |" + customHeader)
+	for day := 1; day <= days; day++ {
+		fmt.Fprintf(&sb, " Day %d |", day)
+	}
+	sb.WriteString("\n| ---: | ---- | ----: | ----: |" + customDivider)
+	sb.WriteString(strings.Repeat(" ---- |", days))
+	sb.WriteString("\n")
+
+	for _, member := range exportMembers(leaderboard, board) {
+		fmt.Fprintf(&sb, "| %s | %s | %d | %d |", rankText(member.Rank), member.Name, member.Stars, member.LocalScore)
+		if member.CustomScore != nil {
+			fmt.Fprintf(&sb, " %d |", *member.CustomScore)
+		}
+		for _, day := range member.Days {
+			cell := elapsed(day.Part1, day.Day)
+			if day.Part2 != nil {
+				cell += " / " + elapsed(day.Part2, day.Day)
+			}
+			fmt.Fprintf(&sb, " %s |", cell)
+		}
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// formatElapsed formats a duration as h:mm:ss, which is how the site shows completion times.
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
@@ -0,0 +1,19 @@
+package main
+
+import "fmt"
+
+// The heaviest optional pieces can be left out of the binary with build tags, for small deployments that don't need
+// them, e.g. `go build -tags nosql,nocloud,noha,nogrpc,nocharts`:
+//
+//   - nosql leaves out the sqlite and postgres stores
+//   - nocloud leaves out the s3 and gs stores
+//   - noha leaves out the etcd and consul stores
+//   - nogrpc leaves out serve's gRPC service
+//   - nocharts leaves out the chart command and the charts on member pages
+//
+// Trying to use something that was left out fails with errLeftOut.
+
+// errLeftOut is the error for using something that the binary was built without.
+func errLeftOut(what, tag string) error {
+	return fmt.Errorf("%s isn't available, since this binary was built with the %s tag", what, tag)
+}
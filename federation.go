@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+var (
+	federateURLArg      = flag.String("federateURL", "", "another scanner's /federation endpoint to send every scan of this leaderboard to, for standings combined across boards")
+	federationSecretArg = flag.String("federationSecret", "", "shared secret that federated scanners sign and verify their payloads with")
+	federationBoardsArg = flag.String("federationBoards", "", "comma-separated IDs of other leaderboards whose federated scans the web server accepts and combines with this one in digests")
+)
+
+// federationPayload is what one scanner sends another after each scan: the leaderboard exactly as it was downloaded.
+type federationPayload struct {
+	Year        string          `json:"year"`
+	Leaderboard string          `json:"leaderboard"`
+	FetchedAt   int64           `json:"fetched_at"`
+	Body        json.RawMessage `json:"body"`
+}
+
+// federatedPartition is where a federated board's latest scan is kept, apart from any board this scanner scans itself.
+func federatedPartition(year, board string) statePartition {
+	return statePartition{Year: year, Leaderboard: "federated-" + board}
+}
+
+// federatedBoards returns the IDs in federationBoards.
+func federatedBoards() []string {
+	var boards []string
+	for _, board := range strings.Split(*federationBoardsArg, ",") {
+		if board = strings.TrimSpace(board); len(board) > 0 {
+			boards = append(boards, board)
+		}
+	}
+	return boards
+}
+
+func signFederation(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(*federationSecretArg))
+	fmt.Fprintf(mac, "%s.%s", timestamp, body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendFederation posts a scan of the leaderboard to federateURL.
+func sendFederation(partition statePartition, state scanState) error {
+	if len(*federationSecretArg) == 0 {
+		return errors.New("federateURL needs a federationSecret to sign with")
+	}
+
+	body, _ := json.Marshal(federationPayload{
+		Year:        partition.Year,
+		Leaderboard: partition.Leaderboard,
+		FetchedAt:   state.LastRead,
+		Body:        state.LastBody,
+	})
+	req, reqErr := http.NewRequest(http.MethodPost, *federateURLArg, bytes.NewReader(body))
+	if reqErr != nil {
+		return fmt.Errorf("invalid federateURL: %w", reqErr)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-AOC-Timestamp", timestamp)
+	req.Header.Set("X-AOC-Signature", signFederation(timestamp, body))
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending scan to federation: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from federation", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// handleFederation accepts scans sent by other scanners with sendFederation, keeping the latest from each board in
+// federationBoards.
+func (s *server) handleFederation(w http.ResponseWriter, r *http.Request) {
+	if len(*federationSecretArg) == 0 || len(*federationBoardsArg) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	timestamp := r.Header.Get("X-AOC-Timestamp")
+	body, ok := readSignedBody(w, r, timestamp)
+	if !ok {
+		return
+	}
+	if !hmac.Equal([]byte(signFederation(timestamp, body)), []byte(r.Header.Get("X-AOC-Signature"))) {
+		writeAPIError(w, http.StatusUnauthorized, "invalid signature")
+		return
+	}
+
+	var payload federationPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if payload.Year != s.partition.Year {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("this scanner is combining %s, not %s", s.partition.Year, payload.Year))
+		return
+	}
+	if !arrayContains(federatedBoards(), func(b string) bool { return b == payload.Leaderboard }) {
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("leaderboard %s isn't in federationBoards", payload.Leaderboard))
+		return
+	}
+	if _, err := buildLeaderboard(payload.Body); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid leaderboard body")
+		return
+	}
+
+	partition := federatedPartition(payload.Year, payload.Leaderboard)
+	state, loadErr := s.store.Load(partition)
+	if loadErr != nil {
+		logError("Error loading federated leaderboard", payload.Leaderboard, ":", loadErr)
+		writeAPIError(w, http.StatusInternalServerError, "error loading federated leaderboard")
+		return
+	}
+	// scans can arrive out of order after a retry, and an older one shouldn't replace a newer one
+	if payload.FetchedAt > state.LastRead {
+		if err := s.store.Save(partition, scanState{LastRead: payload.FetchedAt, LastBody: payload.Body}); err != nil {
+			logError("Error saving federated leaderboard", payload.Leaderboard, ":", err)
+			writeAPIError(w, http.StatusInternalServerError, "error saving federated leaderboard")
+			return
+		}
+		logDebug("Stored federated scan of leaderboard", payload.Leaderboard, "from", time.Unix(payload.FetchedAt, 0).Format(time.RFC3339))
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Result string `json:"result"`
+	}{"stored"})
+}
+
+// combinedLeaderboard merges the partition's cached leaderboard with the latest scan of every board in
+// federationBoards, returning it along with how many boards went into it. Members on more than one board are only
+// counted once, and everyone's local score is recomputed as though they were all on a single leaderboard.
+func combinedLeaderboard(store stateStore, partition statePartition) (*leaderboardData, int, error) {
+	partitions := []statePartition{partition}
+	for _, board := range federatedBoards() {
+		partitions = append(partitions, federatedPartition(partition.Year, board))
+	}
+
+	combined := &leaderboardData{Event: partition.Year}
+	boards := 0
+	for _, p := range partitions {
+		state, loadErr := store.Load(p)
+		if loadErr != nil {
+			return nil, 0, loadErr
+		}
+		if len(state.LastBody) == 0 {
+			logDebug("No scan of", p, "to combine yet")
+			continue
+		}
+		leaderboard, buildErr := buildLeaderboard(state.LastBody)
+		if buildErr != nil {
+			return nil, 0, fmt.Errorf("error building leaderboard %s: %w", p, buildErr)
+		}
+
+		boards++
+		for _, member := range leaderboard.Members {
+			existing := arrayFind(combined.Members, func(m memberData) bool { return m.ID == member.ID })
+			if existing == nil {
+				combined.Members = append(combined.Members, member)
+			} else if member.Stars > existing.Stars {
+				*existing = member
+			}
+		}
+	}
+	if boards == 0 {
+		return nil, 0, errNoData
+	}
+
+	scores := computeLocalScores(rankedLeaderboard(combined), eventDays(partition.Year))
+	for idx := range combined.Members {
+		combined.Members[idx].LocalScore = scores[combined.Members[idx].ID]
+	}
+
+	return combined, boards, nil
+}
+
+// buildCombinedDigest is the standings digest for a leaderboard made by combinedLeaderboard.
+func buildCombinedDigest(leaderboard *leaderboardData, year string, boards int, board leaderboardSettings) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, ":globe_with_meridians: Combined standings across %d leaderboards as of %s:\n\n",
+		boards,
+		time.Now().In(board.Location).Format("Jan 2 3:04pm MST"),
+	)
+	sb.WriteString("| Rank | Name | Stars | Score |\n")
+	sb.WriteString("| ---: | ---- | ----: | ----: |\n")
+	for idx, member := range rankedStandings(leaderboard, year) {
+		fmt.Fprintf(&sb, "| %d | %s | %d | %s |\n", idx+1, displayName(member), member.Stars, formatScore(member.LocalScore))
+	}
+	sb.WriteString(unrankedSection(leaderboard))
+
+	return sb.String()
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// feedSize is how many of the most recent notifications the feeds include.
+const feedSize = 50
+
+// feedTitle turns a notification's markdown into a short plain-text title for feed readers.
+func feedTitle(content string) string {
+	title, _, _ := strings.Cut(content, "\n")
+	title = strings.Trim(plainText(title), "#*_ ")
+	if utf8.RuneCountInString(title) > 120 {
+		title = string([]rune(title)[:119]) + "…"
+	}
+
+	return title
+}
+
+// feedID gives a notification a stable identifier so feed readers can tell which entries they've already seen.
+func feedID(n deliveredNotification) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%d\n%s", n.SentAt.Unix(), n.Content)))
+	return "urn:sha1:" + hex.EncodeToString(sum[:])
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+	GUID        rssGUID `xml:"guid"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// feedNotifications returns what the feeds show: the most recent notifications posted to the webhook, which includes
+// completions, joins, digests, and announcements but not admin alerts.
+func (s *server) feedNotifications(w http.ResponseWriter) ([]deliveredNotification, bool) {
+	lister, ok := s.store.(notificationLister)
+	if !ok {
+		http.Error(w, "the store doesn't keep a record of sent notifications", http.StatusNotFound)
+		return nil, false
+	}
+
+	notifications, listErr := lister.RecentNotifications("webhook", feedSize)
+	if listErr != nil {
+		logError("Error listing notifications for feed:", listErr)
+		http.Error(w, "error listing notifications", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return notifications, true
+}
+
+func (s *server) leaderboardURL() string {
+	return fmt.Sprintf("https://adventofcode.com/%s/leaderboard/private/view/%s", s.partition.Year, s.partition.Leaderboard)
+}
+
+func (s *server) handleRSS(w http.ResponseWriter, r *http.Request) {
+	notifications, ok := s.feedNotifications(w)
+	if !ok {
+		return
+	}
+
+	feed := rssFeed{Version: "2.0", Channel: rssChannel{
+		Title:       fmt.Sprintf("Advent of Code %s leaderboard %s", s.partition.Year, s.partition.Leaderboard),
+		Link:        s.leaderboardURL(),
+		Description: "Everything announced about the leaderboard",
+	}}
+	for _, n := range notifications {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       feedTitle(n.Content),
+			Description: n.Content,
+			PubDate:     n.SentAt.UTC().Format(time.RFC1123Z),
+			GUID:        rssGUID{Value: feedID(n)},
+		})
+	}
+
+	writeXML(w, "application/rss+xml", feed)
+}
+
+func (s *server) handleAtom(w http.ResponseWriter, r *http.Request) {
+	notifications, ok := s.feedNotifications(w)
+	if !ok {
+		return
+	}
+
+	feed := atomFeed{
+		ID:      s.leaderboardURL(),
+		Title:   fmt.Sprintf("Advent of Code %s leaderboard %s", s.partition.Year, s.partition.Leaderboard),
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: s.leaderboardURL()},
+		Author:  atomAuthor{Name: "Advent of Code leaderboard scanner"},
+	}
+	if len(notifications) > 0 {
+		feed.Updated = notifications[0].SentAt.UTC().Format(time.RFC3339)
+	}
+	for _, n := range notifications {
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      feedID(n),
+			Title:   feedTitle(n.Content),
+			Updated: n.SentAt.UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "text", Value: n.Content},
+		})
+	}
+
+	writeXML(w, "application/atom+xml", feed)
+}
+
+func writeXML(w http.ResponseWriter, contentType string, v any) {
+	data, _ := xml.MarshalIndent(v, "", "  ")
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/aocclient"
+)
+
+var digestGlobalArg = flag.Bool("digestGlobal", false, "compare the leaderboard's fastest times on the latest day to the last place on the site's global top 100 in the daily digest, which downloads the day's public leaderboard for it")
+
+// globalLeaderboardSize is how many people the site's global leaderboard lists for each star.
+const globalLeaderboardSize = 100
+
+var (
+	// globalDays caches the global leaderboards of days that have filled up, by year and day, since they can't
+	// change after that.
+	globalDays   = map[string]aocclient.GlobalDay{}
+	globalDaysMu sync.Mutex
+)
+
+// globalComparison is a day's global leaderboard, to compare the leaderboard's times to.
+type globalComparison struct {
+	Day    int
+	Global aocclient.GlobalDay
+}
+
+// globalDay downloads the global leaderboard of a day, or returns the copy from an earlier download once it's full.
+func globalDay(ctx context.Context, year string, day int) (aocclient.GlobalDay, error) {
+	key := fmt.Sprintf("%s/%d", year, day)
+	globalDaysMu.Lock()
+	cached, ok := globalDays[key]
+	globalDaysMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	client := aocclient.Client{HTTPClient: httpClient()}
+	global, err := client.GlobalDay(ctx, year, day)
+	if err != nil {
+		return global, err
+	}
+	if len(global.Part1) >= globalLeaderboardSize && len(global.Part2) >= globalLeaderboardSize {
+		globalDaysMu.Lock()
+		globalDays[key] = global
+		globalDaysMu.Unlock()
+	}
+	return global, nil
+}
+
+// digestGlobalFor is the global leaderboard of the latest day anyone on the leaderboard has started, to compare to in a
+// digest, or nil if digests don't compare to it or it can't be downloaded.
+func digestGlobalFor(ctx context.Context, leaderboard *leaderboardData, year string) *globalComparison {
+	if !*digestGlobalArg {
+		return nil
+	}
+	day := exportedDays(leaderboard)
+	if day == 0 {
+		return nil
+	}
+
+	global, err := globalDay(ctx, year, day)
+	if err != nil {
+		logError("Error downloading the global leaderboard for the digest:", err)
+		return nil
+	}
+	return &globalComparison{Day: day, Global: global}
+}
+
+// globalSection compares the leaderboard's fastest time for each part of the day to the slowest on the global
+// leaderboard, and where it would have placed on it if it was fast enough, or is empty without a comparison.
+func globalSection(leaderboard *leaderboardData, year string, comparison *globalComparison) string {
+	if comparison == nil {
+		return ""
+	}
+
+	unlock := dayUnlock(year, comparison.Day)
+	var lines []string
+	for part, times := range [][]time.Duration{comparison.Global.Part1, comparison.Global.Part2} {
+		finishers := dayFinishers(leaderboard, comparison.Day, part+1)
+		if len(finishers) == 0 || len(times) == 0 {
+			continue
+		}
+
+		best := finishers[0].At.Sub(unlock).Round(time.Second)
+		last := times[len(times)-1]
+		line := fmt.Sprintf("* Part %d: our best was %s; global %s was %s", part+1, best, ordinal(len(times)), last)
+		if best <= last {
+			placed := sort.Search(len(times), func(i int) bool { return times[i] >= best })
+			line += fmt.Sprintf(", so %s would have placed %s", displayName(finishers[0].Member), ordinal(placed+1))
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("\n:earth_americas: Day %d against the global leaderboard:\n%s\n", comparison.Day, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/graphql-go/graphql"
+)
+
+// graphqlLeaderboard is a single copy of a leaderboard that a GraphQL query is looking at: the cached one for a year,
+// or one of its snapshots.
+type graphqlLeaderboard struct {
+	partition statePartition
+	data      *leaderboardData
+	updated   time.Time
+	snapshot  *int64
+}
+
+// graphqlDay is one day of a leaderboard, whose finishers are only worked out if they're asked for.
+type graphqlDay struct {
+	leaderboard *graphqlLeaderboard
+	day         int
+}
+
+// graphqlMember is one member of a leaderboard, along with the year it's for so completions can be timed.
+type graphqlMember struct {
+	exportedMember
+	year string
+}
+
+// graphqlCompletion is one star a member has earned.
+type graphqlCompletion struct {
+	Day     int
+	Part    int
+	At      time.Time
+	Elapsed int64
+}
+
+// graphqlRequest is the body of a GraphQL POST.
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// fieldOf builds a field that resolves from its parent with get.
+func fieldOf[T any](typ graphql.Output, get func(T) any) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return get(p.Source.(T)), nil
+		},
+	}
+}
+
+// limited returns at most the number of items asked for by a field's limit argument.
+func limited[T any](items []T, p graphql.ResolveParams) []T {
+	if limit, ok := p.Args["limit"].(int); ok && limit >= 0 && limit < len(items) {
+		return items[:limit]
+	}
+	return items
+}
+
+var limitArg = graphql.FieldConfigArgument{
+	"limit": &graphql.ArgumentConfig{Type: graphql.Int, Description: "return no more than this many"},
+}
+
+// graphqlSchema builds the schema served at /graphql.
+func (s *server) graphqlSchema() (graphql.Schema, error) {
+	finisherType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Finisher",
+		Description: "A member's completion of one part of a day.",
+		Fields: graphql.Fields{
+			"rank":           fieldOf(graphql.NewNonNull(graphql.Int), func(f apiFinisher) any { return f.Rank }),
+			"id":             fieldOf(graphql.NewNonNull(graphql.Int), func(f apiFinisher) any { return f.ID }),
+			"name":           fieldOf(graphql.NewNonNull(graphql.String), func(f apiFinisher) any { return f.Name }),
+			"at":             fieldOf(graphql.NewNonNull(graphql.DateTime), func(f apiFinisher) any { return f.At }),
+			"elapsedSeconds": fieldOf(graphql.NewNonNull(graphql.Int), func(f apiFinisher) any { return f.Elapsed }),
+		},
+	})
+
+	finishersField := func(part int) *graphql.Field {
+		return &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(finisherType))),
+			Description: fmt.Sprintf("Everyone who has completed part %d, fastest first.", part),
+			Args:        limitArg,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				d := p.Source.(graphqlDay)
+				return limited(s.apiFinishers(d.leaderboard.data, d.leaderboard.partition.Year, d.day, part), p), nil
+			},
+		}
+	}
+
+	dayType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Day",
+		Fields: graphql.Fields{
+			"day": fieldOf(graphql.NewNonNull(graphql.Int), func(d graphqlDay) any { return d.day }),
+			"unlock": fieldOf(graphql.NewNonNull(graphql.DateTime), func(d graphqlDay) any {
+				return dayUnlock(d.leaderboard.partition.Year, d.day).In(s.board.Location)
+			}),
+			"part1": finishersField(1),
+			"part2": finishersField(2),
+		},
+	})
+
+	completionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Completion",
+		Description: "A star a member has earned.",
+		Fields: graphql.Fields{
+			"day":            fieldOf(graphql.NewNonNull(graphql.Int), func(c graphqlCompletion) any { return c.Day }),
+			"part":           fieldOf(graphql.NewNonNull(graphql.Int), func(c graphqlCompletion) any { return c.Part }),
+			"at":             fieldOf(graphql.NewNonNull(graphql.DateTime), func(c graphqlCompletion) any { return c.At }),
+			"elapsedSeconds": fieldOf(graphql.NewNonNull(graphql.Int), func(c graphqlCompletion) any { return c.Elapsed }),
+		},
+	})
+
+	memberType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Member",
+		Fields: graphql.Fields{
+			"id":          fieldOf(graphql.NewNonNull(graphql.Int), func(m graphqlMember) any { return m.ID }),
+			"name":        fieldOf(graphql.NewNonNull(graphql.String), func(m graphqlMember) any { return m.Name }),
+			"rank":        fieldOf(graphql.NewNonNull(graphql.Int), func(m graphqlMember) any { return m.Rank }),
+			"stars":       fieldOf(graphql.NewNonNull(graphql.Int), func(m graphqlMember) any { return m.Stars }),
+			"localScore":  fieldOf(graphql.NewNonNull(graphql.Int), func(m graphqlMember) any { return m.LocalScore }),
+			"globalScore": fieldOf(graphql.NewNonNull(graphql.Int), func(m graphqlMember) any { return m.GlobalScore }),
+			"lastStar": fieldOf(graphql.DateTime, func(m graphqlMember) any {
+				if m.LastStar == nil {
+					return nil
+				}
+				return *m.LastStar
+			}),
+			"completions": fieldOf(graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(completionType))), func(m graphqlMember) any {
+				completions := []graphqlCompletion{}
+				for _, day := range m.Days {
+					for part, at := range []*time.Time{day.Part1, day.Part2} {
+						if at != nil {
+							completions = append(completions, graphqlCompletion{
+								Day:     day.Day,
+								Part:    part + 1,
+								At:      *at,
+								Elapsed: int64(at.Sub(dayUnlock(m.year, day.Day)) / time.Second),
+							})
+						}
+					}
+				}
+				return completions
+			}),
+		},
+	})
+
+	members := func(lb *graphqlLeaderboard) []graphqlMember {
+		out := []graphqlMember{}
+		for _, member := range exportMembers(lb.data, s.board) {
+			out = append(out, graphqlMember{exportedMember: member, year: lb.partition.Year})
+		}
+		return out
+	}
+
+	leaderboardType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Leaderboard",
+		Fields: graphql.Fields{
+			"year":        fieldOf(graphql.NewNonNull(graphql.String), func(lb *graphqlLeaderboard) any { return lb.partition.Year }),
+			"leaderboard": fieldOf(graphql.NewNonNull(graphql.String), func(lb *graphqlLeaderboard) any { return lb.partition.Leaderboard }),
+			"updated":     fieldOf(graphql.NewNonNull(graphql.DateTime), func(lb *graphqlLeaderboard) any { return lb.updated.In(s.board.Location) }),
+			"snapshot": fieldOf(graphql.Int, func(lb *graphqlLeaderboard) any {
+				if lb.snapshot == nil {
+					return nil
+				}
+				return int(*lb.snapshot)
+			}),
+			"members": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(memberType))),
+				Description: "Everyone on the leaderboard in standings order.",
+				Args:        limitArg,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return limited(members(p.Source.(*graphqlLeaderboard)), p), nil
+				},
+			},
+			"member": &graphql.Field{
+				Type: memberType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id := p.Args["id"].(int)
+					if member := arrayFind(members(p.Source.(*graphqlLeaderboard)), func(m graphqlMember) bool { return m.ID == id }); member != nil {
+						return *member, nil
+					}
+					return nil, nil
+				},
+			},
+			"days": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(dayType))),
+				Description: "Every day that anyone has a star on.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					lb := p.Source.(*graphqlLeaderboard)
+					days := []graphqlDay{}
+					for day := 1; day <= exportedDays(lb.data); day++ {
+						days = append(days, graphqlDay{leaderboard: lb, day: day})
+					}
+					return days, nil
+				},
+			},
+			"day": &graphql.Field{
+				Type: dayType,
+				Args: graphql.FieldConfigArgument{
+					"n": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					lb := p.Source.(*graphqlLeaderboard)
+					day := p.Args["n"].(int)
+					if day < 1 || day > eventDays(lb.partition.Year) {
+						return nil, fmt.Errorf("%s doesn't have a day %d", lb.partition.Year, day)
+					}
+					return graphqlDay{leaderboard: lb, day: day}, nil
+				},
+			},
+		},
+	})
+
+	snapshotType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Snapshot",
+		Fields: graphql.Fields{
+			"id":        fieldOf(graphql.NewNonNull(graphql.Int), func(snap graphqlSnapshot) any { return int(snap.ID) }),
+			"fetchedAt": fieldOf(graphql.NewNonNull(graphql.DateTime), func(snap graphqlSnapshot) any { return snap.FetchedAt.In(s.board.Location) }),
+			"leaderboard": &graphql.Field{
+				Type: graphql.NewNonNull(leaderboardType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					snap := p.Source.(graphqlSnapshot)
+					return s.graphqlSnapshotLeaderboard(snap.partition, snap.ID)
+				},
+			},
+		},
+	})
+
+	yearArg := &graphql.ArgumentConfig{Type: graphql.String, Description: "the event year; defaults to the year being served"}
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"leaderboard": &graphql.Field{
+				Type:        leaderboardType,
+				Description: "The cached leaderboard for a year, or one of its snapshots. Null if that year hasn't been scanned.",
+				Args: graphql.FieldConfigArgument{
+					"year":     yearArg,
+					"snapshot": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					partition, partErr := s.graphqlPartition(p)
+					if partErr != nil {
+						return nil, partErr
+					}
+					if id, ok := p.Args["snapshot"].(int); ok {
+						return s.graphqlSnapshotLeaderboard(partition, int64(id))
+					}
+					return s.graphqlCachedLeaderboard(partition)
+				},
+			},
+			"snapshots": &graphql.Field{
+				Type:        graphql.NewList(graphql.NewNonNull(snapshotType)),
+				Description: "Every stored copy of a year's leaderboard, oldest first. Null if the store doesn't keep history.",
+				Args:        graphql.FieldConfigArgument{"year": yearArg},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					partition, partErr := s.graphqlPartition(p)
+					if partErr != nil {
+						return nil, partErr
+					}
+					history := historyFor(s.store)
+					if history == nil {
+						return nil, fmt.Errorf("the store doesn't keep leaderboard history")
+					}
+					snaps, listErr := history.Snapshots(partition)
+					if listErr != nil {
+						return nil, listErr
+					}
+					out := []graphqlSnapshot{}
+					for _, snap := range snaps {
+						out = append(out, graphqlSnapshot{snapshot: snap, partition: partition})
+					}
+					return out, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// graphqlSnapshot is a stored snapshot along with the leaderboard it belongs to.
+type graphqlSnapshot struct {
+	snapshot
+	partition statePartition
+}
+
+// graphqlPartition returns the partition for the year a field asked for, or the year being served.
+func (s *server) graphqlPartition(p graphql.ResolveParams) (statePartition, error) {
+	partition := s.partition
+	if year, ok := p.Args["year"].(string); ok {
+		if y, err := strconv.Atoi(year); err != nil || y < 2015 {
+			return partition, fmt.Errorf("invalid year %q", year)
+		}
+		partition.Year = year
+	}
+	return partition, nil
+}
+
+func (s *server) graphqlCachedLeaderboard(partition statePartition) (*graphqlLeaderboard, error) {
+	state, loadErr := s.store.Load(partition)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	if len(state.LastBody) == 0 {
+		return nil, nil
+	}
+
+	leaderboard, buildErr := buildLeaderboard(state.LastBody)
+	if buildErr != nil {
+		return nil, buildErr
+	}
+	return &graphqlLeaderboard{partition: partition, data: &leaderboard, updated: time.Unix(state.LastRead, 0)}, nil
+}
+
+func (s *server) graphqlSnapshotLeaderboard(partition statePartition, id int64) (*graphqlLeaderboard, error) {
+	history := historyFor(s.store)
+	if history == nil {
+		return nil, fmt.Errorf("the store doesn't keep leaderboard history")
+	}
+
+	// as with the json api, make sure the snapshot belongs to the leaderboard being asked about
+	snaps, listErr := history.Snapshots(partition)
+	if listErr != nil {
+		return nil, listErr
+	}
+	if !arrayContains(snaps, func(snap snapshot) bool { return snap.ID == id }) {
+		return nil, fmt.Errorf("no snapshot %d of the %s leaderboard", id, partition.Year)
+	}
+
+	snap, leaderboard, loadErr := loadSnapshotLeaderboard(history, id)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	return &graphqlLeaderboard{partition: partition, data: leaderboard, updated: snap.FetchedAt, snapshot: &snap.ID}, nil
+}
+
+// graphqlHandler answers GraphQL queries sent as a POSTed json body or as a GET with ?query=.
+func (s *server) graphqlHandler() http.HandlerFunc {
+	schema, schemaErr := s.graphqlSchema()
+	if schemaErr != nil {
+		panic(schemaErr)
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req graphqlRequest
+		switch r.Method {
+		case http.MethodGet:
+			req.Query = r.URL.Query().Get("query")
+			req.OperationName = r.URL.Query().Get("operationName")
+			if vars := r.URL.Query().Get("variables"); len(vars) > 0 {
+				if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+					writeAPIError(w, http.StatusBadRequest, "invalid variables")
+					return
+				}
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+				writeAPIError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "only GET and POST are supported")
+			return
+		}
+		if len(req.Query) == 0 {
+			writeAPIError(w, http.StatusBadRequest, "missing query")
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			OperationName:  req.OperationName,
+			VariableValues: req.Variables,
+			Context:        r.Context(),
+		})
+		writeJSON(w, http.StatusOK, result)
+	}
+}
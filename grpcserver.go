@@ -0,0 +1,231 @@
+//go:build !nogrpc
+
+package main
+
+//go:generate protoc -I proto --go_out=. --go_opt=module=pernicious.games/advent-of-code-scanner --go-grpc_out=. --go-grpc_opt=module=pernicious.games/advent-of-code-scanner proto/scanner.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"pernicious.games/advent-of-code-scanner/scannerpb"
+)
+
+// grpcScanner serves proto/scanner.proto from the same data as the web server.
+type grpcScanner struct {
+	scannerpb.UnimplementedScannerServer
+	s *server
+}
+
+// startGRPCServer serves the gRPC service on addr, over TLS when a certificate is given, sending the error it stops
+// with to serveErr. The returned function shuts it down.
+func startGRPCServer(srv *server, addr, tlsCert, tlsKey string, serveErr chan<- error) (func(), error) {
+	var opts []grpc.ServerOption
+	if len(tlsCert) > 0 {
+		creds, credsErr := credentials.NewServerTLSFromFile(tlsCert, tlsKey)
+		if credsErr != nil {
+			return nil, fmt.Errorf("error loading TLS certificate: %w", credsErr)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	listener, listenErr := net.Listen("tcp", addr)
+	if listenErr != nil {
+		return nil, listenErr
+	}
+
+	grpcServer := newGRPCServer(srv, opts...)
+	go func() {
+		logInfo("Serving gRPC on", addr)
+		serveErr <- grpcServer.Serve(listener)
+	}()
+
+	return func() {
+		// live event streams don't end on their own, so they're cut off if they're all that's left
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			grpcServer.Stop()
+		}
+	}, nil
+}
+
+// newGRPCServer returns a gRPC server for s. Like the web server, it requires readToken when that's set, given as
+// "authorization: Bearer <token>" metadata.
+func newGRPCServer(s *server, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.UnaryInterceptor(grpcUnaryAuth), grpc.StreamInterceptor(grpcStreamAuth))
+	srv := grpc.NewServer(opts...)
+	scannerpb.RegisterScannerServer(srv, &grpcScanner{s: s})
+	return srv
+}
+
+func grpcAuthorize(ctx context.Context) error {
+	if len(*readTokenArg) == 0 {
+		return nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token, _ := strings.CutPrefix(value, "Bearer ")
+		if tokenMatches(token, *readTokenArg) || tokenMatches(token, *adminTokenArg) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "a valid token is required")
+}
+
+func grpcUnaryAuth(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := grpcAuthorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func grpcStreamAuth(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := grpcAuthorize(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// grpcError converts an error from the API's helpers to a gRPC status, the same way apiHandler does for HTTP.
+func grpcError(method string, err error) error {
+	var reqErr apiRequestError
+	switch {
+	case errors.As(err, &reqErr):
+		code := codes.NotFound
+		if reqErr.status == http.StatusBadRequest {
+			code = codes.InvalidArgument
+		}
+		return status.Error(code, reqErr.message)
+	case errors.Is(err, errNoData):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		logError("Error serving gRPC", method, ":", err)
+		return status.Error(codes.Internal, "error loading leaderboard")
+	}
+}
+
+// leaderboard returns the latest leaderboard, or the given snapshot of it if snapshotID isn't 0.
+func (g *grpcScanner) leaderboard(snapshotID int64) (*leaderboardData, time.Time, error) {
+	if snapshotID != 0 {
+		return g.s.snapshotLeaderboard(snapshotID)
+	}
+	return g.s.latestLeaderboard()
+}
+
+func grpcTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func grpcMember(member exportedMember) *scannerpb.Member {
+	out := &scannerpb.Member{
+		Rank:        int32(member.Rank),
+		Id:          int64(member.ID),
+		Name:        member.Name,
+		Stars:       int32(member.Stars),
+		LocalScore:  int32(member.LocalScore),
+		GlobalScore: int32(member.GlobalScore),
+		LastStar:    grpcTimestamp(member.LastStar),
+	}
+	for _, day := range member.Days {
+		out.Days = append(out.Days, &scannerpb.MemberDay{Day: int32(day.Day), Part1: grpcTimestamp(day.Part1), Part2: grpcTimestamp(day.Part2)})
+	}
+	return out
+}
+
+func grpcFinishers(finishers []apiFinisher) []*scannerpb.Finisher {
+	var out []*scannerpb.Finisher
+	for _, f := range finishers {
+		out = append(out, &scannerpb.Finisher{Rank: int32(f.Rank), Id: int64(f.ID), Name: f.Name, At: timestamppb.New(f.At), ElapsedSeconds: f.Elapsed})
+	}
+	return out
+}
+
+func (g *grpcScanner) GetStandings(_ context.Context, req *scannerpb.GetStandingsRequest) (*scannerpb.Standings, error) {
+	leaderboard, updated, loadErr := g.leaderboard(req.GetSnapshotId())
+	if loadErr != nil {
+		return nil, grpcError("GetStandings", loadErr)
+	}
+
+	out := &scannerpb.Standings{Year: g.s.partition.Year, Leaderboard: g.s.partition.Leaderboard, Updated: timestamppb.New(updated)}
+	for _, member := range exportMembers(leaderboard, g.s.board) {
+		out.Members = append(out.Members, grpcMember(member))
+	}
+	return out, nil
+}
+
+func (g *grpcScanner) GetMember(_ context.Context, req *scannerpb.GetMemberRequest) (*scannerpb.Member, error) {
+	leaderboard, _, loadErr := g.leaderboard(req.GetSnapshotId())
+	if loadErr != nil {
+		return nil, grpcError("GetMember", loadErr)
+	}
+
+	member := arrayFind(exportMembers(leaderboard, g.s.board), func(m exportedMember) bool { return int64(m.ID) == req.GetId() })
+	if member == nil {
+		return nil, status.Errorf(codes.NotFound, "member %d isn't on the leaderboard", req.GetId())
+	}
+	return grpcMember(*member), nil
+}
+
+func (g *grpcScanner) GetDay(_ context.Context, req *scannerpb.GetDayRequest) (*scannerpb.Day, error) {
+	day := int(req.GetDay())
+	if day < 1 || day > eventDays(g.s.partition.Year) {
+		return nil, status.Errorf(codes.NotFound, "%s doesn't have a day %d", g.s.partition.Year, day)
+	}
+	leaderboard, _, loadErr := g.leaderboard(req.GetSnapshotId())
+	if loadErr != nil {
+		return nil, grpcError("GetDay", loadErr)
+	}
+
+	out := g.s.apiDay(leaderboard, day)
+	return &scannerpb.Day{Day: int32(day), Unlock: timestamppb.New(out.Unlock), Part1: grpcFinishers(out.Part1), Part2: grpcFinishers(out.Part2)}, nil
+}
+
+// StreamEvents sends live events from the server's event hub until the client goes away or the server stops.
+func (g *grpcScanner) StreamEvents(_ *scannerpb.StreamEventsRequest, stream scannerpb.Scanner_StreamEventsServer) error {
+	events, unsubscribe := g.s.events.subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			err := stream.Send(&scannerpb.Event{
+				Type:     event.Type,
+				At:       timestamppb.New(event.At),
+				MemberId: int64(event.MemberID),
+				Name:     event.Name,
+				Day:      int32(event.Day),
+				Part:     int32(event.Part),
+				Rank:     int32(event.Rank),
+				PrevRank: int32(event.PrevRank),
+				Stars:    int32(event.Stars),
+				Message:  event.Message,
+			})
+			if err != nil {
+				return fmt.Errorf("error sending live event: %w", err)
+			}
+		}
+	}
+}
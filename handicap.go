@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	digestHandicapArg = flag.Bool("digestHandicap", false, "add standings with each member's score adjusted by a handicap from how they did in earlier years to the digests, so newcomers have a shot against veterans")
+	handicapSinceArg  = flag.String("handicapSince", "", "the first event year whose stored leaderboard counts toward members' handicaps; defaults to the year before the one being scanned")
+	handicapMaxArg    = flag.Float64("handicapMax", 0.5, "how much a handicap can add to a score, as a fraction: 0.5 is a newcomer's score counting half again as much as the best veteran's")
+)
+
+// validHandicapMax checks the handicapMax setting.
+func validHandicapMax(fraction float64) error {
+	if fraction < 0 {
+		return fmt.Errorf("invalid handicapMax %v; expected 0 or more", fraction)
+	}
+	return nil
+}
+
+// priorStrengths is how well each member did in the stored leaderboards of earlier years, by member ID: the average,
+// over the years they earned a star in, of their local score as a fraction of that year's best. Members who haven't
+// played an earlier year aren't in it.
+func priorStrengths(store stateStore, partition statePartition) (map[int]float64, error) {
+	since, sinceErr := strconv.Atoi(partition.Year)
+	if sinceErr != nil {
+		return nil, fmt.Errorf("invalid year %q", partition.Year)
+	}
+	since--
+	if len(*handicapSinceArg) > 0 {
+		if since, sinceErr = strconv.Atoi(*handicapSinceArg); sinceErr != nil {
+			return nil, fmt.Errorf("invalid handicapSince %q; expected a year", *handicapSinceArg)
+		}
+	}
+
+	totals, years := map[int]float64{}, map[int]int{}
+	storeErr := eachStoredYear(store, partition, since, func(year string, past *leaderboardData) {
+		past = rankedLeaderboard(past)
+		best := 0
+		for _, member := range past.Members {
+			best = max(best, member.LocalScore)
+		}
+		if best == 0 {
+			return
+		}
+		for _, member := range past.Members {
+			if member.Stars > 0 {
+				totals[member.ID] += float64(member.LocalScore) / float64(best)
+				years[member.ID]++
+			}
+		}
+	})
+	if storeErr != nil {
+		return nil, storeErr
+	}
+
+	strengths := map[int]float64{}
+	for id, total := range totals {
+		strengths[id] = total / float64(years[id])
+	}
+	return strengths, nil
+}
+
+// memberHandicaps is the multiplier each of the leaderboard's members' scores are adjusted by, by member ID: 1 for a
+// member who had the best score every earlier year they played, up to 1+handicapMax for a newcomer, in proportion to
+// how far from the best they were. It's nil if the store has no earlier years of the leaderboard to go by.
+func memberHandicaps(store stateStore, partition statePartition, leaderboard *leaderboardData) (map[int]float64, error) {
+	strengths, strengthErr := priorStrengths(store, partition)
+	if strengthErr != nil {
+		return nil, strengthErr
+	}
+	if len(strengths) == 0 {
+		logDebug("No stored leaderboards from earlier years to base handicaps on")
+		return nil, nil
+	}
+
+	handicaps := map[int]float64{}
+	for _, member := range leaderboard.Members {
+		handicaps[member.ID] = 1 + *handicapMaxArg*(1-strengths[member.ID])
+	}
+	return handicaps, nil
+}
+
+// digestHandicapsFor is the handicaps to put in a digest, or nil if digests don't show them or they can't be computed.
+func digestHandicapsFor(store stateStore, partition statePartition, leaderboard *leaderboardData) map[int]float64 {
+	if !*digestHandicapArg || store == nil {
+		return nil
+	}
+	handicaps, err := memberHandicaps(store, partition, leaderboard)
+	if err != nil {
+		logError("Error working out handicaps for the digest:", err)
+		return nil
+	}
+	return handicaps
+}
+
+// handicapSection is the standings with each member's score under the scoring mode adjusted by their handicap, leaving
+// out anyone without a star, or is empty without handicaps. For modes where lower is better, the score is divided by
+// the handicap rather than multiplied.
+func handicapSection(leaderboard *leaderboardData, year string, handicaps map[int]float64) string {
+	if handicaps == nil {
+		return ""
+	}
+
+	mode := scoringModes[*scoringArg]
+	adjusted := map[int]int{}
+	playing := *leaderboard
+	playing.Members = nil
+	for _, member := range rankedStandings(leaderboard, year) {
+		if member.Stars == 0 {
+			continue
+		}
+		if mode.lowerIsBetter {
+			adjusted[member.ID] = int(float64(member.LocalScore) / handicaps[member.ID])
+		} else {
+			adjusted[member.ID] = int(float64(member.LocalScore) * handicaps[member.ID])
+		}
+		playing.Members = append(playing.Members, member)
+	}
+	if len(playing.Members) == 0 {
+		return ""
+	}
+	members, _ := rankMembers(&playing, adjusted, mode)
+
+	var sb strings.Builder
+	sb.WriteString("\nWith handicaps from earlier years:\n\n| Rank | Name | Handicap | Score |\n| ---: | ---- | -------: | ----: |\n")
+	for idx, member := range members {
+		fmt.Fprintf(&sb, "| %d | %s | ×%.2f | %s |\n", idx+1, displayName(member), handicaps[member.ID], formatScore(adjusted[member.ID]))
+	}
+	return sb.String()
+}
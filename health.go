@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var healthAddrArg = flag.String("healthAddr", "", "address to serve /healthz and /readyz on when daemonized, e.g. :8081")
+
+// readyWindow is how long after the last successful download the scanner still counts as ready: two of the gaps
+// between downloads, which are the fetch interval rounded up to the next scheduled scan.
+func readyWindow(year string, now time.Time) time.Duration {
+	reloadMu.RLock()
+	defer reloadMu.RUnlock()
+
+	interval := fetchInterval(year, now)
+	if scanningPaused(year, now) {
+		// any download is as recent as can be expected
+		return interval
+	}
+	if rem := interval % scanTick; rem != 0 {
+		interval += scanTick - rem
+	}
+
+	return 2 * interval
+}
+
+// handleHealthz reports that the process is up, for liveness probes.
+func (s *server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("ok\n"))
+}
+
+// handleReadyz reports whether the leaderboard has been downloaded successfully recently enough to trust what's
+// being served and announced, and scans haven't kept failing since, for readiness probes.
+func (s *server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	state, loadErr := s.store.Load(s.partition)
+	if loadErr != nil {
+		logError("Error loading state for readiness check:", loadErr)
+		http.Error(w, "error loading state", http.StatusServiceUnavailable)
+		return
+	}
+	if state.LastRead == 0 {
+		http.Error(w, "the leaderboard hasn't been downloaded yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	if escalated(&state) {
+		http.Error(w, fmt.Sprintf("%d scans in a row have failed, most recently with: %s", state.Failures, state.LastFailure), http.StatusServiceUnavailable)
+		return
+	}
+
+	now := time.Now()
+	since := now.Sub(time.Unix(state.LastRead, 0)).Round(time.Second)
+	if window := readyWindow(s.partition.Year, now); since > window {
+		http.Error(w, fmt.Sprintf("the last successful download was %s ago, more than %s", since, window), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintf(w, "ok: last successful download %s ago\n", since)
+}
+
+// serveHealth serves only the health endpoints, for a daemonized scanner that isn't otherwise running the web server.
+func serveHealth(addr string, store stateStore) {
+	s := &server{store: store, partition: statePartition{Year: *yearArg, Leaderboard: primaryLeaderboard()}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+
+	httpServer := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		logInfo("Serving health checks on", addr)
+		if err := httpServer.ListenAndServe(); err != nil {
+			logError("Error serving health checks:", err)
+		}
+	}()
+}
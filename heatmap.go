@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	heatmapByArg     = flag.String("heatmapBy", "time", "what shades the solve-time heatmap's cells: time, for how long after unlock each day was finished, or rank, for where it was finished among the leaderboard")
+	digestHeatmapArg = flag.Bool("digestHeatmap", false, "add a solve-time heatmap of the top members to the daily and final digests")
+)
+
+// digestHeatmapRows is how many of the top-ranked members a digest's heatmap shows.
+const digestHeatmapRows = 10
+
+// heatmapTimeLevels are the times after unlock that each shade of a time heatmap is under. Anything slower gets the
+// last shade.
+var heatmapTimeLevels = []time.Duration{30 * time.Minute, time.Hour, 3 * time.Hour, 24 * time.Hour}
+
+// heatmapShades is how many shades a finished day can have, from best to worst.
+var heatmapShades = len(heatmapTimeLevels) + 1
+
+var (
+	heatmapColors      = []string{"#2da44e", "#d4b106", "#f08c00", "#d1242f", "#8250df"}
+	heatmapEmoji       = []string{"🟩", "🟨", "🟧", "🟥", "🟪"}
+	heatmapLegendTimes = []string{"under 30m", "under 1h", "under 3h", "under a day", "a day or more"}
+	heatmapLegendRanks = []string{"top fifth", "second fifth", "middle fifth", "fourth fifth", "bottom fifth"}
+)
+
+const (
+	heatmapColorPart1 = "#c0c0c0"
+	heatmapEmojiPart1 = "⬜"
+	heatmapColorNone  = "#f0f0f0"
+	heatmapEmojiNone  = "⬛"
+)
+
+// heatmapCell is how one member did on one day.
+type heatmapCell struct {
+	// Level is the cell's shade, from 0 for the best to heatmapShades-1 for the worst, or -1 if the member didn't
+	// finish the day.
+	Level int
+	// Part1Only is whether the member only finished the first part, which isn't shaded since the day isn't done.
+	Part1Only bool
+	// Label is the time or rank the cell was shaded by, or empty for a day the member didn't start.
+	Label string
+}
+
+type heatmapRow struct {
+	Member memberData
+	Cells  []heatmapCell
+}
+
+// heatmap is a grid of every day released so far against each member with a star, in standings order, showing how
+// quickly each member finished each day either by the time it took or by where they placed.
+type heatmap struct {
+	Year string
+	By   string
+	Days int
+	Rows []heatmapRow
+}
+
+// validHeatmapBy checks what a heatmap is shaded by.
+func validHeatmapBy(by string) error {
+	if by != "time" && by != "rank" {
+		return fmt.Errorf("unknown heatmap shading %q; expected time or rank", by)
+	}
+	return nil
+}
+
+// buildHeatmap lays out the leaderboard's heatmap, shaded by time or rank.
+func buildHeatmap(leaderboard *leaderboardData, year, by string) heatmap {
+	h := heatmap{Year: year, By: by, Days: exportedDays(leaderboard)}
+
+	// where each member finished each day, by member ID, and how many finished it
+	ranks := make([]map[int]int, h.Days+1)
+	finished := make([]int, h.Days+1)
+	for day := 1; day <= h.Days; day++ {
+		ranks[day] = map[int]int{}
+		// members in excludeMembers don't take a place, so their cells show their time instead
+		finishers := dayFinishers(rankedLeaderboard(leaderboard), day, 2)
+		for idx, f := range finishers {
+			ranks[day][f.Member.ID] = idx
+		}
+		finished[day] = len(finishers)
+	}
+
+	for _, member := range append(rankedStandings(leaderboard, year), unrankedMembers(leaderboard)...) {
+		if member.Stars == 0 {
+			continue
+		}
+
+		row := heatmapRow{Member: member}
+		for day := 1; day <= h.Days; day++ {
+			cell := heatmapCell{Level: -1}
+			var completion completionDayData
+			if day <= len(member.CompletionDayLevel) {
+				completion = member.CompletionDayLevel[day-1]
+			}
+			unlock := dayUnlock(year, day)
+			rank, placed := ranks[day][member.ID]
+			switch {
+			case completion.Part2 != nil && by == "rank" && placed:
+				cell.Level = rank * heatmapShades / finished[day]
+				cell.Label = fmt.Sprintf("#%d", rank+1)
+			case completion.Part2 != nil:
+				elapsed := time.Unix(completion.Part2.GotStarAt, 0).Sub(unlock)
+				cell.Level = len(heatmapTimeLevels)
+				for level, limit := range heatmapTimeLevels {
+					if elapsed < limit {
+						cell.Level = level
+						break
+					}
+				}
+				cell.Label = formatElapsed(elapsed)
+			case completion.Part1 != nil:
+				cell.Part1Only = true
+				cell.Label = "part 1 in " + formatElapsed(time.Unix(completion.Part1.GotStarAt, 0).Sub(unlock))
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		h.Rows = append(h.Rows, row)
+	}
+
+	return h
+}
+
+// legend describes each of the heatmap's shades in turn.
+func (h heatmap) legend() []string {
+	if h.By == "rank" {
+		return heatmapLegendRanks
+	}
+	return heatmapLegendTimes
+}
+
+func (c heatmapCell) color() string {
+	switch {
+	case c.Part1Only:
+		return heatmapColorPart1
+	case c.Level < 0:
+		return heatmapColorNone
+	}
+	return heatmapColors[c.Level]
+}
+
+func (c heatmapCell) emoji() string {
+	switch {
+	case c.Part1Only:
+		return heatmapEmojiPart1
+	case c.Level < 0:
+		return heatmapEmojiNone
+	}
+	return heatmapEmoji[c.Level]
+}
+
+// title is the cell's tooltip.
+func (c heatmapCell) title(name string, day int) string {
+	if len(c.Label) == 0 {
+		return fmt.Sprintf("%s, day %d: not started", name, day)
+	}
+	return fmt.Sprintf("%s, day %d: %s", name, day, c.Label)
+}
+
+var heatmapTemplate = template.Must(template.New("heatmap").Parse(`<table class="heatmap">
+<tr><th></th>{{range .Days}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr><td><a href="{{.Page}}">{{.Name}}</a></td>{{range .Cells}}<td style="background: {{.Color}}" title="{{.Title}}"></td>{{end}}</tr>
+{{end}}</table>
+<p>{{range .Legend}}<span class="swatch" style="background: {{.Color}}"></span> {{.Label}} {{end}}</p>
+`))
+
+// html renders the heatmap as a table for the dashboard, or returns nothing when nobody has a star yet.
+func (h heatmap) html() template.HTML {
+	if len(h.Rows) == 0 {
+		return ""
+	}
+
+	type cell struct {
+		Color template.CSS
+		Title string
+	}
+	type row struct {
+		Name  string
+		Page  string
+		Cells []cell
+	}
+	type swatch struct {
+		Color template.CSS
+		Label string
+	}
+	data := struct {
+		Days   []int
+		Rows   []row
+		Legend []swatch
+	}{}
+	for day := 1; day <= h.Days; day++ {
+		data.Days = append(data.Days, day)
+	}
+	for _, r := range h.Rows {
+		name := displayName(r.Member)
+		tr := row{Name: name, Page: memberPagePath(r.Member.ID)}
+		for idx, c := range r.Cells {
+			tr.Cells = append(tr.Cells, cell{Color: template.CSS(c.color()), Title: c.title(name, idx+1)})
+		}
+		data.Rows = append(data.Rows, tr)
+	}
+	for level, label := range h.legend() {
+		data.Legend = append(data.Legend, swatch{Color: template.CSS(heatmapColors[level]), Label: label})
+	}
+	data.Legend = append(data.Legend, swatch{Color: template.CSS(heatmapColorPart1), Label: "part 1 only"})
+
+	var buf bytes.Buffer
+	if err := heatmapTemplate.Execute(&buf, data); err != nil {
+		logDebug("Unable to render heatmap:", err)
+		return ""
+	}
+	return template.HTML(buf.String())
+}
+
+const (
+	heatmapCellSize = 16
+	heatmapCellGap  = 2
+)
+
+// svg renders the heatmap as an image, for embedding where the dashboard can't be.
+func (h heatmap) svg() []byte {
+	nameWidth := 0
+	for _, r := range h.Rows {
+		nameWidth = max(nameWidth, badgeTextWidth(displayName(r.Member)))
+	}
+	step := heatmapCellSize + heatmapCellGap
+	top := step + 24
+	legendTop := top + len(h.Rows)*step + 10
+	width := max(nameWidth+max(h.Days, 1)*step+10, 560)
+	height := legendTop + heatmapCellSize + 10
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+"\n", width, height)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="#fff"/>`+"\n", width, height)
+	fmt.Fprintf(&sb, `<text x="5" y="16" font-size="13">Advent of Code %s: each day by %s</text>`+"\n", html.EscapeString(h.Year), h.By)
+	for day := 1; day <= h.Days; day++ {
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle" font-size="9">%d</text>`+"\n", nameWidth+(day-1)*step+heatmapCellSize/2, top-4, day)
+	}
+	for rowIdx, r := range h.Rows {
+		name := displayName(r.Member)
+		y := top + rowIdx*step
+		fmt.Fprintf(&sb, `<text x="5" y="%d">%s</text>`+"\n", y+heatmapCellSize-4, html.EscapeString(name))
+		for idx, c := range r.Cells {
+			fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s</title></rect>`+"\n",
+				nameWidth+idx*step, y, heatmapCellSize, heatmapCellSize, c.color(), html.EscapeString(c.title(name, idx+1)))
+		}
+	}
+
+	x := 5
+	swatch := func(color, label string) {
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"/><text x="%d" y="%d">%s</text>`+"\n",
+			x, legendTop, heatmapCellSize, heatmapCellSize, color, x+heatmapCellSize+4, legendTop+heatmapCellSize-4, label)
+		x += heatmapCellSize + 4 + utf8.RuneCountInString(label)*7 + 10
+	}
+	for level, label := range h.legend() {
+		swatch(heatmapColors[level], label)
+	}
+	swatch(heatmapColorPart1, "part 1 only")
+	sb.WriteString("</svg>\n")
+
+	return []byte(sb.String())
+}
+
+// text renders the heatmap as rows of emoji, which chat apps show as colored squares.
+func (h heatmap) text() string {
+	var sb strings.Builder
+	for _, r := range h.Rows {
+		for _, c := range r.Cells {
+			sb.WriteString(c.emoji())
+		}
+		fmt.Fprintf(&sb, " %s\n", displayName(r.Member))
+	}
+	var legend []string
+	for level, label := range h.legend() {
+		legend = append(legend, heatmapEmoji[level]+" "+label)
+	}
+	legend = append(legend, heatmapEmojiPart1+" part 1 only")
+	sb.WriteString("\n" + strings.Join(legend, " · ") + "\n")
+	return sb.String()
+}
+
+// heatmapSection is the heatmap of the top-ranked members for a digest, or is empty if digests don't show one or
+// nobody has a star yet.
+func heatmapSection(leaderboard *leaderboardData, year string) string {
+	if !*digestHeatmapArg {
+		return ""
+	}
+	h := buildHeatmap(leaderboard, year, *heatmapByArg)
+	if len(h.Rows) == 0 {
+		return ""
+	}
+	if len(h.Rows) > digestHeatmapRows {
+		h.Rows = h.Rows[:digestHeatmapRows]
+	}
+	return fmt.Sprintf("\nEach day by %s:\n\n", h.By) + h.text()
+}
+
+// handleHeatmap serves /heatmap.svg from the cached leaderboard, shaded by heatmapBy unless the by query parameter
+// says otherwise.
+func (s *server) handleHeatmap(w http.ResponseWriter, r *http.Request) {
+	by := *heatmapByArg
+	if q := r.URL.Query().Get("by"); len(q) > 0 {
+		by = q
+	}
+	if err := validHeatmapBy(by); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	leaderboard, _, loadErr := s.cachedLeaderboard()
+	if loadErr != nil {
+		logError("Error loading leaderboard for heatmap:", loadErr)
+		http.Error(w, "error loading leaderboard", http.StatusInternalServerError)
+		return
+	}
+	if leaderboard == nil {
+		http.Error(w, errNoData.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", "max-age=300")
+	w.Write(buildHeatmap(leaderboard, s.partition.Year, by).svg())
+}
+
+func runHeatmapCommand(args []string) error {
+	fs := flag.NewFlagSet("heatmap", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	by := fs.String("by", *heatmapByArg, "what shades the cells: time or rank; defaults to heatmapBy")
+	format := fs.String("format", "svg", "the output format: svg, html, or text")
+	out := fs.String("o", "", "the file to write to; defaults to stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := validHeatmapBy(*by); err != nil {
+		return err
+	}
+
+	leaderboard, _, loadErr := loadLeaderboard(*cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+	h := buildHeatmap(leaderboard, *yearArg, *by)
+	if len(h.Rows) == 0 {
+		return errors.New("nothing to map yet; nobody has a star")
+	}
+
+	var contents []byte
+	switch *format {
+	case "svg":
+		contents = h.svg()
+	case "html":
+		contents = []byte(h.html())
+	case "text":
+		contents = []byte(h.text())
+	default:
+		return fmt.Errorf("unknown heatmap format %q; expected svg, html, or text", *format)
+	}
+
+	if len(*out) == 0 {
+		_, err := os.Stdout.Write(contents)
+		return err
+	}
+	if err := writeFileAtomic(*out, contents, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", *out, err)
+	}
+	fmt.Printf("Wrote the heatmap to %s\n", *out)
+	return nil
+}
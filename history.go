@@ -0,0 +1,31 @@
+package main
+
+import "time"
+
+// deliveredNotification is a message that was successfully sent to a destination.
+type deliveredNotification struct {
+	SentAt      time.Time
+	Destination string
+	Content     string
+}
+
+// notificationLogger is implemented by stores that keep a record of every delivered notification.
+type notificationLogger interface {
+	RecordNotification(n deliveredNotification) error
+}
+
+// notificationLister is implemented by notification logs that can list what they've recorded.
+type notificationLister interface {
+	// RecentNotifications returns up to limit of the notifications most recently delivered to destination, newest first.
+	RecentNotifications(destination string, limit int) ([]deliveredNotification, error)
+}
+
+func (s *scanner) recordNotification(destination, content string) {
+	if s.notificationLog == nil {
+		return
+	}
+
+	if err := s.notificationLog.RecordNotification(deliveredNotification{SentAt: time.Now(), Destination: destination, Content: content}); err != nil {
+		logError("Error recording delivered notification:", err)
+	}
+}
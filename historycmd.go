@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// openConfiguredHistory returns the history for the configured store and partition, or an error if nothing is
+// keeping history.
+func openConfiguredHistory() (historyStore, statePartition, error) {
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return nil, partition, storeErr
+	}
+
+	history := historyFor(store)
+	if history == nil {
+		return nil, partition, errors.New("the configured store doesn't keep leaderboard history; use a store that does or set -archiveDir")
+	}
+
+	return history, partition, nil
+}
+
+// loadSnapshotLeaderboard loads and parses a single snapshot.
+func loadSnapshotLeaderboard(history historyStore, id int64) (snapshot, *leaderboardData, error) {
+	snap, loadErr := history.LoadSnapshot(id)
+	if loadErr != nil {
+		return snap, nil, loadErr
+	}
+
+	leaderboard, buildErr := buildLeaderboard(snap.Body)
+	if buildErr != nil {
+		return snap, nil, fmt.Errorf("error parsing snapshot %d: %w", id, buildErr)
+	}
+
+	return snap, &leaderboard, nil
+}
+
+func runHistoryCommand(args []string) error {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		return listHistory()
+	case args[0] == "show" && len(args) == 2:
+		id, parseErr := strconv.ParseInt(args[1], 10, 64)
+		if parseErr != nil {
+			return fmt.Errorf("invalid snapshot id %q", args[1])
+		}
+		return showHistory(id)
+	}
+
+	return errors.New("usage: history [list] | history show <snapshot id>")
+}
+
+func listHistory() error {
+	history, partition, historyErr := openConfiguredHistory()
+	if historyErr != nil {
+		return historyErr
+	}
+
+	board, boardErr := configuredBoard(partition.Leaderboard)
+	if boardErr != nil {
+		return boardErr
+	}
+
+	snaps, listErr := history.Snapshots(partition)
+	if listErr != nil {
+		return listErr
+	}
+	if len(snaps) == 0 {
+		fmt.Println("No snapshots recorded for", partition)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFetched at\tMembers\tStars")
+	for _, listed := range snaps {
+		_, leaderboard, loadErr := loadSnapshotLeaderboard(history, listed.ID)
+		if loadErr != nil {
+			logWarn("Skipping unreadable snapshot", listed.ID, ":", loadErr)
+			continue
+		}
+
+		stars := 0
+		for _, member := range leaderboard.Members {
+			stars += member.Stars
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\n", listed.ID, listed.FetchedAt.In(board.Location).Format(time.RFC3339), len(leaderboard.Members), stars)
+	}
+	w.Flush()
+
+	return nil
+}
+
+func showHistory(id int64) error {
+	history, partition, historyErr := openConfiguredHistory()
+	if historyErr != nil {
+		return historyErr
+	}
+
+	board, boardErr := configuredBoard(partition.Leaderboard)
+	if boardErr != nil {
+		return boardErr
+	}
+
+	snap, leaderboard, loadErr := loadSnapshotLeaderboard(history, id)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	fmt.Println("Standings as of", snap.FetchedAt.In(board.Location).Format(time.RFC3339)+":")
+	printSummary(leaderboard, board)
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+var hookCommandArg = flag.String("hookCommand", "", "command to run for each join, star, and standings change a scan finds, with the event as json on its standard input")
+
+// hookTimeout is how long a hook command gets to handle an event before it's killed.
+const hookTimeout = 30 * time.Second
+
+// hookQueueSize is how many events can be waiting for the hook command before any more are dropped.
+const hookQueueSize = 256
+
+// hookEnv describes an event with environment variables, for hook commands that would rather not parse json.
+func hookEnv(event liveEvent) []string {
+	return append(os.Environ(),
+		"AOC_EVENT_TYPE="+event.Type,
+		"AOC_EVENT_AT="+event.At.Format(time.RFC3339),
+		"AOC_EVENT_MEMBER_ID="+strconv.Itoa(event.MemberID),
+		"AOC_EVENT_NAME="+event.Name,
+		"AOC_EVENT_DAY="+strconv.Itoa(event.Day),
+		"AOC_EVENT_PART="+strconv.Itoa(event.Part),
+		"AOC_EVENT_RANK="+strconv.Itoa(event.Rank),
+		"AOC_EVENT_PREV_RANK="+strconv.Itoa(event.PrevRank),
+		"AOC_EVENT_STARS="+strconv.Itoa(event.Stars),
+		"AOC_EVENT_MESSAGE="+event.Message,
+	)
+}
+
+// runHook runs the hook command once for an event.
+func runHook(ctx context.Context, command []string, event liveEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, hookTimeout)
+	defer cancel()
+
+	input, _ := json.Marshal(event)
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = hookEnv(event)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(output.String()); len(msg) > 0 {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// hookJob is an event waiting for the hook command that was configured when it was found.
+type hookJob struct {
+	command []string
+	event   liveEvent
+}
+
+// hookQueue runs the hook command for each event it's given, one at a time and in order, away from the scan that found
+// them, so that a slow command never holds up scanning. Hooks are best-effort: unlike notifications, a failed one is
+// logged and not retried, and events that arrive while the queue is full are dropped.
+type hookQueue struct {
+	// mu guards closed, since a scan asked for on demand can still be finishing when the queue is drained
+	mu     sync.Mutex
+	closed bool
+	jobs   chan hookJob
+	done   chan struct{}
+}
+
+// newHookQueue starts running hooks until the queue is drained. Cancelling ctx kills the command that's running, and
+// the hooks still waiting are skipped.
+func newHookQueue(ctx context.Context) *hookQueue {
+	q := &hookQueue{jobs: make(chan hookJob, hookQueueSize), done: make(chan struct{})}
+	go func() {
+		defer close(q.done)
+		for job := range q.jobs {
+			if ctx.Err() != nil {
+				logDebug("Skipping the hook command for a", job.event.Type, "event while shutting down")
+				continue
+			}
+			start := time.Now()
+			if err := runHook(ctx, job.command, job.event); err != nil {
+				logError("Error running hook command for", job.event.Type, "event:", err)
+				continue
+			}
+			logDebugf("Hook command for %s event took %s", job.event.Type, time.Since(start).Round(time.Millisecond))
+		}
+	}()
+	return q
+}
+
+// enqueue queues the hook command to run for each event, if there is one.
+func (q *hookQueue) enqueue(events []liveEvent) {
+	command := strings.Fields(*hookCommandArg)
+	if len(command) == 0 || len(events) == 0 {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		logWarn("Dropping", len(events), "events found after the hook queue was drained")
+		return
+	}
+	for _, event := range events {
+		select {
+		case q.jobs <- hookJob{command: command, event: event}:
+		default:
+			logWarn("Dropping", event.Type, "event because", hookQueueSize, "are already waiting for the hook command")
+		}
+	}
+}
+
+// drain stops taking events and returns a channel that's closed once the hooks for every event already queued have
+// run. Events queued after it's called are dropped.
+func (q *hookQueue) drain() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	return q.done
+}
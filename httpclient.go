@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	httpTimeoutArg = flag.Duration("httpTimeout", 30*time.Second, "the longest any request the scanner makes can take, including reading the response")
+	userAgentArg   = flag.String("userAgent", "", "User-Agent to send with every request; defaults to one naming the scanner and where to find it")
+)
+
+// projectURL is where the scanner's source lives, so that whoever sees its requests knows where they're coming from.
+const projectURL = "https://github.com/parnic/advent-of-code-leaderboard-scanner"
+
+// userAgent identifies the scanner in its requests. The site's maintainer asks that automated tools say what they are
+// and how to reach whoever runs them.
+func userAgent() string {
+	if len(*userAgentArg) > 0 {
+		return *userAgentArg
+	}
+	v, _, _ := buildMetadata()
+	return fmt.Sprintf("advent-of-code-scanner/%s (+%s)", v, projectURL)
+}
+
+// userAgentTransport sets the User-Agent on requests that don't already have one.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(req.Header.Get("User-Agent")) == 0 {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// httpClient returns the client used for every request the scanner makes. It's built the first time it's needed,
+// after the options have been resolved.
+var httpClient = sync.OnceValue(func() *http.Client {
+	return &http.Client{
+		Timeout:   *httpTimeoutArg,
+		Transport: &userAgentTransport{base: http.DefaultTransport, userAgent: userAgent()},
+	}
+})
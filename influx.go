@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	influxURLArg   = flag.String("influxURL", "", "InfluxDB write URL (e.g. http://localhost:8086/api/v2/write?org=me&bucket=aoc) to record every member's stars, score, and rank to after each scan")
+	influxTokenArg = flag.String("influxToken", "", "API token for influxURL")
+)
+
+// influxTagEscaper escapes tag keys and values in InfluxDB line protocol.
+var influxTagEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+// influxLines returns a line protocol point for each member of the leaderboard as of at.
+func influxLines(leaderboard *leaderboardData, partition statePartition, at time.Time) string {
+	ranks := map[int]int{}
+	for idx, member := range rankedStandings(leaderboard, partition.Year) {
+		ranks[member.ID] = idx + 1
+	}
+
+	var sb strings.Builder
+	for _, member := range sortedStandings(leaderboard) {
+		// members in excludeMembers have no rank to record
+		rank := ""
+		if ranks[member.ID] > 0 {
+			rank = fmt.Sprintf(",rank=%di", ranks[member.ID])
+		}
+		fmt.Fprintf(&sb, "aoc_member,year=%s,leaderboard=%s,member_id=%d,member=%s stars=%di,local_score=%di%s %d\n",
+			influxTagEscaper.Replace(partition.Year),
+			influxTagEscaper.Replace(partition.Leaderboard),
+			member.ID,
+			influxTagEscaper.Replace(displayName(member)),
+			member.Stars,
+			member.LocalScore,
+			rank,
+			at.Unix(),
+		)
+	}
+	return sb.String()
+}
+
+// pushInfluxMetrics writes the leaderboard's per-member values to influxURL, timestamped with when it was downloaded,
+// so its history can be graphed at the resolution of every scan rather than of whenever it was scraped.
+func pushInfluxMetrics(leaderboard *leaderboardData, partition statePartition, at time.Time) error {
+	u := *influxURLArg
+	if !strings.Contains(u, "precision=") {
+		sep := "?"
+		if strings.Contains(u, "?") {
+			sep = "&"
+		}
+		u += sep + "precision=s"
+	}
+
+	req, reqErr := http.NewRequest(http.MethodPost, u, strings.NewReader(influxLines(leaderboard, partition, at)))
+	if reqErr != nil {
+		return fmt.Errorf("invalid influxURL: %w", reqErr)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if len(*influxTokenArg) > 0 {
+		req.Header.Set("Authorization", "Token "+*influxTokenArg)
+	}
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("error writing to InfluxDB: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status code %d from InfluxDB: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/joho/godotenv"
+
+	"pernicious.games/advent-of-code-scanner/notify"
+)
+
+// ask prompts for a line of input, returning def when nothing is entered.
+func ask(question, def string) string {
+	if len(def) > 0 {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+
+	answer, _ := stdinReader.ReadString('\n')
+	if answer = strings.TrimSpace(answer); len(answer) == 0 {
+		return def
+	}
+
+	return answer
+}
+
+func runInitCommand(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	format := fs.String("format", "env", "the kind of file to write: env or json")
+	out := fs.String("o", "", "the file to write; defaults to .env or aoc.json depending on -format")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "env" && *format != "json" {
+		return fmt.Errorf("unknown format %q; expected env or json", *format)
+	}
+	if len(*out) == 0 {
+		*out = ".env"
+		if *format == "json" {
+			*out = "aoc.json"
+		}
+	}
+
+	if _, statErr := os.Stat(*out); statErr == nil && !confirm(fmt.Sprintf("%s already exists. Overwrite it?", *out)) {
+		return errors.New("cancelled")
+	}
+
+	year := ask("Event year", *yearArg)
+
+	var session string
+	var boards []privateBoard
+	for {
+		session = ask("Session cookie (the \"session\" cookie from adventofcode.com in your browser)", *sessionArg)
+		if len(session) == 0 {
+			fmt.Println("A session cookie is required to read private leaderboards.")
+			continue
+		}
+
+		account, visible, checkErr := checkSession(year, session)
+		if checkErr == nil {
+			fmt.Println("Logged in as", account)
+			boards = visible
+			break
+		}
+		if errors.Is(checkErr, errSessionRejected) {
+			fmt.Println("adventofcode.com didn't accept that session cookie; it may have expired.")
+			continue
+		}
+		if confirm(fmt.Sprintf("Couldn't check the session (%v). Use it anyway?", checkErr)) {
+			break
+		}
+	}
+
+	defaultBoard := primaryLeaderboard()
+	if len(boards) > 0 {
+		fmt.Println("Private leaderboards this session can view:")
+		for _, board := range boards {
+			fmt.Printf("  %s: %s\n", board.ID, board.Name)
+		}
+		if len(defaultBoard) == 0 {
+			defaultBoard = boards[0].ID
+		}
+	}
+	var leaderboard string
+	for {
+		leaderboard = ask("Leaderboard ID (the number at the end of the leaderboard's URL)", defaultBoard)
+		if len(leaderboard) == 0 {
+			continue
+		}
+		if len(boards) == 0 || arrayContains(boards, func(b privateBoard) bool { return b.ID == leaderboard }) ||
+			confirm(fmt.Sprintf("Leaderboard %s isn't visible to this session. Use it anyway?", leaderboard)) {
+			break
+		}
+	}
+
+	var webhook string
+	for {
+		webhook = ask("Webhook URL to post announcements to", *webhookURLArg)
+		_, u, parseErr := notify.Parse(webhook)
+		if parseErr != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			fmt.Println("That doesn't look like an http(s) URL.")
+			continue
+		}
+
+		if !confirm("Send a test message to it?") {
+			break
+		}
+		testNotifier, notifierErr := notify.New(webhook)
+		if notifierErr == nil {
+			notifierErr = deliver(context.Background(), testNotifier, notify.Event{Content: ":wave: The Advent of Code leaderboard scanner is set up to post here."})
+		}
+		if notifierErr != nil {
+			fmt.Println("Sending the test message failed:", notifierErr)
+			if !confirm("Use this webhook anyway?") {
+				continue
+			}
+		} else {
+			fmt.Println("Test message sent.")
+		}
+		break
+	}
+
+	options := map[string]string{
+		"year":        year,
+		"session":     session,
+		"leaderboard": leaderboard,
+		"webhookURL":  webhook,
+	}
+	if err := writeStarterConfig(*out, *format, options); err != nil {
+		return err
+	}
+
+	fmt.Println("Wrote", *out)
+	if *format == "json" {
+		fmt.Printf("Run the scanner with -config=%s\n", *out)
+	}
+	return nil
+}
+
+// writeStarterConfig writes options (keyed by option name) as either a .env file or a json config file. Both contain
+// secrets, so they're only readable by their owner.
+func writeStarterConfig(path, format string, options map[string]string) error {
+	var data []byte
+	if format == "json" {
+		data, _ = json.MarshalIndent(options, "", "  ")
+		data = append(data, '\n')
+	} else {
+		env := make(map[string]string, len(options))
+		for name, value := range options {
+			env[envVarForOption(name)] = value
+		}
+		marshaled, marshalErr := godotenv.Marshal(env)
+		if marshalErr != nil {
+			return fmt.Errorf("error formatting %s: %w", path, marshalErr)
+		}
+		data = []byte(marshaled + "\n")
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// lambdaRuntimeEnv is set by AWS Lambda to the address of its runtime API when the scanner is running as a function.
+const lambdaRuntimeEnv = "AWS_LAMBDA_RUNTIME_API"
+
+// localStoreSchemes are the stores that keep their state on the machine the scanner runs on, which a function only
+// keeps between invocations by chance unless the path is on a mounted file system.
+var localStoreSchemes = []string{"file", "bolt", "sqlite"}
+
+// lambdaEvent is what an invocation asks for. An empty event, which is what a schedule sends by default, scans every
+// configured leaderboard.
+type lambdaEvent struct {
+	// Digest posts a digest of this kind (daily, weekly, final, or combined) instead of scanning.
+	Digest string `json:"digest"`
+}
+
+// lambdaResult is how an invocation went for one leaderboard.
+type lambdaResult struct {
+	Leaderboard string `json:"leaderboard"`
+	Result      string `json:"result,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// lambdaRuntime talks to AWS Lambda's runtime API, which hands out invocations one at a time and takes their results.
+type lambdaRuntime struct {
+	base string
+	// client has no timeout, since asking for the next invocation waits for as long as it takes for one to come.
+	client *http.Client
+}
+
+// lambdaInvocation is one request to the function.
+type lambdaInvocation struct {
+	id       string
+	deadline time.Time
+	payload  []byte
+}
+
+func (rt *lambdaRuntime) next() (lambdaInvocation, error) {
+	resp, err := rt.client.Get(rt.base + "/invocation/next")
+	if err != nil {
+		return lambdaInvocation{}, fmt.Errorf("error asking for the next invocation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	payload, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		return lambdaInvocation{}, fmt.Errorf("error reading the next invocation: %w", readErr)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return lambdaInvocation{}, fmt.Errorf("unexpected status code %d asking for the next invocation", resp.StatusCode)
+	}
+
+	inv := lambdaInvocation{id: resp.Header.Get("Lambda-Runtime-Aws-Request-Id"), payload: payload}
+	if ms, parseErr := strconv.ParseInt(resp.Header.Get("Lambda-Runtime-Deadline-Ms"), 10, 64); parseErr == nil {
+		inv.deadline = time.UnixMilli(ms)
+	}
+	return inv, nil
+}
+
+// post sends a result or an error to the runtime API.
+func (rt *lambdaRuntime) post(path string, body any) error {
+	data, marshalErr := json.Marshal(body)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	resp, err := rt.client.Post(rt.base+path, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error reporting to the Lambda runtime: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("unexpected status code %d reporting to the Lambda runtime", resp.StatusCode)
+	}
+	return nil
+}
+
+// lambdaError is how the runtime API expects to be told about a failure.
+func lambdaError(err error) map[string]string {
+	return map[string]string{"errorMessage": err.Error(), "errorType": "ScanError"}
+}
+
+func runLambdaCommand(args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: lambda")
+	}
+	api := os.Getenv(lambdaRuntimeEnv)
+	if len(api) == 0 {
+		return fmt.Errorf("%s isn't set; the lambda command only runs as an AWS Lambda function", lambdaRuntimeEnv)
+	}
+	rt := &lambdaRuntime{base: "http://" + api + "/2018-06-01/runtime", client: &http.Client{}}
+
+	store, storeErr := openLambdaStore()
+	if storeErr != nil {
+		// telling the runtime means the failure shows up as the function's error rather than only in its log
+		if reportErr := rt.post("/init/error", lambdaError(storeErr)); reportErr != nil {
+			logError(reportErr)
+		}
+		return storeErr
+	}
+
+	for {
+		inv, nextErr := rt.next()
+		if nextErr != nil {
+			return nextErr
+		}
+
+		results, handleErr := handleLambdaInvocation(store, inv)
+		var reportErr error
+		if handleErr != nil {
+			logError("Lambda invocation", inv.id, "failed:", handleErr)
+			reportErr = rt.post("/invocation/"+inv.id+"/error", lambdaError(handleErr))
+		} else {
+			reportErr = rt.post("/invocation/"+inv.id+"/response", results)
+		}
+		if reportErr != nil {
+			logError(reportErr)
+		}
+	}
+}
+
+// openLambdaStore opens the configured store, refusing one that can't possibly keep state between invocations.
+func openLambdaStore() (stateStore, error) {
+	store, storeErr := openStore(*storeArg)
+	if storeErr != nil {
+		return nil, storeErr
+	}
+	if _, inMemory := store.(*memoryStore); inMemory {
+		return nil, errors.New("the memory store forgets everything between invocations; use an external store such as s3:// or postgres://")
+	}
+	if scheme, _, _ := strings.Cut(*storeArg, ":"); slices.Contains(localStoreSchemes, scheme) {
+		logWarn("The", scheme, "store keeps its state on the function's own disk, which is lost whenever the function starts fresh unless it's on a mounted file system such as EFS.")
+	}
+	return store, nil
+}
+
+// handleLambdaInvocation does what an invocation asks for, once. It fails if any leaderboard does, so that the
+// failure counts against the function; a scan that's too soon after the last one isn't a failure.
+func handleLambdaInvocation(store stateStore, inv lambdaInvocation) ([]lambdaResult, error) {
+	var event lambdaEvent
+	if len(bytes.TrimSpace(inv.payload)) > 0 {
+		if err := json.Unmarshal(inv.payload, &event); err != nil {
+			return nil, fmt.Errorf("error parsing the invocation's event: %w", err)
+		}
+	}
+	if len(event.Digest) > 0 && !slices.Contains([]string{"daily", "weekly", "final", "combined"}, event.Digest) {
+		return nil, fmt.Errorf("unknown digest %q; expected daily, weekly, final, or combined", event.Digest)
+	}
+
+	ctx := context.Background()
+	if !inv.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, inv.deadline)
+		defer cancel()
+	}
+	s, scanners := newBoardScanners(ctx, store, false)
+
+	var results []lambdaResult
+	numFailed := 0
+	record := func(board, result string, err error) {
+		var tooSoon errTooSoon
+		switch {
+		case errors.As(err, &tooSoon):
+			results = append(results, lambdaResult{Leaderboard: board, Result: err.Error()})
+		case err != nil:
+			results = append(results, lambdaResult{Leaderboard: board, Error: err.Error()})
+			numFailed++
+		default:
+			results = append(results, lambdaResult{Leaderboard: board, Result: result})
+		}
+	}
+
+	if len(event.Digest) > 0 {
+		for idx, scanner := range scanners {
+			// there's only one combined digest, which belongs to the first board
+			if event.Digest == "combined" && idx > 0 {
+				continue
+			}
+			result, err := scanner.controls.digest(event.Digest)
+			record(scanner.board.ID, result, err)
+		}
+	} else {
+		for _, scan := range scanAll(scanners) {
+			record(scan.board, scan.result, scan.err)
+		}
+		for _, scanner := range scanners {
+			scanner.maintenance()
+		}
+	}
+	// the function is frozen once it returns, so the hooks have to finish first
+	<-s.hooks.drain()
+
+	if numFailed > 0 {
+		return results, fmt.Errorf("%d of %d leaderboards failed: %s", numFailed, len(results), summarizeLambdaErrors(results))
+	}
+	return results, nil
+}
+
+func summarizeLambdaErrors(results []lambdaResult) string {
+	var failures []string
+	for _, result := range results {
+		if len(result.Error) > 0 {
+			failures = append(failures, result.Leaderboard+": "+result.Error)
+		}
+	}
+	return strings.Join(failures, "; ")
+}
@@ -0,0 +1,87 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Decode reads a leaderboard from r the same way Parse does, but a member at a time, so that only one member's json is
+// held in memory at once rather than the whole body, along with everything it would take to parse it.
+func Decode(r io.Reader) (Leaderboard, error) {
+	var leaderboard Leaderboard
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return leaderboard, fmt.Errorf("error decoding leaderboard: %w", err)
+	}
+
+	for dec.More() {
+		key, keyErr := dec.Token()
+		if keyErr != nil {
+			return leaderboard, fmt.Errorf("error decoding leaderboard: %w", keyErr)
+		}
+
+		var err error
+		switch key {
+		case "event":
+			err = dec.Decode(&leaderboard.Event)
+		case "owner_id":
+			err = dec.Decode(&leaderboard.OwnerID)
+		case "members":
+			err = decodeMembers(dec, &leaderboard)
+		default:
+			var skipped json.RawMessage
+			err = dec.Decode(&skipped)
+		}
+		if err != nil {
+			return leaderboard, fmt.Errorf("error decoding leaderboard: %w", err)
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return leaderboard, fmt.Errorf("error decoding leaderboard: %w", err)
+	}
+	return leaderboard, nil
+}
+
+func decodeMembers(dec *json.Decoder, leaderboard *Leaderboard) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	leaderboard.Members = []Member{}
+
+	parser := parsers.Get()
+	defer parsers.Put(parser)
+	// each member's json is read into the same buffer and parsed by the same parser, which both stay the size of the
+	// biggest member
+	var raw json.RawMessage
+	for dec.More() {
+		// the key is the member's id, which is in the member too
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		memberVal, parseErr := parser.ParseBytes(raw)
+		if parseErr != nil {
+			return parseErr
+		}
+		leaderboard.Members = append(leaderboard.Members, parseMember(memberVal))
+	}
+
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token, which must be the given delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v but found %v", delim, token)
+	}
+	return nil
+}
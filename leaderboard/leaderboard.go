@@ -0,0 +1,269 @@
+// Package leaderboard parses Advent of Code private leaderboards and ranks their members the way the site does.
+package leaderboard
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/valyala/fastjson"
+)
+
+// Part is a member's completion of one part of a day.
+type Part struct {
+	GotStarAt int64 `json:"get_star_ts"`
+	StarIndex int64 `json:"star_index"`
+}
+
+// Day is a member's completion of a day. Parts they haven't finished are nil.
+type Day struct {
+	Part1 *Part
+	Part2 *Part
+}
+
+// Member is one member of a leaderboard and their progress.
+type Member struct {
+	Name string `json:"name"`
+	// CompletionDayLevel has an entry for every day of the event, whether or not the member has started it.
+	CompletionDayLevel []Day `json:"-"`
+	ID                 int   `json:"id"`
+	LocalScore         int   `json:"local_score"`
+	GlobalScore        int   `json:"global_score"`
+	Stars              int   `json:"stars"`
+	LastStarTimestamp  int   `json:"last_star_ts"`
+}
+
+// Leaderboard is a private leaderboard for one year's event.
+type Leaderboard struct {
+	Event   string   `json:"event"`
+	Members []Member `json:"-"`
+	OwnerID int      `json:"owner_id"`
+}
+
+// parsers are reused between calls to Parse, since most of the cost of parsing is allocating the values that a parser
+// keeps around for next time. Nothing Parse returns refers to them.
+var parsers fastjson.ParserPool
+
+// Parse reads a leaderboard in the json the site serves it as. Members are in the order the site listed them.
+func Parse(body []byte) (Leaderboard, error) {
+	var leaderboard Leaderboard
+	parser := parsers.Get()
+	defer parsers.Put(parser)
+	jsonObj, parseErr := parser.ParseBytes(body)
+	if parseErr != nil {
+		return leaderboard, fmt.Errorf("error parsing string `%s` into a leaderboard: %w", string(body), parseErr)
+	}
+	if jsonObj.Type() != fastjson.TypeObject {
+		return leaderboard, fmt.Errorf("error parsing string `%s` into a leaderboard: expected a json object", string(body))
+	}
+
+	leaderboard.Event = string(jsonObj.GetStringBytes("event"))
+	leaderboard.OwnerID = jsonObj.GetInt("owner_id")
+
+	members := jsonObj.GetObject("members")
+	if members != nil {
+		leaderboard.Members = make([]Member, 0, members.Len())
+	}
+	members.Visit(func(_ []byte, memberVal *fastjson.Value) {
+		leaderboard.Members = append(leaderboard.Members, parseMember(memberVal))
+	})
+
+	return leaderboard, nil
+}
+
+// parseMember reads one member of a leaderboard from the site's json.
+func parseMember(memberVal *fastjson.Value) Member {
+	member := Member{
+		Name:               string(memberVal.GetStringBytes("name")),
+		CompletionDayLevel: make([]Day, 25),
+		ID:                 memberVal.GetInt("id"),
+		LocalScore:         memberVal.GetInt("local_score"),
+		GlobalScore:        memberVal.GetInt("global_score"),
+		Stars:              memberVal.GetInt("stars"),
+		LastStarTimestamp:  memberVal.GetInt("last_star_ts"),
+	}
+
+	memberVal.GetObject("completion_day_level").Visit(func(completionKey []byte, completionDay *fastjson.Value) {
+		completionDayNum, _ := strconv.Atoi(string(completionKey))
+		if completionDayNum < 1 || completionDayNum > len(member.CompletionDayLevel) {
+			return
+		}
+
+		day := &member.CompletionDayLevel[completionDayNum-1]
+		completionDay.GetObject().Visit(func(completionPartKey []byte, completionPartVal *fastjson.Value) {
+			completionPart := &Part{GotStarAt: completionPartVal.GetInt64("get_star_ts"), StarIndex: completionPartVal.GetInt64("star_index")}
+			if string(completionPartKey) == "1" {
+				day.Part1 = completionPart
+			} else {
+				day.Part2 = completionPart
+			}
+		})
+	})
+
+	return member
+}
+
+// DisplayName is the member's name, or how the site shows anonymous members.
+func DisplayName(member Member) string {
+	if len(member.Name) == 0 {
+		return "(anonymous user #" + strconv.Itoa(member.ID) + ")"
+	}
+
+	return member.Name
+}
+
+// Standings returns the leaderboard's members in the order the site ranks them: by local score, then stars, then who
+// got their last star first.
+func Standings(leaderboard *Leaderboard) []Member {
+	members := make([]Member, len(leaderboard.Members))
+	copy(members, leaderboard.Members)
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].LocalScore != members[j].LocalScore {
+			return members[i].LocalScore > members[j].LocalScore
+		}
+		if members[i].Stars != members[j].Stars {
+			return members[i].Stars > members[j].Stars
+		}
+		return members[i].LastStarTimestamp < members[j].LastStarTimestamp
+	})
+
+	return members
+}
+
+// EventDays is how many puzzles an event has: 25 until 2025, and 12 since.
+func EventDays(year string) int {
+	if y, _ := strconv.Atoi(year); y >= 2025 {
+		return 12
+	}
+
+	return 25
+}
+
+// DayUnlock is when the given day's puzzle became available: midnight US Eastern (UTC-5) on that day in December.
+func DayUnlock(year string, day int) time.Time {
+	y, _ := strconv.Atoi(year)
+	return time.Date(y, time.December, day, 5, 0, 0, 0, time.UTC)
+}
+
+// Finisher is a member's completion of one part of one day.
+type Finisher struct {
+	Member Member
+	At     time.Time
+}
+
+// Finishers returns everyone who has completed the given part of the given day, fastest first.
+func Finishers(leaderboard *Leaderboard, day, part int) []Finisher {
+	var finishers []Finisher
+	for _, member := range leaderboard.Members {
+		completion := member.CompletionDayLevel[day-1].Part1
+		if part != 1 {
+			completion = member.CompletionDayLevel[day-1].Part2
+		}
+		if completion == nil {
+			continue
+		}
+
+		finishers = append(finishers, Finisher{Member: member, At: time.Unix(completion.GotStarAt, 0)})
+	}
+
+	sort.SliceStable(finishers, func(i, j int) bool { return finishers[i].At.Before(finishers[j].At) })
+	return finishers
+}
+
+// LocalScores scores the given number of days the same way the site's local score does, keyed by member ID.
+func LocalScores(leaderboard *Leaderboard, days int) map[int]int {
+	scores := map[int]int{}
+	for _, member := range leaderboard.Members {
+		scores[member.ID] = 0
+	}
+
+	for dayIdx := 0; dayIdx < days; dayIdx++ {
+		for partNum := 1; partNum <= 2; partNum++ {
+			finishers := Finishers(leaderboard, dayIdx+1, partNum)
+			for rank, f := range finishers {
+				scores[f.Member.ID] += len(leaderboard.Members) - rank
+			}
+		}
+	}
+
+	return scores
+}
+
+// TotalStars counts the member's stars, leaving out the second star of skipPart2OfDay (a day index, or -1 to count
+// everything).
+func TotalStars(member *Member, skipPart2OfDay int) int {
+	total := 0
+	for dayIdx, day := range member.CompletionDayLevel {
+		if day.Part1 != nil {
+			total++
+		}
+		if day.Part2 != nil && skipPart2OfDay != dayIdx {
+			total++
+		}
+	}
+
+	return total
+}
+
+// MemberHash summarizes everything the site reports about a member, so that two copies of a member with the same hash
+// can be treated as unchanged without comparing them field by field.
+func MemberHash(member *Member) uint64 {
+	// FNV-1a, a word at a time instead of a byte at a time, which is plenty to tell two scans of a member apart
+	const prime = 1099511628211
+	h := uint64(14695981039346656037)
+	mix := func(v int64) {
+		h = (h ^ uint64(v)) * prime
+	}
+
+	mix(int64(member.ID))
+	mix(int64(member.Stars))
+	mix(int64(member.LocalScore))
+	mix(int64(member.GlobalScore))
+	mix(int64(member.LastStarTimestamp))
+	for idx := 0; idx < len(member.Name); idx++ {
+		mix(int64(member.Name[idx]))
+	}
+	for _, day := range member.CompletionDayLevel {
+		// no star is ever earned before the epoch, so -1 can't be mistaken for a finished part
+		for _, part := range [2]*Part{day.Part1, day.Part2} {
+			if part == nil {
+				mix(-1)
+				continue
+			}
+			mix(part.GotStarAt)
+			mix(part.StarIndex)
+		}
+	}
+	return h
+}
+
+// CompletionRank returns how many other members finished the given part of the given day (an index) before inMember,
+// who must have finished it.
+func CompletionRank(leaderboard *Leaderboard, inMember *Member, dayIdx int, partNum int) int {
+	targetTime := inMember.CompletionDayLevel[dayIdx].Part1.GotStarAt
+	if partNum != 1 {
+		targetTime = inMember.CompletionDayLevel[dayIdx].Part2.GotStarAt
+	}
+
+	numAhead := 0
+	for _, member := range leaderboard.Members {
+		if member.ID == inMember.ID {
+			continue
+		}
+
+		part := member.CompletionDayLevel[dayIdx].Part1
+		if partNum != 1 {
+			part = member.CompletionDayLevel[dayIdx].Part2
+		}
+		if part == nil {
+			continue
+		}
+
+		if part.GotStarAt < targetTime {
+			numAhead++
+		}
+	}
+
+	return numAhead
+}
@@ -0,0 +1,181 @@
+package leaderboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// siteBody is a leaderboard the way the site serves it: an anonymous member, a member who has only started a day, and
+// a member with no stars.
+const siteBody = `{"event":"2023","owner_id":1,"members":{
+"1":{"name":"Ada","id":1,"stars":3,"local_score":11,"global_score":0,"last_star_ts":1701416224,"completion_day_level":{
+	"1":{"1":{"get_star_ts":1701411502,"star_index":1},"2":{"get_star_ts":1701416224,"star_index":12}},
+	"2":{"1":{"get_star_ts":1701495567,"star_index":30}}}},
+"2":{"name":null,"id":2,"stars":1,"local_score":2,"global_score":0,"last_star_ts":1701412000,"completion_day_level":{
+	"1":{"1":{"get_star_ts":1701412000,"star_index":5}}}},
+"3":{"name":"Grace","id":3,"stars":0,"local_score":0,"global_score":0,"last_star_ts":0,"completion_day_level":{}}}}`
+
+// referenceParse decodes a leaderboard with encoding/json, for comparing Parse against. Members are in ID order, since
+// a map doesn't keep the site's.
+func referenceParse(t *testing.T, body []byte) Leaderboard {
+	t.Helper()
+	var site struct {
+		Event   string `json:"event"`
+		OwnerID int    `json:"owner_id"`
+		Members map[string]struct {
+			Member
+			CompletionDayLevel map[string]map[string]Part `json:"completion_day_level"`
+		} `json:"members"`
+	}
+	if err := json.Unmarshal(body, &site); err != nil {
+		t.Fatalf("encoding/json couldn't decode the body: %v", err)
+	}
+
+	leaderboard := Leaderboard{Event: site.Event, OwnerID: site.OwnerID, Members: []Member{}}
+	for _, siteMember := range site.Members {
+		member := siteMember.Member
+		member.CompletionDayLevel = make([]Day, 25)
+		for key, parts := range siteMember.CompletionDayLevel {
+			day, _ := strconv.Atoi(key)
+			if day < 1 || day > 25 {
+				continue
+			}
+			if part, ok := parts["1"]; ok {
+				member.CompletionDayLevel[day-1].Part1 = &part
+			}
+			if part, ok := parts["2"]; ok {
+				member.CompletionDayLevel[day-1].Part2 = &part
+			}
+		}
+		leaderboard.Members = append(leaderboard.Members, member)
+	}
+	sort.Slice(leaderboard.Members, func(i, j int) bool { return leaderboard.Members[i].ID < leaderboard.Members[j].ID })
+	return leaderboard
+}
+
+// fullBody is the largest leaderboard the site serves: 200 members with both stars on all 25 days.
+func fullBody() []byte {
+	var sb strings.Builder
+	sb.WriteString(`{"event":"2023","owner_id":100000,"members":{`)
+	for m := 0; m < 200; m++ {
+		if m > 0 {
+			sb.WriteString(",")
+		}
+		id := 100000 + m
+		fmt.Fprintf(&sb, `"%d":{"name":"Member %d","id":%d,"stars":50,"local_score":%d,"global_score":%d,"last_star_ts":%d,"completion_day_level":{`, id, m, id, 10000-m, m%7, 1703500000+m)
+		for day := 1; day <= 25; day++ {
+			if day > 1 {
+				sb.WriteString(",")
+			}
+			at := 1701406800 + (day-1)*86400 + m*60
+			fmt.Fprintf(&sb, `"%d":{"1":{"get_star_ts":%d,"star_index":%d},"2":{"get_star_ts":%d,"star_index":%d}}`, day, at, m*50+day, at+600, m*50+day+1)
+		}
+		sb.WriteString("}}")
+	}
+	sb.WriteString("}}")
+	return []byte(sb.String())
+}
+
+func TestParseMatchesEncodingJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"site", []byte(siteBody)},
+		{"full", fullBody()},
+		{"no members", []byte(`{"event":"2023","owner_id":1,"members":{}}`)},
+		{"days out of range", []byte(`{"event":"2023","owner_id":1,"members":{"1":{"name":"Ada","id":1,"stars":1,"completion_day_level":{"0":{"1":{"get_star_ts":1}},"26":{"1":{"get_star_ts":2}},"3":{"1":{"get_star_ts":3,"star_index":4}}}}}}`)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Parse(test.body)
+			if err != nil {
+				t.Fatalf("Parse returned an error: %v", err)
+			}
+			sort.Slice(got.Members, func(i, j int) bool { return got.Members[i].ID < got.Members[j].ID })
+			if want := referenceParse(t, test.body); !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse didn't match encoding/json:\ngot  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseKeepsSiteOrder(t *testing.T) {
+	leaderboard, err := Parse([]byte(siteBody))
+	if err != nil {
+		t.Fatalf("Parse returned an error: %v", err)
+	}
+	var ids []int
+	for _, member := range leaderboard.Members {
+		ids = append(ids, member.ID)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got members %v, want %v", ids, want)
+	}
+}
+
+func TestParseRejectsNonObjects(t *testing.T) {
+	for _, body := range []string{``, `[]`, `"leaderboard"`, `{"members":`} {
+		if _, err := Parse([]byte(body)); err == nil {
+			t.Errorf("Parse(%q) returned no error", body)
+		}
+	}
+}
+
+func TestDecodeMatchesParse(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"site", []byte(siteBody)},
+		{"full", fullBody()},
+		{"no members", []byte(`{"event":"2023","owner_id":1,"members":{}}`)},
+		{"days out of range", []byte(`{"event":"2023","owner_id":1,"members":{"1":{"name":"Ada","id":1,"stars":1,"completion_day_level":{"0":{"1":{"get_star_ts":1}},"26":{"1":{"get_star_ts":2}},"3":{"1":{"get_star_ts":3,"star_index":4}}}}}}`)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Decode(bytes.NewReader(test.body))
+			if err != nil {
+				t.Fatalf("Decode returned an error: %v", err)
+			}
+			want, _ := Parse(test.body)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Decode didn't match Parse:\ngot  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeRejectsNonObjects(t *testing.T) {
+	for _, body := range []string{``, `[]`, `"leaderboard"`, `{"members":`} {
+		if _, err := Decode(strings.NewReader(body)); err == nil {
+			t.Errorf("Decode(%q) returned no error", body)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	body := fullBody()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(body); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	body := fullBody()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(bytes.NewReader(body)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
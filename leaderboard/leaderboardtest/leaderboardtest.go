@@ -0,0 +1,78 @@
+// Package leaderboardtest builds leaderboards for tests out of the json the site serves, so they're parsed the same way
+// a downloaded leaderboard is.
+package leaderboardtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"pernicious.games/advent-of-code-scanner/leaderboard"
+)
+
+// Member is a member of a test leaderboard. Days has the unix times of their stars on each day they've started: part
+// 1's and then part 2's, with 0 for a part they haven't finished. A member without a Name is anonymous.
+type Member struct {
+	ID         int
+	Name       string
+	LocalScore int
+	Days       map[int][2]int64
+}
+
+// Body is the json the site would serve for a leaderboard of the given members, listed in the order they're given.
+func Body(event string, members ...Member) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `{"event":%q,"owner_id":1,"members":{`, event)
+	starIndex := 0
+	for i, member := range members {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+
+		name := []byte("null")
+		if len(member.Name) > 0 {
+			name, _ = json.Marshal(member.Name)
+		}
+
+		days := make([]int, 0, len(member.Days))
+		for day := range member.Days {
+			days = append(days, day)
+		}
+		sort.Ints(days)
+
+		var levels []string
+		stars, lastStar := 0, int64(0)
+		for _, day := range days {
+			var parts []string
+			for part, at := range member.Days[day] {
+				if at == 0 {
+					continue
+				}
+				starIndex++
+				parts = append(parts, fmt.Sprintf(`"%d":{"get_star_ts":%d,"star_index":%d}`, part+1, at, starIndex))
+				stars++
+				lastStar = max(lastStar, at)
+			}
+			if len(parts) > 0 {
+				levels = append(levels, fmt.Sprintf(`"%d":{%s}`, day, strings.Join(parts, ",")))
+			}
+		}
+
+		fmt.Fprintf(&sb, `"%d":{"name":%s,"id":%d,"stars":%d,"local_score":%d,"global_score":0,"last_star_ts":%d,"completion_day_level":{%s}}`,
+			member.ID, name, member.ID, stars, member.LocalScore, lastStar, strings.Join(levels, ","))
+	}
+	sb.WriteString("}}")
+	return []byte(sb.String())
+}
+
+// New parses Body for the given members, failing the test if it can't.
+func New(t testing.TB, event string, members ...Member) *leaderboard.Leaderboard {
+	t.Helper()
+	parsed, err := leaderboard.Parse(Body(event, members...))
+	if err != nil {
+		t.Fatalf("error parsing a test leaderboard: %v", err)
+	}
+	return &parsed
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// deliveryLedger records an idempotency key for every delivered notification so that nothing is ever announced
+// twice, even if the cached leaderboard is lost or rolls back.
+type deliveryLedger interface {
+	HasDelivered(key string) (bool, error)
+	MarkDelivered(key string, at time.Time) error
+}
+
+// ledgerLister is implemented by ledgers that can enumerate everything they've recorded, for exporting.
+type ledgerLister interface {
+	Deliveries() (map[string]int64, error)
+}
+
+// ledgerImporter is implemented by ledgers that can record many deliveries more efficiently than one at a time.
+type ledgerImporter interface {
+	ImportDeliveries(deliveries map[string]int64) error
+}
+
+// ledgerFor returns the store's own ledger if it keeps one, otherwise a ledger that lives inside the given state and
+// is persisted with it by calling save.
+func ledgerFor(store stateStore, state *scanState, save func(scanState)) deliveryLedger {
+	if ledger, ok := store.(deliveryLedger); ok {
+		return ledger
+	}
+
+	return &stateLedger{state: state, save: save}
+}
+
+// stateLedger keeps the ledger in scanState for stores that persist state as a single blob.
+type stateLedger struct {
+	state *scanState
+	save  func(scanState)
+}
+
+func (l *stateLedger) HasDelivered(key string) (bool, error) {
+	_, ok := l.state.Delivered[key]
+	return ok, nil
+}
+
+func (l *stateLedger) MarkDelivered(key string, at time.Time) error {
+	if l.state.Delivered == nil {
+		l.state.Delivered = make(map[string]int64)
+	}
+	l.state.Delivered[key] = at.Unix()
+	l.save(*l.state)
+
+	return nil
+}
+
+// ledgerPath returns the file the fileStore keeps its ledger in. It's separate from the cache so that a corrupt cache
+// can't take the ledger down with it.
+func (s *fileStore) ledgerPath() string {
+	return strings.TrimSuffix(s.path, filepath.Ext(s.path)) + ".ledger.json"
+}
+
+// loadLedger returns the ledger, reading it on first use. The caller holds ledgerMu.
+func (s *fileStore) loadLedger() (map[string]int64, error) {
+	if s.ledger != nil {
+		return s.ledger, nil
+	}
+
+	ledger := make(map[string]int64)
+	data, readErr := os.ReadFile(s.ledgerPath())
+	if readErr != nil && !errors.Is(readErr, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading %s: %w", s.ledgerPath(), readErr)
+	}
+	if data, readErr = openBytes(data); readErr != nil {
+		return nil, fmt.Errorf("error reading %s: %w", s.ledgerPath(), readErr)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &ledger); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", s.ledgerPath(), err)
+		}
+	}
+	s.ledger = ledger
+
+	return ledger, nil
+}
+
+func (s *fileStore) HasDelivered(key string) (bool, error) {
+	s.ledgerMu.Lock()
+	defer s.ledgerMu.Unlock()
+
+	ledger, err := s.loadLedger()
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := ledger[key]
+	return ok, nil
+}
+
+func (s *fileStore) MarkDelivered(key string, at time.Time) error {
+	return s.ImportDeliveries(map[string]int64{key: at.Unix()})
+}
+
+func (s *fileStore) ImportDeliveries(deliveries map[string]int64) error {
+	s.ledgerMu.Lock()
+	defer s.ledgerMu.Unlock()
+
+	ledger, err := s.loadLedger()
+	if err != nil {
+		return err
+	}
+
+	for key, at := range deliveries {
+		ledger[key] = at
+	}
+	data, _ := json.Marshal(ledger)
+	if data, err = sealBytes(data); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.ledgerPath(), data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", s.ledgerPath(), err)
+	}
+
+	return nil
+}
+
+func (s *fileStore) Deliveries() (map[string]int64, error) {
+	s.ledgerMu.Lock()
+	defer s.ledgerMu.Unlock()
+
+	ledger, err := s.loadLedger()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make(map[string]int64, len(ledger))
+	for key, at := range ledger {
+		deliveries[key] = at
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"pernicious.games/advent-of-code-scanner/diff"
+	"pernicious.games/advent-of-code-scanner/leaderboard"
+	"pernicious.games/advent-of-code-scanner/store"
+)
+
+// The scanner's names for what it uses from the library packages, which hold everything that's useful to other
+// programs without the scanner's configuration.
+type (
+	leaderboardData    = leaderboard.Leaderboard
+	memberData         = leaderboard.Member
+	completionDayData  = leaderboard.Day
+	completionPartData = leaderboard.Part
+	dayFinisher        = leaderboard.Finisher
+
+	scanState      = store.State
+	statePartition = store.Partition
+	stateStore     = store.Store
+	outboxEntry    = store.OutboxEntry
+	snapshot       = store.Snapshot
+	historyStore   = store.History
+	memoryStore    = store.Memory
+)
+
+var (
+	buildLeaderboard   = leaderboard.Parse
+	decodeLeaderboard  = leaderboard.Decode
+	displayName        = leaderboard.DisplayName
+	sortedStandings    = leaderboard.Standings
+	eventDays          = leaderboard.EventDays
+	dayUnlock          = leaderboard.DayUnlock
+	dayFinishers       = leaderboard.Finishers
+	computeLocalScores = leaderboard.LocalScores
+	getTotalStars      = leaderboard.TotalStars
+	getCompletionRank  = leaderboard.CompletionRank
+
+	joinKey = diff.JoinKey
+	starKey = diff.StarKey
+	ordinal = diff.Ordinal
+)
+
+func newMemoryStore(string) (stateStore, error) {
+	return store.NewMemory(), nil
+}
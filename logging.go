@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var logLevelArg = flag.String("logLevel", "info", "minimum level of log output: debug, info, warn, or error")
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var logLevelNames = map[string]logLevel{
+	"debug": levelDebug,
+	"info":  levelInfo,
+	"warn":  levelWarn,
+	"error": levelError,
+}
+
+var currentLogLevel = levelInfo
+
+func setLogLevel(name string) error {
+	level, ok := logLevelNames[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown log level %q; expected debug, info, warn, or error", name)
+	}
+
+	currentLogLevel = level
+	return nil
+}
+
+// logDebug is for details that help troubleshoot why something did or didn't happen: timings, diffs, skipped events.
+func logDebug(v ...any) {
+	if currentLogLevel <= levelDebug {
+		log.Println(append([]any{"[debug]"}, v...)...)
+	}
+}
+
+func logDebugf(format string, v ...any) {
+	if currentLogLevel <= levelDebug {
+		log.Printf("[debug] "+format, v...)
+	}
+}
+
+// logInfo reports normal progress on stdout.
+func logInfo(v ...any) {
+	if currentLogLevel <= levelInfo {
+		fmt.Println(v...)
+	}
+}
+
+func logWarn(v ...any) {
+	if currentLogLevel <= levelWarn {
+		log.Println(v...)
+	}
+}
+
+func logError(v ...any) {
+	if currentLogLevel <= levelError {
+		log.Println(v...)
+	}
+}
+
+func logErrorf(format string, v ...any) {
+	if currentLogLevel <= levelError {
+		log.Printf(format, v...)
+	}
+}
@@ -1,386 +1,705 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
-	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
-	"github.com/goccy/go-json"
 	"github.com/joho/godotenv"
 	"github.com/robfig/cron/v3"
-	"github.com/valyala/fastjson"
-)
 
-var (
-	yearArg        = flag.String("year", "2023", "the year to scan")
-	leaderboardArg = flag.String("leaderboard", "", "the leaderboard code to check")
-	sessionArg     = flag.String("session", "", "session cookie to use to request the leaderboard")
-	webhookURLArg  = flag.String("webhookURL", "", "webhook to post updates to")
-	daemonizeArg   = flag.Bool("d", false, "daemonizes the application to run and scan every 15 minutes")
+	"pernicious.games/advent-of-code-scanner/aocclient"
+	"pernicious.games/advent-of-code-scanner/notify"
 )
 
 var (
-	webhook    = ""
-	webhookURL *url.URL
-
-	ChicagoTimeZone, _ = time.LoadLocation("America/Chicago")
-	ordinals           = []string{"th", "st", "nd", "rd"}
+	yearArg             = flag.String("year", "2023", "the year to scan")
+	leaderboardArg      = flag.String("leaderboard", "", "the leaderboard code to check; separate several with commas to scan them all, and give one its own timezone and digest time as id@timezone@HH:MM")
+	sessionArg          = flag.String("session", "", "session cookie to use to request the leaderboard; separate multiple with commas to fail over in order")
+	webhookURLArg       = flag.String("webhookURL", "", "webhook to post updates to")
+	adminURLArg         = flag.String("adminWebhookURL", "", "webhook to post operational alerts (such as expired sessions) to")
+	daemonizeArg        = flag.Bool("d", false, "daemonizes the application to run and scan every 15 minutes")
+	timezoneArg         = flag.String("timezone", defaultTimezone, "the timezone used to display completion times for the leaderboard")
+	digestTimeArg       = flag.String("digestTime", "", "time of day (HH:MM, in the leaderboard's timezone) to post a standings digest when daemonized")
+	minFetchIntervalArg = flag.Duration("minFetchInterval", minFetchIntervalFloor, "minimum time between leaderboard downloads; can't be lower than 14m")
+	minStarsArg         = flag.Int("minStars", 0, "don't announce anything about a member until they have at least this many stars")
+	versionArg          = flag.Bool("version", false, "print version information and exit")
 )
 
-type completionPartData struct {
-	GotStarAt int64 `json:"get_star_ts"`
-	StarIndex int64 `json:"star_index"`
-}
+const defaultTimezone = "America/Chicago"
 
-type completionDayData struct {
-	Part1 *completionPartData
-	Part2 *completionPartData
-}
+// minFetchIntervalFloor is the shortest allowed time between leaderboard downloads. The website requests no more than
+// every 15mins, but this gives us a little slop for cron jobs.
+const minFetchIntervalFloor = time.Minute * 14
 
-type memberData struct {
-	Name               string              `json:"name"`
-	CompletionDayLevel []completionDayData `json:"-"`
-	ID                 int                 `json:"id"`
-	LocalScore         int                 `json:"local_score"`
-	GlobalScore        int                 `json:"global_score"`
-	Stars              int                 `json:"stars"`
-	LastStarTimestamp  int                 `json:"last_star_ts"`
-}
+// scanTick is how often the daemon checks whether it's time to download the leaderboard again; see nextScanTick.
+const scanTick = time.Minute * 15
 
-type leaderboardData struct {
-	Event   string       `json:"event"`
-	Members []memberData `json:"-"`
-	OwnerID int          `json:"owner_id"`
+// leaderboardSettings holds the configuration specific to a single leaderboard.
+type leaderboardSettings struct {
+	ID string
+	// Location is the timezone that completion times and digests are presented in.
+	Location *time.Location
+	// DigestTime is the "HH:MM" time of day, in Location, to post a standings digest. Empty disables the digest.
+	DigestTime string
 }
 
 func main() {
+	flag.Usage = printUsage
 	flag.Parse()
 
-	fmt.Println("Started AOC leaderboard scanner.")
+	// global options may also come after the command, so pull them out and parse them along with the rest
+	var commandArgs []string
+	if flag.NArg() > 0 {
+		global, rest := splitGlobalFlags(flag.Args()[1:])
+		commandArgs = append([]string{flag.Arg(0)}, rest...)
+		flag.CommandLine.Parse(global)
+	}
+
+	if *versionArg {
+		printVersion()
+		return
+	}
 
 	dotenvErr := godotenv.Load()
 	if dotenvErr != nil && !errors.Is(dotenvErr, os.ErrNotExist) {
 		log.Fatalln("Error loading .env file:", dotenvErr)
 	}
 
-	session := *sessionArg
-	if len(session) == 0 {
-		session = os.Getenv("AOC_SESSION")
+	if optionsErr := resolveOptions(); optionsErr != nil {
+		log.Fatalln("Error resolving options:", optionsErr)
 	}
-	if len(session) == 0 {
-		log.Fatalln("No session code provided. You must specify your session code as an argument or as an AOC_SESSION environment variable in either .env or defined in your environment to pull leaderboard info.")
+
+	if levelErr := setLogLevel(*logLevelArg); levelErr != nil {
+		log.Fatalln(levelErr)
+	}
+	if tableErr := loadScoringTable(); tableErr != nil {
+		log.Fatalln(tableErr)
 	}
+	if _, ok := scoringModes[*scoringArg]; !ok {
+		log.Fatalf("Unknown scoring mode %q; expected one of %s\n", *scoringArg, strings.Join(scoringModeNames(), ", "))
+	}
+	if heatmapErr := validHeatmapBy(*heatmapByArg); heatmapErr != nil {
+		log.Fatalln(heatmapErr)
+	}
+	if handicapErr := validHandicapMax(*handicapMaxArg); handicapErr != nil {
+		log.Fatalln(handicapErr)
+	}
+	notify.DefaultClient = httpClient()
 
-	leaderboardID := *leaderboardArg
-	if len(leaderboardID) == 0 {
-		leaderboardID = os.Getenv("AOC_LEADERBOARD")
+	// Lambda runs a function's bootstrap without arguments, so that's all it takes to deploy the plain binary as one
+	if len(commandArgs) == 0 && len(os.Getenv(lambdaRuntimeEnv)) > 0 {
+		commandArgs = []string{"lambda"}
 	}
-	if len(leaderboardID) == 0 {
-		log.Fatalln("No leaderboard ID provided.")
+
+	if len(commandArgs) > 0 {
+		if cmdErr := runCommand(commandArgs); cmdErr != nil {
+			log.Fatalln(cmdErr)
+		}
+		return
 	}
 
-	webhook = *webhookURLArg
-	if len(webhook) == 0 {
-		webhook = os.Getenv("AOC_WEBHOOK")
+	store, storeErr := openStore(*storeArg)
+	if storeErr != nil {
+		log.Fatalln(storeErr)
 	}
-	if len(webhook) == 0 {
-		log.Fatalln("No webhook URL provided.")
+
+	ctx, stop := shutdownContext()
+	defer stop()
+	if scheduler, controls := runScanner(ctx, store, *daemonizeArg); scheduler != nil {
+		if len(*healthAddrArg) > 0 {
+			serveHealth(*healthAddrArg, store)
+		}
+		systemdReady(ctx, scheduler)
+		<-ctx.Done()
+		stop()
+		logInfo("Shutting down.")
+		stopScanner(scheduler, controls)
 	}
-	var webhookErr error
-	webhookURL, webhookErr = url.Parse(webhook)
-	if webhookErr != nil {
-		log.Fatalln("Unable to parse given webhook", webhook, "to a URL:", webhookErr)
+}
+
+// runScanner sets up scanning with newBoardScanners, and either scans once and returns a nil scheduler, or starts scanning on a
+// schedule and returns the running scheduler. Either way, it also returns controls for scanning each configured
+// leaderboard on demand, in the order they were configured. Cancelling ctx cuts off any
+// download or delivery in progress, and scans and digests after that do nothing; see stopScanner.
+func runScanner(ctx context.Context, store stateStore, daemonize bool) (*scanScheduler, []*scanControls) {
+	logInfo("Started AOC leaderboard scanner.")
+
+	s, scanners := newBoardScanners(ctx, store, daemonize)
+	var controls []*scanControls
+	for _, scanner := range scanners {
+		controls = append(controls, scanner.controls)
+	}
+
+	if !daemonize {
+		scanAll(scanners)
+		for _, scanner := range scanners {
+			scanner.maintenance()
+		}
+		<-s.hooks.drain()
+		return nil, controls
+	}
+
+	c := cron.New()
+	c.AddFunc("@daily", func() {
+		for _, scanner := range scanners {
+			scanner.maintenance()
+		}
+	})
+
+	for _, scanner := range scanners {
+		if len(scanner.board.DigestTime) > 0 {
+			if _, err := c.AddFunc(scanner.board.digestSchedule(), scanner.digest); err != nil {
+				log.Fatalln("Unable to schedule digest:", err)
+			}
+		}
+	}
+
+	c.Start()
+	if len(*configArg) > 0 {
+		go s.watchConfig(ctx)
+	}
+	return &scanScheduler{scans: startScanTicker(*yearArg, func() { scanAll(scanners) }), cron: c, hooks: s.hooks}, controls
+}
+
+// newBoardScanners validates the scanning options and sets up scanning each configured leaderboard, in the order they
+// were configured, along with the scanner they all send through. Invalid options are fatal.
+func newBoardScanners(ctx context.Context, store stateStore, daemonize bool) (*scanner, []*boardScanner) {
+	session := *sessionArg
+	if len(session) == 0 {
+		log.Fatalln("No session code provided. You must specify your session code as an argument, as an AOC_SESSION environment variable in either .env or defined in your environment, or in a config file to pull leaderboard info.")
+	}
+
+	leaderboardIDs := configuredLeaderboards()
+	if len(leaderboardIDs) == 0 {
+		log.Fatalln("No leaderboard ID provided.")
+	}
+	var boards []leaderboardSettings
+	for _, leaderboardID := range leaderboardIDs {
+		board, boardErr := configuredBoard(leaderboardID)
+		if boardErr != nil {
+			log.Fatalln(boardErr)
+		}
+		boards = append(boards, board)
 	}
 
-	var p fastjson.Parser
-	var lastRead int64
-	var lastBody []byte
+	s, scannerErr := newScanner(store)
+	if scannerErr != nil {
+		log.Fatalln(scannerErr)
+	}
+	s.hooks = newHookQueue(ctx)
+
+	if *minFetchIntervalArg < minFetchIntervalFloor {
+		logWarn("minFetchInterval", *minFetchIntervalArg, "is below the allowed floor; using", minFetchIntervalFloor)
+		*minFetchIntervalArg = minFetchIntervalFloor
+	}
 
-	cache, cacheErr := os.ReadFile(".cache.json")
-	if cacheErr != nil {
-		if !errors.Is(cacheErr, os.ErrNotExist) {
-			log.Println("Error reading cached data, will pull fresh copy:", cacheErr)
+	if _, inMemory := store.(*memoryStore); inMemory {
+		if len(*archiveDirArg) > 0 {
+			log.Fatalln("The memory store never writes files, so it can't be combined with archiveDir.")
 		}
-	} else {
-		cacheObj, parseErr := p.ParseBytes(cache)
-		if parseErr == nil {
-			lastRead = cacheObj.GetInt64("last_read")
-			lastBody = cacheObj.GetStringBytes("last_body")
+		if !daemonize {
+			logWarn("The memory store forgets everything when the scanner exits, so without -d every run is a fresh baseline and nothing will be announced.")
 		}
 	}
 
-	refresh := func() {
-		fmt.Println("Scanning for new leaderboard data...")
+	var scanners []*boardScanner
+	for idx, board := range boards {
+		// there's only one static site and one combined digest, so they're left to the first board
+		scanners = append(scanners, s.newBoardScanner(ctx, store, board, session, idx == 0))
+	}
+	return s, scanners
+}
 
-		// the website requests no more than every 15mins, but this gives us a little slop for cron jobs
-		if time.Since(time.Unix(lastRead, 0)) < time.Minute*14 {
-			log.Println("Too soon since the last request; doing nothing")
-			return
+// scanScheduler is everything the daemon does on a schedule: scans on every scanTick, plus daily housekeeping and
+// digests at their configured times.
+type scanScheduler struct {
+	scans *scanTicker
+	cron  *cron.Cron
+	hooks *hookQueue
+}
+
+// Stop stops scheduling anything new, and returns a channel that's closed once everything already running has
+// finished, including the hooks for what it found.
+func (s *scanScheduler) Stop() <-chan struct{} {
+	done := make(chan struct{})
+	scansDone, cronDone := s.scans.Stop(), s.cron.Stop().Done()
+	go func() {
+		<-scansDone
+		<-cronDone
+		<-s.hooks.drain()
+		close(done)
+	}()
+	return done
+}
+
+// boardScanner does the scheduled work for one leaderboard.
+type boardScanner struct {
+	board       leaderboardSettings
+	controls    *scanControls
+	refresh     func() (string, error)
+	maintenance func()
+	digest      func()
+}
+
+// newBoardScanner sets up the scanning of one leaderboard. The primary board is the one that also publishes the static
+// site and posts the combined digest of federated boards.
+func (s *scanner) newBoardScanner(ctx context.Context, store stateStore, board leaderboardSettings, session string, primary bool) *boardScanner {
+	partition := statePartition{Year: *yearArg, Leaderboard: board.ID}
+	var stateMu sync.Mutex
+
+	saveState := func(state scanState) {
+		if saveErr := store.Save(partition, state); saveErr != nil {
+			logError("Failed to save cached data:", saveErr)
 		}
+	}
 
-		currBody, downloadErr := downloadLeaderboardData(*yearArg, leaderboardID, session)
-		if downloadErr != nil {
-			log.Println("Error downloading leaderboard data:", downloadErr)
-			return
+	loadState := func() scanState {
+		state, loadErr := store.Load(partition)
+		if loadErr != nil {
+			logWarn("Error reading cached data, will pull fresh copy:", loadErr)
 		}
 
-		defer func() { lastBody = currBody }()
+		return state
+	}
 
-		lastRead = time.Now().Unix()
-		jsonBytes, marshalErr := json.Marshal(map[string]any{"last_read": lastRead, "last_body": string(currBody)})
-		if marshalErr != nil {
-			log.Println("Failed to marshal last-read data into json. Data:", string(jsonBytes), "- error:", marshalErr)
-		} else {
-			writeErr := os.WriteFile(".cache.json", jsonBytes, 0644)
-			if writeErr != nil {
-				log.Println("Failed to save cached data:", writeErr)
+	// withReplicaLock runs fn unless another replica sharing the store is in the middle of a scan
+	withReplicaLock := func(fn func() (string, error)) (string, error) {
+		if locker, ok := store.(stateLocker); ok {
+			unlock, locked, lockErr := locker.TryLock(partition)
+			if lockErr != nil {
+				logError("Error coordinating with other replicas; skipping this scan:", lockErr)
+				return "", fmt.Errorf("error coordinating with other replicas: %w", lockErr)
+			}
+			if !locked {
+				logInfo("Another replica is scanning; doing nothing")
+				return "another replica is scanning", nil
 			}
+			defer unlock()
 		}
 
-		if len(lastBody) == 0 {
-			return
+		return fn()
+	}
+
+	// afterScan does everything that should follow a successful download of the leaderboard
+	afterScan := func(state scanState) {
+		if primary && len(*publishDirArg) > 0 {
+			site := &server{store: store, partition: partition, board: board}
+			if _, err := site.publish(*publishDirArg); err != nil {
+				logError("Error publishing static site:", err)
+			}
 		}
 
-		lastLeaderboard, lastLeaderboardErr := buildLeaderboard(lastBody)
-		if lastLeaderboardErr != nil {
-			log.Println("Error building leaderboard from cached body:", lastLeaderboardErr)
-			return
+		if len(*influxURLArg) > 0 {
+			leaderboard, buildErr := buildLeaderboard(state.LastBody)
+			if buildErr != nil {
+				logError("Error building leaderboard for InfluxDB:", buildErr)
+			} else if err := pushInfluxMetrics(&leaderboard, partition, time.Unix(state.LastRead, 0)); err != nil {
+				logError("Error recording metrics:", err)
+			}
 		}
-		leaderboard, leaderboardErr := buildLeaderboard(currBody)
-		if leaderboardErr != nil {
-			log.Println("Error building leaderboard from downloaded body:", leaderboardErr)
-			return
+
+		if len(*federateURLArg) > 0 {
+			if err := sendFederation(partition, state); err != nil {
+				logError("Error sending scan to federation:", err)
+			}
 		}
+	}
 
-		for _, member := range leaderboard.Members {
-			lastMember := arrayFind(lastLeaderboard.Members, func(m memberData) bool { return m.ID == member.ID })
-			if lastMember == nil {
-				// todo: report if they've already got stars on the year
-				nErr := sendNotification(fmt.Sprintf(":tada: A new challenger has appeared! Welcome, %s, to [the leaderboard](https://adventofcode.com/%s/leaderboard/private/view/%s)! :tada:", member.Name, *yearArg, leaderboardID))
-				if nErr != nil {
-					log.Printf("Error sending new-challenger notification to the leaderboard for %s: %v\n", member.Name, nErr)
-				}
+	// scan downloads the leaderboard and announces what's changed, unless it was last downloaded less than interval ago.
+	// A scheduled scan waits longer while scans keep failing.
+	scan := func(interval time.Duration, scheduled bool) (string, error) {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		reloadMu.RLock()
+		defer reloadMu.RUnlock()
+
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		logInfo("Scanning leaderboard", board.ID, "for new data...")
 
-				continue
+		return withReplicaLock(func() (result string, err error) {
+			state := loadState()
+			defer func() { s.trackFailures(ctx, board, &state, err, saveState) }()
+			ledger := ledgerFor(store, &state, saveState)
+			// anything left over from a scan that couldn't deliver it goes out before anything new is detected
+			s.flushOutbox(ctx, &state, ledger, saveState)
+
+			if scheduled {
+				interval = scheduledInterval(&state, interval)
+			}
+			if since := time.Since(time.Unix(state.LastRead, 0)); since < interval {
+				logInfo("Too soon since the last request; doing nothing")
+				logDebugf("last request was %s ago at %s; waiting for %s between requests", since.Round(time.Second), time.Unix(state.LastRead, 0).Format(time.RFC3339), interval)
+				return "", errTooSoon{next: time.Unix(state.LastRead, 0).Add(interval)}
 			}
 
-			for dayIdx, day := range member.CompletionDayLevel {
-				s := func(part *completionPartData, partNum int) {
-					// in case we get two updates at once, this prevents us from saying the same number of total stars for both parts.
-					// it's never possible to have part2 completed before part 1 for a day, so this is all we need to check.
-					skipPart2OfDay := -1
-					if partNum == 1 {
-						skipPart2OfDay = dayIdx
-					}
-					totalStars := getTotalStars(&member, skipPart2OfDay)
-					totalStarsPlural := "s"
-					if totalStars == 1 {
-						totalStarsPlural = ""
-					}
-
-					completionTime := time.Unix(part.GotStarAt, 0).In(ChicagoTimeZone).Format("3:04:05pm")
-					rank := getCompletionRank(&leaderboard, &member, dayIdx, partNum) + 1
-					ordinal := getOrdinal(rank)
-					err := sendNotification(fmt.Sprintf(
-						":tada: %s completed day %d part %d %d%s on [the leaderboard](https://adventofcode.com/%s/leaderboard/private/view/%s) at %s, and now has %d star%s on the year. :tada:",
-						member.Name,
-						dayIdx+1,
-						partNum,
-						rank,
-						ordinal,
-						*yearArg,
-						leaderboardID,
-						completionTime,
-						totalStars,
-						totalStarsPlural,
-					))
-
-					if err != nil {
-						log.Println("Error sending notification for", member, err)
-					}
+			sessions := newSessionPool(session, state.FailedSessions)
+			sessions.alert = s.sendAdminNotification
+			// the validators are only worth sending if there's a copy of what they validate to compare against
+			var since aocclient.Validators
+			if len(state.LastBody) > 0 {
+				since = aocclient.Validators{ETag: state.ETag, LastModified: state.LastModified}
+			}
+			currBody, validators, downloadErr := sessions.download(ctx, *yearArg, board.ID, since)
+			state.FailedSessions = sessions.failedFingerprints()
+			if errors.Is(downloadErr, errNotModified) {
+				logInfo("The leaderboard hasn't changed since the last download")
+				state.LastRead = time.Now().Unix()
+				saveState(state)
+				return "the leaderboard hasn't changed since the last download", nil
+			}
+			if downloadErr != nil {
+				logError("Error downloading leaderboard data:", downloadErr)
+				if errors.Is(downloadErr, errSessionRejected) {
+					// remember which sessions were rejected so we don't keep retrying (and alerting about) them
+					saveState(state)
 				}
+				return "", downloadErr
+			}
 
-				// todo: probably want to batch these for delivery later so we can sort by completion rank/time
-				if day.Part1 != nil && lastMember.CompletionDayLevel[dayIdx].Part1 == nil {
-					s(day.Part1, 1)
-				}
-				if day.Part2 != nil && lastMember.CompletionDayLevel[dayIdx].Part2 == nil {
-					s(day.Part2, 2)
+			lastBody := state.LastBody
+			state.LastRead = time.Now().Unix()
+			state.LastBody = currBody
+			state.ETag, state.LastModified = validators.ETag, validators.LastModified
+
+			if history := historyFor(store); history != nil {
+				snapErr := history.SaveSnapshot(snapshot{FetchedAt: time.Unix(state.LastRead, 0), Year: *yearArg, Leaderboard: board.ID, Body: currBody})
+				if snapErr != nil {
+					logError("Error recording leaderboard history:", snapErr)
 				}
 			}
-		}
-	}
 
-	if !*daemonizeArg {
-		refresh()
-		return
-	}
+			if len(lastBody) == 0 {
+				logDebug("No previous leaderboard data to compare against; this scan becomes the baseline and no notifications are sent")
+				saveState(state)
+				afterScan(state)
+				return "downloaded the first copy of the leaderboard to compare future scans against", nil
+			}
 
-	c := cron.New()
-	c.AddFunc("*/15 * * * *", refresh)
+			events, liveEvents, compareErr := compareScans(lastBody, currBody, time.Unix(state.LastRead, 0), board)
+			if compareErr != nil {
+				logError("Error comparing leaderboards:", compareErr)
+				saveState(state)
+				return "", compareErr
+			}
 
-	c.Start()
-	quit := make(chan os.Signal, 2)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-	<-quit
-	fmt.Println("Shutting down.")
-}
+			// the new body and the events detected in it are saved together, so they're either both kept or both lost
+			state.Enqueue(events)
+			saveState(state)
 
-func getTotalStars(member *memberData, skipPart2OfDay int) int {
-	total := 0
-	for dayIdx, day := range member.CompletionDayLevel {
-		if day.Part1 != nil {
-			total++
+			s.flushOutbox(ctx, &state, ledger, saveState)
+			s.hooks.enqueue(liveEvents)
+			afterScan(state)
+			return fmt.Sprintf("found %d new events; %d notifications still waiting to be delivered", len(events), len(state.Outbox)), nil
+		})
+	}
+
+	refresh := func() (string, error) {
+		reloadMu.RLock()
+		now := time.Now()
+		paused, interval := scanningPaused(*yearArg, now), fetchInterval(*yearArg, now)
+		reloadMu.RUnlock()
+		if paused {
+			logDebug("Not scanning leaderboard", board.ID, "outside of December and January, since offSeasonFetchInterval is 0")
+			return "scanning is paused outside of December and January", nil
 		}
-		if day.Part2 != nil && skipPart2OfDay != dayIdx {
-			total++
+		result, err := scan(interval, true)
+		var tooSoon errTooSoon
+		switch {
+		case errors.As(err, &tooSoon):
+			// most ticks are too soon to download anything, which isn't worth reporting
+		case err != nil:
+			notifySystemd(fmt.Sprintf("STATUS=Leaderboard %s failed to scan at %s: %v", board.ID, time.Now().Format(time.Kitchen), err))
+		default:
+			notifySystemd(fmt.Sprintf("STATUS=Leaderboard %s scanned at %s: %s", board.ID, time.Now().Format(time.Kitchen), result))
 		}
+		return result, err
 	}
 
-	return total
-}
-
-func getCompletionRank(leaderboard *leaderboardData, inMember *memberData, dayIdx int, partNum int) int {
-	targetTime := inMember.CompletionDayLevel[dayIdx].Part1.GotStarAt
-	if partNum != 1 {
-		targetTime = inMember.CompletionDayLevel[dayIdx].Part2.GotStarAt
+	flush := func(ctx context.Context) (string, error) {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		reloadMu.RLock()
+		defer reloadMu.RUnlock()
+
+		return withReplicaLock(func() (string, error) {
+			state := loadState()
+			pending := len(state.Outbox)
+			s.flushOutbox(ctx, &state, ledgerFor(store, &state, saveState), saveState)
+			if len(state.Outbox) > 0 {
+				return "", fmt.Errorf("delivered %d of %d pending notifications; the rest will be retried on the next scan", pending-len(state.Outbox), pending)
+			}
+			return fmt.Sprintf("delivered %d pending notifications", pending), nil
+		})
 	}
 
-	numAhead := 0
-	for _, member := range leaderboard.Members {
-		if member.ID == inMember.ID {
-			continue
+	sendDigest := func(kind string) (string, error) {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		reloadMu.RLock()
+		defer reloadMu.RUnlock()
+
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if kind == "combined" {
+			combined, boards, combineErr := combinedLeaderboard(store, partition)
+			if combineErr != nil {
+				logError("Error combining federated leaderboards for digest:", combineErr)
+				return "", combineErr
+			}
+			if err := s.sendNotification(ctx, buildCombinedDigest(combined, partition.Year, boards, board)); err != nil {
+				logError("Error sending combined digest notification:", err)
+				return "", err
+			}
+			return fmt.Sprintf("sent the combined digest of %d leaderboards", boards), nil
 		}
 
-		part := member.CompletionDayLevel[dayIdx].Part1
-		if partNum != 1 {
-			part = member.CompletionDayLevel[dayIdx].Part2
+		lastBody := loadState().LastBody
+		if len(lastBody) == 0 {
+			logWarn("No leaderboard data available yet; skipping digest")
+			return "", errNoData
 		}
-		if part == nil {
-			continue
+
+		leaderboard, leaderboardErr := buildLeaderboard(lastBody)
+		if leaderboardErr != nil {
+			logError("Error building leaderboard for digest:", leaderboardErr)
+			return "", leaderboardErr
 		}
 
-		if part.GotStarAt < targetTime {
-			numAhead++
+		var digest string
+		switch kind {
+		case "weekly":
+			digest = buildWeeklyDigest(&leaderboard, *yearArg, board, time.Now(), digestHandicapsFor(store, partition, &leaderboard))
+		case "final":
+			digest = buildFinalDigest(&leaderboard, *yearArg, board, digestRatingsFor(store, partition, &leaderboard), digestHandicapsFor(store, partition, &leaderboard))
+		default:
+			digest = buildDigest(&leaderboard, *yearArg, board, digestRatingsFor(store, partition, &leaderboard), digestGlobalFor(ctx, &leaderboard, *yearArg), digestHandicapsFor(store, partition, &leaderboard))
 		}
+
+		if err := s.sendNotification(ctx, digest); err != nil {
+			logError("Error sending digest notification:", err)
+			return "", err
+		}
+		return "sent the " + kind + " digest", nil
 	}
 
-	return numAhead
-}
+	controls := &scanControls{
+		// an explicit scan doesn't wait out the idle interval, but still never downloads more often than allowed
+		scan:   func() (string, error) { return scan(*minFetchIntervalArg, false) },
+		flush:  flush,
+		digest: sendDigest,
+	}
 
-func getOrdinal(n int) string {
-	v := n % 100
-	if v >= 20 && len(ordinals) > (v-20)%10 {
-		return ordinals[(v-20)%10]
+	// maintenance is for housekeeping that doesn't need to happen on every scan
+	maintenance := func() {
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		reloadMu.RLock()
+		defer reloadMu.RUnlock()
+
+		if history := historyFor(store); history != nil {
+			pruned, pruneErr := pruneHistory(history, partition, time.Now())
+			if pruneErr != nil {
+				logError("Error pruning leaderboard history:", pruneErr)
+			} else if pruned > 0 {
+				logInfo("Pruned", pruned, "leaderboard history snapshots")
+			}
+		}
 	}
-	if len(ordinals) > v {
-		return ordinals[v]
+
+	return &boardScanner{
+		board:       board,
+		controls:    controls,
+		refresh:     refresh,
+		maintenance: maintenance,
+		digest: func() {
+			sendDigest("daily")
+			if primary && len(federatedBoards()) > 0 {
+				sendDigest("combined")
+			}
+		},
 	}
-	return ordinals[0]
 }
 
-func downloadLeaderboardData(year, leaderboardID, sessionID string) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://adventofcode.com/%s/leaderboard/private/view/%s.json", year, leaderboardID), nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request for leaderboard: %w", err)
-	}
+// shutdownContext returns a context that's cancelled when the process is asked to stop. Calling stop once it's done
+// lets a second request to stop end the process right away, in case shutting down gets stuck.
+func shutdownContext() (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
 
-	req.AddCookie(&http.Cookie{
-		Name:     "session",
-		Value:    sessionID,
-		Path:     "/",
-		Domain:   ".adventofcode.com",
-		Secure:   true,
-		HttpOnly: true,
-	})
+// shutdownTimeout is how long the scanner gets to wrap up once it's been asked to stop.
+const shutdownTimeout = 10 * time.Second
+
+// stopScanner stops a scheduler from runScanner once its context has been cancelled. It waits for scans that were
+// running to save their state, then makes one last attempt at delivering what's left in each board's outbox, which
+// would otherwise wait until the scanner next starts.
+func stopScanner(scheduler *scanScheduler, controls []*scanControls) {
+	notifySystemd("STOPPING=1")
+	select {
+	case <-scheduler.Stop():
+	case <-time.After(shutdownTimeout):
+		logWarn("Timed out waiting for the running scan to finish")
+		return
+	}
 
-	client := http.DefaultClient
-	resp, reqErr := client.Do(req)
-	if reqErr != nil {
-		return nil, fmt.Errorf("error attempting to download leaderboard: %w", reqErr)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, board := range controls {
+		result, err := board.flush(ctx)
+		if err != nil {
+			logWarn("Error delivering notifications before exiting:", err)
+			continue
+		}
+		logDebug("Before exiting,", result)
 	}
+}
+
+// scanner is where a run of the scanner sends what it finds. Everything else it goes by comes from the options, so
+// scanners with their own destinations can run side by side in one process.
+type scanner struct {
+	// notifier is where notifications go, and adminNotifier is where operational alerts go if adminWebhookURL is set.
+	notifier      notify.Notifier
+	adminNotifier notify.Notifier
+	// notificationLog receives every delivered notification when the store supports it.
+	notificationLog notificationLogger
+	// hooks runs hookCommand for what the scans find. It's only set for scanning.
+	hooks *hookQueue
+}
 
-	read, readErr := io.ReadAll(resp.Body)
-	if readErr != nil {
-		return nil, fmt.Errorf("error reading response body: %w", readErr)
+// newScanner creates a scanner that sends to the configured webhooks, and records what it sends in store if store
+// keeps a record of notifications. store can be nil.
+func newScanner(store stateStore) (*scanner, error) {
+	s := &scanner{}
+	if logger, ok := store.(notificationLogger); ok {
+		s.notificationLog = logger
+	}
+	if err := s.setWebhooks(); err != nil {
+		return nil, err
 	}
 
-	return read, nil
+	return s, nil
 }
 
-func buildLeaderboard(body []byte) (leaderboardData, error) {
-	var leaderboard leaderboardData
-	marshalErr := json.Unmarshal(body, &leaderboard)
-	if marshalErr != nil {
-		return leaderboard, fmt.Errorf("error unmarshaling string `%s` into leaderboardData: %w", string(body), marshalErr)
+// setWebhooks points the scanner at the configured webhooks, leaving it as it was if either can't be used.
+func (s *scanner) setWebhooks() error {
+	if len(*webhookURLArg) == 0 {
+		return errors.New("no webhook URL provided")
+	}
+	webhook, webhookErr := notify.New(*webhookURLArg)
+	if webhookErr != nil {
+		return fmt.Errorf("unable to use the webhook: %w", webhookErr)
 	}
 
-	jsonObj, parseErr := fastjson.ParseBytes(body)
-	if parseErr != nil {
-		return leaderboard, fmt.Errorf("error parsing string into json: %w", parseErr)
+	var adminNotifier notify.Notifier
+	if len(*adminURLArg) > 0 {
+		admin, adminErr := notify.New(*adminURLArg)
+		if adminErr != nil {
+			return fmt.Errorf("unable to use the admin webhook: %w", adminErr)
+		}
+		adminNotifier = newBreakerNotifier(admin, "admin webhook", nil)
 	}
 
-	members := jsonObj.GetObject("members")
-	members.Visit(func(key []byte, memberVal *fastjson.Value) {
-		var member memberData
-		json.Unmarshal([]byte(memberVal.String()), &member)
-		member.CompletionDayLevel = make([]completionDayData, 25)
+	s.notifier = newBreakerNotifier(webhook, "webhook", s.sendAdminNotification)
+	s.adminNotifier = adminNotifier
+	return nil
+}
 
-		completionObj := memberVal.GetObject("completion_day_level")
-		completionObj.Visit(func(completionKey []byte, completionDay *fastjson.Value) {
-			memberCompletionObj := completionDayData{}
+// downloadLeaderboardData downloads a leaderboard, retrying failures that look temporary so that a blip doesn't hold up
+// announcements until the next scan.
+func downloadLeaderboardData(ctx context.Context, year, leaderboardID, sessionID string, since aocclient.Validators) ([]byte, aocclient.Validators, error) {
+	for retry := 0; ; retry++ {
+		body, validators, err := downloadLeaderboardOnce(ctx, year, leaderboardID, sessionID, since)
+		if err == nil || retry >= *fetchRetriesArg || ctx.Err() != nil || !isTransientDownloadError(err) {
+			return body, validators, err
+		}
 
-			completionDayObj, _ := completionDay.Object()
-			completionDayObj.Visit(func(completionPartKey []byte, completionPartVal *fastjson.Value) {
-				var completionPart completionPartData
-				json.Unmarshal([]byte(completionPartVal.String()), &completionPart)
-				if string(completionPartKey) == "1" {
-					memberCompletionObj.Part1 = &completionPart
-				} else {
-					memberCompletionObj.Part2 = &completionPart
-				}
-			})
+		delay := fetchRetryDelay(retry)
+		logWarn("Error downloading leaderboard", leaderboardID, "; retrying in", delay, ":", err)
+		select {
+		case <-ctx.Done():
+			return nil, aocclient.Validators{}, err
+		case <-time.After(delay):
+		}
+	}
+}
 
-			completionDayNum, _ := strconv.Atoi(string(completionKey))
-			member.CompletionDayLevel[completionDayNum-1] = memberCompletionObj
-		})
+func downloadLeaderboardOnce(ctx context.Context, year, leaderboardID, sessionID string, since aocclient.Validators) ([]byte, aocclient.Validators, error) {
+	if err := aocRequests.wait(ctx, *aocRequestSpacingArg); err != nil {
+		return nil, aocclient.Validators{}, err
+	}
+	start := time.Now()
+	client := aocclient.Client{HTTPClient: httpClient(), OnResponse: func(resp *http.Response, body []byte) {
+		logDebugf("Downloaded leaderboard %s for %s in %s: status %d, %d bytes", leaderboardID, year, time.Since(start).Round(time.Millisecond), resp.StatusCode, len(body))
+		recordResponse(resp, body, year, leaderboardID)
+	}}
 
-		leaderboard.Members = append(leaderboard.Members, member)
-	})
+	return client.FetchIfModified(ctx, year, leaderboardID, sessionID, since)
+}
 
-	return leaderboard, nil
+func (s *scanner) sendNotification(ctx context.Context, content string) error {
+	return s.sendEvent(ctx, notify.Event{Content: content})
 }
 
-func sendNotification(content string) error {
-	b, _ := json.Marshal(struct {
-		Text string `json:"text"`
-	}{
-		Text: content,
-	})
+// sendEvent delivers a notification about a single event to the webhook.
+func (s *scanner) sendEvent(ctx context.Context, event notify.Event) error {
+	logInfo("Sending notification:", event.Content)
 
-	fmt.Println("Sending notification:", content)
+	if err := deliver(ctx, s.notifier, event); err != nil {
+		return err
+	}
 
-	resp, err := http.DefaultClient.Post(webhookURL.String(), "application/json", bytes.NewReader(b))
-	if err != nil {
-		return fmt.Errorf("error POSTing to webhook: %w", err)
+	s.recordNotification("webhook", event.Content)
+	return nil
+}
+
+// sendAdminNotification delivers operational alerts to the admin webhook, if one is configured.
+func (s *scanner) sendAdminNotification(ctx context.Context, content string) error {
+	if s.adminNotifier == nil {
+		return nil
 	}
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+
+	logInfo("Sending admin notification:", content)
+
+	if err := deliver(ctx, s.adminNotifier, notify.Event{Content: content}); err != nil {
+		return err
 	}
 
+	s.recordNotification("admin", content)
 	return nil
 }
 
+func deliver(ctx context.Context, n notify.Notifier, event notify.Event) error {
+	start := time.Now()
+	err := n.Send(ctx, event)
+	logDebugf("Notification delivery took %s", time.Since(start).Round(time.Millisecond))
+	return err
+}
+
+// webhookHost is the host of a webhook's URL, for showing where something is being sent without revealing the
+// webhook's secret path.
+func webhookHost(webhook string) string {
+	if kind, u, err := notify.Parse(webhook); err == nil {
+		// the program an exec destination runs isn't a secret, and it's the only way to tell them apart
+		if kind == "exec" {
+			return u.Path
+		}
+		return u.Host
+	}
+	return ""
+}
+
 func arrayContains[T any](array []T, pred func(val T) bool) bool {
 	for _, v := range array {
 		if pred(v) {
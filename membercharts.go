@@ -0,0 +1,121 @@
+//go:build !nocharts
+
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// memberCharts draws the charts on a member's page.
+func (s *server) memberCharts(leaderboard *leaderboardData, member memberData) ([]memberChart, error) {
+	// names are left off the charts since the chart library doesn't escape what it draws
+	timeline := starProgressChart(leaderboard, s.partition.Year, []memberData{member})
+	timeline.Title = ""
+	charts := []memberChart{
+		{Title: "Stars over time", SVG: renderMemberChart(timeline)},
+		{Title: "Time after unlock", SVG: renderMemberChart(memberSolveTimeChart(member, s.partition.Year))},
+	}
+	if history := historyFor(s.store); history != nil {
+		gap, historyErr := s.memberGapChart(history, member.ID)
+		if historyErr != nil {
+			return nil, historyErr
+		}
+		charts = append(charts, memberChart{Title: "Points behind first place", SVG: renderMemberChart(gap)})
+	}
+
+	return charts, nil
+}
+
+// renderMemberChart renders c as SVG, or returns nothing when it has nothing to plot.
+func renderMemberChart(c chart.Chart) template.HTML {
+	if len(c.Series) == 0 {
+		return ""
+	}
+	c.Width, c.Height = 800, 300
+	if len(c.Series) > 1 {
+		c.Elements = []chart.Renderable{chart.Legend(&c)}
+	}
+
+	var buf bytes.Buffer
+	if err := c.Render(chart.SVG, &buf); err != nil {
+		logDebug("Unable to render member chart:", err)
+		return ""
+	}
+	// the library writes an escaped newline after the opening tag, which would otherwise show up on the page
+	return template.HTML(strings.Replace(buf.String(), `\n`, "", 1))
+}
+
+// memberSolveTimeChart plots how long after unlock the member got each star, in minutes.
+func memberSolveTimeChart(member memberData, year string) chart.Chart {
+	parts := []chart.ContinuousSeries{{Name: "Part 1"}, {Name: "Part 2"}}
+	for dayIdx, day := range member.CompletionDayLevel {
+		unlock := dayUnlock(year, dayIdx+1)
+		for partIdx, part := range []*completionPartData{day.Part1, day.Part2} {
+			if part == nil {
+				continue
+			}
+			parts[partIdx].XValues = append(parts[partIdx].XValues, float64(dayIdx+1))
+			parts[partIdx].YValues = append(parts[partIdx].YValues, time.Unix(part.GotStarAt, 0).Sub(unlock).Minutes())
+		}
+	}
+
+	var series []chart.Series
+	for _, part := range parts {
+		if len(part.XValues) >= 2 {
+			series = append(series, part)
+		}
+	}
+
+	return chart.Chart{
+		Background: chart.Style{Padding: chart.Box{Top: 20, Left: 20, Right: 20, Bottom: 20}},
+		XAxis:      chart.XAxis{Name: "Day", ValueFormatter: formatWholeNumber},
+		YAxis:      chart.YAxis{Name: "Minutes after unlock", ValueFormatter: formatWholeNumber},
+		Series:     series,
+	}
+}
+
+// memberGapChart plots how far behind whoever was in first place the member was in each recorded snapshot.
+func (s *server) memberGapChart(history historyStore, id int) (chart.Chart, error) {
+	snaps, listErr := history.Snapshots(s.partition)
+	if listErr != nil {
+		return chart.Chart{}, listErr
+	}
+	if len(snaps) > memberHistoryLimit {
+		snaps = snaps[len(snaps)-memberHistoryLimit:]
+	}
+
+	ts := chart.TimeSeries{Name: "Points behind"}
+	maxGap := 1.0
+	for _, snap := range snaps {
+		_, leaderboard, loadErr := loadSnapshotLeaderboard(history, snap.ID)
+		if loadErr != nil {
+			return chart.Chart{}, loadErr
+		}
+
+		standings := rankedStandings(leaderboard, s.partition.Year)
+		member := arrayFind(standings, func(m memberData) bool { return m.ID == id })
+		if member == nil {
+			continue
+		}
+		gap := float64(standings[0].LocalScore - member.LocalScore)
+		maxGap = max(maxGap, gap)
+		ts.XValues = append(ts.XValues, snap.FetchedAt)
+		ts.YValues = append(ts.YValues, gap)
+	}
+
+	c := chart.Chart{
+		Background: chart.Style{Padding: chart.Box{Top: 20, Left: 20, Right: 20, Bottom: 20}},
+		XAxis:      chart.XAxis{ValueFormatter: chart.TimeValueFormatterWithFormat("Jan 2")},
+		// the range is given so that whoever has led the whole time gets a flat line instead of an error
+		YAxis: chart.YAxis{Name: "Points", ValueFormatter: formatWholeNumber, Range: &chart.ContinuousRange{Min: 0, Max: maxGap}},
+	}
+	if len(ts.XValues) >= 2 {
+		c.Series = []chart.Series{ts}
+	}
+	return c, nil
+}
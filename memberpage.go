@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"time"
+)
+
+// memberHistoryLimit is how many of the most recent snapshots a member page charts, so that a long history doesn't
+// make every page load parse thousands of them.
+const memberHistoryLimit = 500
+
+var memberPageTemplate = template.Must(template.New("member").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="300">
+<title>{{.Name}} - Advent of Code {{.Year}} leaderboard</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+svg { display: block; max-width: 100%; height: auto; margin-bottom: 1em; }
+</style>
+</head>
+<body>
+<p><a href="../../">&larr; Leaderboard</a></p>
+<h1>{{.Name}}</h1>
+<p>{{if .Rank}}Rank {{.Rank}} with {{.Stars}} stars and {{.Score}}.{{else}}Not ranked, with {{.Stars}} stars.{{end}}</p>
+{{range .Charts}}<h2>{{.Title}}</h2>
+{{if .SVG}}{{.SVG}}{{else}}<p>Not enough data to chart yet.</p>{{end}}
+{{end}}<p>Last updated {{.Updated}}.</p>
+</body>
+</html>
+`))
+
+// memberChart is one chart on a member's page. SVG is empty when there isn't enough to plot yet.
+type memberChart struct {
+	Title string
+	SVG   template.HTML
+}
+
+// handleMemberPage serves /members/{id}: charts of one member's progress.
+func (s *server) handleMemberPage(w http.ResponseWriter, r *http.Request) {
+	id, idErr := pathID(r.URL.Path, "/members/")
+	if idErr != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	leaderboard, state, loadErr := s.cachedLeaderboard()
+	if loadErr != nil {
+		logError("Error loading leaderboard for member page:", loadErr)
+		http.Error(w, "error loading leaderboard", http.StatusInternalServerError)
+		return
+	}
+	if leaderboard == nil || arrayFind(leaderboard.Members, func(m memberData) bool { return m.ID == id }) == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.renderMemberPage(w, leaderboard, id, state); err != nil {
+		logError("Error rendering member page:", err)
+	}
+}
+
+// renderMemberPage writes the page for the member with the given ID, who must be on the leaderboard.
+func (s *server) renderMemberPage(w io.Writer, leaderboard *leaderboardData, id int, state scanState) error {
+	data := struct {
+		Year    string
+		Name    string
+		Rank    int
+		Stars   int
+		Score   string
+		Updated string
+		Charts  []memberChart
+	}{
+		Year:    s.partition.Year,
+		Updated: time.Unix(state.LastRead, 0).In(s.board.Location).Format("Jan 2 3:04pm MST"),
+	}
+
+	var member memberData
+	for _, m := range listedStandings(leaderboard, s.partition.Year) {
+		if m.ID == id {
+			member = m.memberData
+			data.Rank = m.Rank
+			break
+		}
+	}
+	data.Name, data.Stars, data.Score = displayName(member), member.Stars, scoreText(member.LocalScore)
+
+	charts, chartsErr := s.memberCharts(leaderboard, member)
+	if chartsErr != nil {
+		return chartsErr
+	}
+	data.Charts = charts
+
+	return memberPageTemplate.Execute(w, data)
+}
+
+// memberPagePath is where a member's page is relative to the dashboard.
+func memberPagePath(id int) string {
+	return fmt.Sprintf("members/%d/", id)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/goccy/go-json"
+)
+
+// memberListing is one row of the members command's output.
+type memberListing struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Stars int    `json:"stars"`
+	// Status is where the member is in being announced: "announced", "pending" (queued in the outbox), "new" (will be
+	// welcomed on the next scan), or "below minStars".
+	Status string `json:"status"`
+}
+
+// memberStatus works out whether a member has been welcomed yet, based on what the scanner last saw and what's still
+// waiting to be delivered.
+func memberStatus(member memberData, state scanState, scanned *leaderboardData, year, leaderboardID string) string {
+	if member.Stars < *minStarsArg {
+		return "below minStars"
+	}
+
+	key := joinKey(year, leaderboardID, member.ID)
+	if arrayContains(state.Outbox, func(e outboxEntry) bool { return e.Key == key }) {
+		return "pending"
+	}
+
+	if scanned != nil {
+		if seen := arrayFind(scanned.Members, func(m memberData) bool { return m.ID == member.ID }); seen != nil && seen.Stars >= *minStarsArg {
+			return "announced"
+		}
+	}
+
+	return "new"
+}
+
+func runMembersCommand(args []string) error {
+	fs := flag.NewFlagSet("members", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	asJSON := fs.Bool("json", false, "print the members as json instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	leaderboard, _, loadErr := loadLeaderboard(*cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	state, stateErr := store.Load(partition)
+	if stateErr != nil {
+		return stateErr
+	}
+	var scanned *leaderboardData
+	if len(state.LastBody) > 0 {
+		if last, err := buildLeaderboard(state.LastBody); err == nil {
+			scanned = &last
+		}
+	}
+
+	members := append([]memberData(nil), leaderboard.Members...)
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+
+	listings := make([]memberListing, 0, len(members))
+	for _, member := range members {
+		listings = append(listings, memberListing{
+			ID:     member.ID,
+			Name:   member.Name,
+			Stars:  member.Stars,
+			Status: memberStatus(member, state, scanned, partition.Year, partition.Leaderboard),
+		})
+	}
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(listings, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tName\tStars\tStatus")
+	for idx, listing := range listings {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", listing.ID, displayName(members[idx]), listing.Stars, listing.Status)
+	}
+	w.Flush()
+
+	return nil
+}
@@ -0,0 +1,12 @@
+//go:build nocharts
+
+package main
+
+func runChartCommand(args []string) error {
+	return errLeftOut("the chart command", "nocharts")
+}
+
+// memberCharts leaves member pages without charts.
+func (s *server) memberCharts(leaderboard *leaderboardData, member memberData) ([]memberChart, error) {
+	return nil, nil
+}
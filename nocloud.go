@@ -0,0 +1,11 @@
+//go:build nocloud
+
+package main
+
+func newS3Store(spec string) (stateStore, error) {
+	return nil, errLeftOut("the s3 store", "nocloud")
+}
+
+func newGCSStore(spec string) (stateStore, error) {
+	return nil, errLeftOut("the gs store", "nocloud")
+}
@@ -0,0 +1,7 @@
+//go:build nogrpc
+
+package main
+
+func startGRPCServer(srv *server, addr, tlsCert, tlsKey string, serveErr chan<- error) (func(), error) {
+	return nil, errLeftOut("the gRPC service", "nogrpc")
+}
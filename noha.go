@@ -0,0 +1,11 @@
+//go:build noha
+
+package main
+
+func newEtcdStore(spec string) (stateStore, error) {
+	return nil, errLeftOut("the etcd store", "noha")
+}
+
+func newConsulStore(spec string) (stateStore, error) {
+	return nil, errLeftOut("the consul store", "noha")
+}
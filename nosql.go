@@ -0,0 +1,11 @@
+//go:build nosql
+
+package main
+
+func newSQLiteStore(spec string) (stateStore, error) {
+	return nil, errLeftOut("the sqlite store", "nosql")
+}
+
+func newPostgresStore(spec string) (stateStore, error) {
+	return nil, errLeftOut("the postgres store", "nosql")
+}
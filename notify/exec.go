@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// Exec delivers messages by running a program with the event as json on its standard input, so destinations that
+// aren't built in can be added without changing the scanner. The program reports failure by exiting with a nonzero
+// status, and whatever it wrote to standard error is included in the error.
+type Exec struct {
+	Path string
+	Args []string
+	// Timeout is how long the program gets before it's killed, when the context doesn't have a deadline of its own.
+	Timeout time.Duration
+}
+
+// newExec creates an Exec from exec:///path/to/program?arg=one&arg=two.
+func newExec(u *url.URL) (Notifier, error) {
+	path := u.Path
+	if len(u.Opaque) > 0 {
+		path = u.Opaque
+	}
+	if len(path) == 0 {
+		return nil, errors.New("exec destinations need a program to run, as in exec:///path/to/program")
+	}
+
+	return &Exec{Path: path, Args: u.Query()["arg"], Timeout: 30 * time.Second}, nil
+}
+
+func init() {
+	Register("exec", newExec)
+}
+
+func (e *Exec) Send(ctx context.Context, event Event) error {
+	if _, ok := ctx.Deadline(); !ok && e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+
+	input, _ := json.Marshal(event)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.Path, e.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return fmt.Errorf("error running %s: %w: %s", e.Path, err, msg)
+		}
+		return fmt.Errorf("error running %s: %w", e.Path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package notify delivers messages to chat platforms and anything else that can be told about leaderboard events.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// Event is a message to deliver.
+type Event struct {
+	// Key uniquely identifies the event the message is about, or is empty for messages that aren't about a single event
+	// (such as digests), so backends can thread or deduplicate messages if they support it.
+	Key     string `json:"key,omitempty"`
+	Content string `json:"content"`
+}
+
+// Notifier delivers messages to one destination.
+type Notifier interface {
+	Send(ctx context.Context, event Event) error
+}
+
+// Factory creates a Notifier for a destination URL.
+type Factory func(u *url.URL) (Notifier, error)
+
+// registry holds a Factory for every kind of destination, keyed by the kind's name.
+var registry = map[string]Factory{}
+
+// Register makes a kind of destination available to New. It's meant to be called from init functions, and panics if
+// the kind is already registered.
+func Register(kind string, factory Factory) {
+	if _, ok := registry[kind]; ok {
+		panic("notify: " + kind + " is already registered")
+	}
+	registry[kind] = factory
+}
+
+// Kinds lists the registered kinds of destination.
+func Kinds() []string {
+	var kinds []string
+	for kind := range registry {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// New returns a Notifier for a destination, which is a URL as described by Parse.
+func New(destination string) (Notifier, error) {
+	kind, u, parseErr := Parse(destination)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	factory, ok := registry[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown kind of destination %q; supported kinds are: %s", kind, strings.Join(Kinds(), ", "))
+	}
+
+	return factory(u)
+}
+
+// Parse splits a destination into its kind and URL. The kind can be given before the URL's scheme, as in
+// discord+https://discord.com/api/webhooks/..., and otherwise it's guessed from the URL, falling back to a generic
+// webhook.
+func Parse(destination string) (string, *url.URL, error) {
+	u, parseErr := url.Parse(destination)
+	if parseErr != nil {
+		return "", nil, fmt.Errorf("unable to parse %s as a URL: %w", destination, parseErr)
+	}
+
+	if kind, scheme, ok := strings.Cut(u.Scheme, "+"); ok {
+		u.Scheme = scheme
+		return kind, u, nil
+	}
+	return guessKind(u), u, nil
+}
+
+// guessKind recognizes the webhooks of platforms that need their own format.
+func guessKind(u *url.URL) string {
+	switch {
+	case u.Scheme == "exec":
+		return "exec"
+	case u.Host == "hooks.slack.com":
+		return "slack"
+	// Discord's Slack-compatible webhooks end in /slack and take the generic format
+	case (u.Host == "discord.com" || u.Host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/") && !strings.HasSuffix(u.Path, "/slack"):
+		return "discord"
+	default:
+		return "webhook"
+	}
+}
+
+func init() {
+	Register("webhook", func(u *url.URL) (Notifier, error) { return &Webhook{URL: u.String()}, nil })
+	Register("slack", func(u *url.URL) (Notifier, error) { return &Webhook{URL: u.String()}, nil })
+	Register("discord", func(u *url.URL) (Notifier, error) { return &Discord{URL: u.String()}, nil })
+}
+
+// DefaultClient makes the requests of notifiers that don't have a Client of their own. Programs can replace it to give
+// every notifier the same timeouts and User-Agent.
+var DefaultClient = http.DefaultClient
+
+// postJSON posts v to u, expecting a 2xx response.
+func postJSON(ctx context.Context, client *http.Client, u string, v any) error {
+	b, _ := json.Marshal(v)
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if reqErr != nil {
+		return fmt.Errorf("error creating webhook request: %w", reqErr)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error POSTing to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Webhook posts messages as {"text": ...} json, the format of incoming webhooks such as Slack's and Mattermost's.
+type Webhook struct {
+	URL string
+	// Client makes the requests. Nil means DefaultClient.
+	Client *http.Client
+}
+
+func (w *Webhook) Send(ctx context.Context, event Event) error {
+	return postJSON(ctx, w.Client, w.URL, struct {
+		Text string `json:"text"`
+	}{event.Content})
+}
+
+// Discord posts messages to a Discord webhook.
+type Discord struct {
+	URL string
+	// Client makes the requests. Nil means DefaultClient.
+	Client *http.Client
+}
+
+// discordMaxLength is the longest message Discord accepts.
+const discordMaxLength = 2000
+
+func (d *Discord) Send(ctx context.Context, event Event) error {
+	content := event.Content
+	if runes := []rune(content); len(runes) > discordMaxLength {
+		content = string(runes[:discordMaxLength-1]) + "…"
+	}
+	return postJSON(ctx, d.Client, d.URL, struct {
+		Content string `json:"content"`
+	}{content})
+}
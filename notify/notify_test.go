@@ -0,0 +1,148 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		destination string
+		kind        string
+		url         string
+	}{
+		{"https://example.com/hook", "webhook", "https://example.com/hook"},
+		{"https://hooks.slack.com/services/T/B/x", "slack", "https://hooks.slack.com/services/T/B/x"},
+		{"https://discord.com/api/webhooks/1/abc", "discord", "https://discord.com/api/webhooks/1/abc"},
+		{"https://discordapp.com/api/webhooks/1/abc", "discord", "https://discordapp.com/api/webhooks/1/abc"},
+		{"https://discord.com/api/webhooks/1/abc/slack", "webhook", "https://discord.com/api/webhooks/1/abc/slack"},
+		{"https://discord.com/channels/1", "webhook", "https://discord.com/channels/1"},
+		{"exec:///usr/local/bin/notify", "exec", "exec:///usr/local/bin/notify"},
+		{"discord+https://example.com/relay", "discord", "https://example.com/relay"},
+		{"slack+http://localhost:8080/hook", "slack", "http://localhost:8080/hook"},
+	}
+	for _, test := range tests {
+		t.Run(test.destination, func(t *testing.T) {
+			kind, u, err := Parse(test.destination)
+			if err != nil {
+				t.Fatalf("Parse returned an error: %v", err)
+			}
+			if kind != test.kind || u.String() != test.url {
+				t.Errorf("got %s %s, want %s %s", kind, u, test.kind, test.url)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidURLs(t *testing.T) {
+	if _, _, err := Parse("https://example.com/%zz"); err == nil {
+		t.Error("Parse returned no error")
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		destination string
+		want        Notifier
+		wantErr     string
+	}{
+		{"https://example.com/hook", &Webhook{URL: "https://example.com/hook"}, ""},
+		{"https://hooks.slack.com/services/T/B/x", &Webhook{URL: "https://hooks.slack.com/services/T/B/x"}, ""},
+		{"https://discord.com/api/webhooks/1/abc", &Discord{URL: "https://discord.com/api/webhooks/1/abc"}, ""},
+		{"carrier-pigeon+https://example.com", nil, `unknown kind of destination "carrier-pigeon"`},
+	}
+	for _, test := range tests {
+		t.Run(test.destination, func(t *testing.T) {
+			got, err := New(test.destination)
+			if len(test.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	type fake struct{ Notifier }
+	Register("test-fake", func(u *url.URL) (Notifier, error) { return fake{}, nil })
+	defer delete(registry, "test-fake")
+
+	got, err := New("test-fake+https://example.com")
+	if err != nil {
+		t.Fatalf("New returned an error: %v", err)
+	}
+	if _, ok := got.(fake); !ok {
+		t.Errorf("got %#v, want the registered kind's notifier", got)
+	}
+	if kinds := Kinds(); !reflect.DeepEqual(kinds, []string{"discord", "exec", "slack", "test-fake", "webhook"}) {
+		t.Errorf("got kinds %v", kinds)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a kind twice didn't panic")
+		}
+	}()
+	Register("test-fake", func(u *url.URL) (Notifier, error) { return fake{}, nil })
+}
+
+func TestSend(t *testing.T) {
+	long := strings.Repeat("é", discordMaxLength+10)
+	tests := []struct {
+		name     string
+		notifier func(u string) Notifier
+		content  string
+		want     map[string]string
+	}{
+		{"webhook", func(u string) Notifier { return &Webhook{URL: u} }, "hello", map[string]string{"text": "hello"}},
+		{"discord", func(u string) Notifier { return &Discord{URL: u} }, "hello", map[string]string{"content": "hello"}},
+		{"discord truncated", func(u string) Notifier { return &Discord{URL: u} }, long, map[string]string{"content": long[:len("é")*(discordMaxLength-1)] + "…"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got map[string]string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Errorf("error decoding the request: %v", err)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			if err := test.notifier(srv.URL).Send(context.Background(), Event{Content: test.content}); err != nil {
+				t.Fatalf("Send returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("posted %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSendFailsOnErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	err := (&Webhook{URL: srv.URL}).Send(context.Background(), Event{Content: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Errorf("got error %v, want one for the 429", err)
+	}
+}
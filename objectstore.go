@@ -0,0 +1,168 @@
+//go:build !nocloud
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"golang.org/x/oauth2/google"
+)
+
+const defaultObjectName = "aoc-scanner/state.json"
+
+// parseObjectSpec splits a spec like s3://bucket/path/to/state.json into its bucket and object key.
+func parseObjectSpec(spec string) (string, string, error) {
+	u, parseErr := url.Parse(spec)
+	if parseErr != nil {
+		return "", "", fmt.Errorf("error parsing store %q: %w", spec, parseErr)
+	}
+	if len(u.Host) == 0 {
+		return "", "", fmt.Errorf("store %q is missing a bucket name", spec)
+	}
+
+	key := strings.TrimPrefix(u.Path, "/")
+	if len(key) == 0 {
+		key = defaultObjectName
+	}
+
+	return u.Host, key, nil
+}
+
+// s3Store keeps state in an S3 object. Credentials and region come from the standard AWS chain (environment,
+// shared config files, or an attached IAM role).
+type s3Store struct {
+	client *s3.Client
+	bucket string
+	key    string
+}
+
+func newS3Store(spec string) (stateStore, error) {
+	bucket, key, specErr := parseObjectSpec(spec)
+	if specErr != nil {
+		return nil, specErr
+	}
+
+	cfg, cfgErr := config.LoadDefaultConfig(context.Background())
+	if cfgErr != nil {
+		return nil, fmt.Errorf("error loading AWS configuration: %w", cfgErr)
+	}
+
+	return &s3Store{client: s3.NewFromConfig(cfg), bucket: bucket, key: key}, nil
+}
+
+func (s *s3Store) Load(p statePartition) (scanState, error) {
+	key := p.Apply(s.key)
+	out, err := s.client.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: aws.String(key)})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return scanState{}, nil
+	}
+	if err != nil {
+		return scanState{}, fmt.Errorf("error reading s3://%s/%s: %w", s.bucket, key, err)
+	}
+	defer out.Body.Close()
+
+	data, readErr := io.ReadAll(out.Body)
+	if readErr != nil {
+		return scanState{}, fmt.Errorf("error reading s3://%s/%s: %w", s.bucket, key, readErr)
+	}
+
+	return unmarshalState(data)
+}
+
+func (s *s3Store) Save(p statePartition, state scanState) error {
+	data, marshalErr := marshalState(state)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	key := p.Apply(s.key)
+	_, err := s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	if err != nil {
+		return fmt.Errorf("error writing s3://%s/%s: %w", s.bucket, key, err)
+	}
+
+	return nil
+}
+
+// gcsStore keeps state in a Google Cloud Storage object. Credentials come from Application Default Credentials
+// (GOOGLE_APPLICATION_CREDENTIALS, gcloud's login, or the attached service account).
+type gcsStore struct {
+	client *http.Client
+	bucket string
+	object string
+}
+
+func newGCSStore(spec string) (stateStore, error) {
+	bucket, object, specErr := parseObjectSpec(spec)
+	if specErr != nil {
+		return nil, specErr
+	}
+
+	client, clientErr := google.DefaultClient(context.Background(), "https://www.googleapis.com/auth/devstorage.read_write")
+	if clientErr != nil {
+		return nil, fmt.Errorf("error finding Google Cloud credentials: %w", clientErr)
+	}
+
+	return &gcsStore{client: client, bucket: bucket, object: object}, nil
+}
+
+func (s *gcsStore) Load(p statePartition) (scanState, error) {
+	object := p.Apply(s.object)
+	resp, err := s.client.Get(fmt.Sprintf("https://storage.googleapis.com/storage/v1/b/%s/o/%s?alt=media", url.PathEscape(s.bucket), url.PathEscape(object)))
+	if err != nil {
+		return scanState{}, fmt.Errorf("error reading gs://%s/%s: %w", s.bucket, object, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return scanState{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return scanState{}, fmt.Errorf("unexpected status code %d reading gs://%s/%s", resp.StatusCode, s.bucket, object)
+	}
+
+	data, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		return scanState{}, fmt.Errorf("error reading gs://%s/%s: %w", s.bucket, object, readErr)
+	}
+
+	return unmarshalState(data)
+}
+
+func (s *gcsStore) Save(p statePartition, state scanState) error {
+	data, marshalErr := marshalState(state)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	object := p.Apply(s.object)
+	uploadURL := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s", url.PathEscape(s.bucket), url.QueryEscape(object))
+	resp, err := s.client.Post(uploadURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error writing gs://%s/%s: %w", s.bucket, object, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d writing gs://%s/%s", resp.StatusCode, s.bucket, object)
+	}
+
+	return nil
+}
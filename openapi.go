@@ -0,0 +1,19 @@
+package main
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec describes the /api endpoints. The client package is kept in step with it, so any change to an /api
+// response needs to be made in all three places.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+func (s *server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	// let browser-based tools like Swagger UI on another origin load it
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Write(openAPISpec)
+}
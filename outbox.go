@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/diff"
+	"pernicious.games/advent-of-code-scanner/notify"
+)
+
+// detectEvents compares two copies of a leaderboard and returns everything worth announcing, oldest first.
+func detectEvents(lastLeaderboard, leaderboard *leaderboardData, year string, board leaderboardSettings) []outboxEntry {
+	return detectEventsSince(diff.NewBaseline(lastLeaderboard), leaderboard, year, board)
+}
+
+// detectEventsSince is detectEvents for when all that's been kept of the last copy is its baseline.
+func detectEventsSince(last diff.Baseline, leaderboard *leaderboardData, year string, board leaderboardSettings) []outboxEntry {
+	var events []outboxEntry
+	for _, event := range diff.EventsSince(last, leaderboard, diff.Options{
+		Year:          year,
+		LeaderboardID: board.ID,
+		Location:      board.Location,
+		MinStars:      *minStarsArg,
+		Unranked:      excludedMembers(),
+		Debugf:        logDebugf,
+	}) {
+		events = append(events, outboxEntry{Key: event.Key, Content: event.Content, At: event.At})
+	}
+	return events
+}
+
+// compareScans decodes two downloads of a leaderboard and returns everything worth announcing between them, along with
+// the live events for hooks when there's a hook command. The cached copy is summarized and let go of before the
+// downloaded one is decoded, so a scan never holds two decoded leaderboards at once.
+func compareScans(lastBody, currBody []byte, scannedAt time.Time, board leaderboardSettings) ([]outboxEntry, []liveEvent, error) {
+	// a download identical to the last one can't have anything new in it, so there's nothing to parse
+	if bytes.Equal(lastBody, currBody) {
+		logDebug("The downloaded leaderboard is identical to the cached one")
+		return nil, nil, nil
+	}
+
+	lastLeaderboard, lastErr := decodeLeaderboard(bytes.NewReader(lastBody))
+	if lastErr != nil {
+		return nil, nil, fmt.Errorf("error building leaderboard from cached body: %w", lastErr)
+	}
+	last := newScanBaseline(&lastLeaderboard, *yearArg, len(*hookCommandArg) > 0)
+	// nothing refers to the cached body or its leaderboard after this, so they can be collected while the download is
+	// decoded
+	lastLeaderboard, lastBody = leaderboardData{}, nil
+
+	leaderboard, currErr := decodeLeaderboard(bytes.NewReader(currBody))
+	if currErr != nil {
+		return nil, nil, fmt.Errorf("error building leaderboard from downloaded body: %w", currErr)
+	}
+
+	events := detectEventsSince(last.events, &leaderboard, *yearArg, board)
+	var live []liveEvent
+	if len(*hookCommandArg) > 0 {
+		live = liveEventsBetween(last, &leaderboard, scannedAt, *yearArg, board)
+	}
+	return events, live, nil
+}
+
+// flushOutbox delivers everything in the state's outbox in order, saving after each delivery so that a crash never
+// leaves a sent entry behind to be sent again. Entries that fail stay in the outbox and are retried on the next
+// flush; since ordering matters, nothing after a failed entry is attempted either. Cancelling ctx ends the flush the
+// same way as a failed delivery.
+func (s *scanner) flushOutbox(ctx context.Context, state *scanState, ledger deliveryLedger, save func(scanState)) {
+	if len(state.Outbox) > 0 {
+		logDebugf("Delivering %d pending notifications", len(state.Outbox))
+	}
+
+	for len(state.Outbox) > 0 && ctx.Err() == nil {
+		entry := state.Outbox[0]
+
+		delivered, ledgerErr := ledger.HasDelivered(entry.Key)
+		if ledgerErr != nil {
+			// better to risk a duplicate than to silently drop an announcement
+			logWarn("Error reading delivery ledger, sending anyway:", ledgerErr)
+		}
+		if delivered {
+			logDebug("Already delivered", entry.Key, "; skipping")
+		} else {
+			if err := s.sendEvent(ctx, notify.Event{Key: entry.Key, Content: entry.Content}); err != nil {
+				if errors.Is(err, errCircuitOpen) {
+					logDebugf("Holding %d notifications until the webhook's cooldown is over", len(state.Outbox))
+					return
+				}
+				logErrorf("Error sending notification %s, will retry on the next scan: %v\n", entry.Key, err)
+				return
+			}
+
+			if err := ledger.MarkDelivered(entry.Key, time.Now()); err != nil {
+				logError("Error recording delivery of", entry.Key, "in the ledger:", err)
+			}
+		}
+
+		state.Outbox = state.Outbox[1:]
+		save(*state)
+	}
+}
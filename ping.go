@@ -0,0 +1,141 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+var pingTokenArg = flag.String("pingToken", "", "token of the chat platform's outgoing webhook that /ping answers with the scanner's status; a Microsoft Teams webhook's security token works too")
+
+// pingRequest holds the fields of an outgoing webhook's request that /ping uses. Slack and Mattermost send a token and
+// the message's text; Teams sends the text and signs the body instead.
+type pingRequest struct {
+	Token    string `json:"token"`
+	Text     string `json:"text"`
+	UserName string `json:"user_name"`
+}
+
+// teamsSignatureMatches checks a Teams outgoing webhook's "Authorization: HMAC <signature>" header, which is the body
+// signed with the base64-decoded security token.
+func teamsSignatureMatches(header string, body []byte) bool {
+	signature, ok := strings.CutPrefix(header, "HMAC ")
+	if !ok {
+		return false
+	}
+	key, keyErr := base64.StdEncoding.DecodeString(*pingTokenArg)
+	if keyErr != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hmac.Equal([]byte(base64.StdEncoding.EncodeToString(mac.Sum(nil))), []byte(signature))
+}
+
+// scannerStatus describes what the scanner has been up to, for someone checking that it's set up right.
+func (s *server) scannerStatus() string {
+	var sb strings.Builder
+	v, commit, _ := buildMetadata()
+	fmt.Fprintf(&sb, "Advent of Code scanner %s (%s) watching leaderboard %s for %s.\n", v, commit, s.partition.Leaderboard, s.partition.Year)
+
+	state, loadErr := s.store.Load(s.partition)
+	if loadErr != nil {
+		logError("Error loading state for ping:", loadErr)
+		sb.WriteString(":warning: The scanner's state couldn't be loaded.\n")
+		return sb.String()
+	}
+
+	now := time.Now()
+	if state.LastRead == 0 {
+		sb.WriteString(":warning: The leaderboard hasn't been downloaded yet.\n")
+	} else {
+		since := now.Sub(time.Unix(state.LastRead, 0)).Round(time.Second)
+		if window := readyWindow(s.partition.Year, now); since > window {
+			fmt.Fprintf(&sb, ":warning: The last successful download was %s ago, more than the %s it should be at most.\n", since, window)
+		} else {
+			fmt.Fprintf(&sb, "The last successful download was %s ago.\n", since)
+		}
+	}
+	if len(state.LastBody) > 0 {
+		if leaderboard, buildErr := buildLeaderboard(state.LastBody); buildErr == nil {
+			fmt.Fprintf(&sb, "The leaderboard has %d members.\n", len(leaderboard.Members))
+		}
+	}
+	if len(state.FailedSessions) > 0 {
+		fmt.Fprintf(&sb, ":warning: %d session cookies have been rejected by the site.\n", len(state.FailedSessions))
+	}
+	if state.Failures > 0 {
+		fmt.Fprintf(&sb, ":warning: The last %d scans failed, most recently with: %s\n", state.Failures, state.LastFailure)
+	}
+	fmt.Fprintf(&sb, "%d notifications are waiting to be delivered.\n", len(state.Outbox))
+
+	return sb.String()
+}
+
+// handlePing answers chat platforms' outgoing webhooks with the scanner's status, so whoever is setting the scanner up
+// can check from the chat that it's reachable and running without needing access to the host.
+func (s *server) handlePing(w http.ResponseWriter, r *http.Request) {
+	if len(*pingTokenArg) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, readErr := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if readErr != nil {
+		http.Error(w, "error reading request", http.StatusBadRequest)
+		return
+	}
+
+	var req pingRequest
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		json.Unmarshal(body, &req)
+	} else if form, formErr := url.ParseQuery(string(body)); formErr == nil {
+		req = pingRequest{Token: form.Get("token"), Text: form.Get("text"), UserName: form.Get("user_name")}
+	}
+
+	teams := len(r.Header.Get("Authorization")) > 0
+	authorized := tokenMatches(req.Token, *pingTokenArg)
+	if teams {
+		authorized = teamsSignatureMatches(r.Header.Get("Authorization"), body)
+	}
+	if !authorized {
+		writeAPIError(w, http.StatusUnauthorized, "invalid token")
+		return
+	}
+
+	reply := "Pong"
+	if len(req.UserName) > 0 {
+		reply += " @" + req.UserName
+	}
+	if text := strings.TrimSpace(req.Text); len(text) > 0 {
+		reply += fmt.Sprintf(", I heard %q", text)
+	}
+	reply += "!\n" + s.scannerStatus()
+	logDebug("Answered a ping from an outgoing webhook")
+
+	// Teams needs to be told the reply is a message, and Mattermost would take that as a post type it doesn't know
+	if teams {
+		writeJSON(w, http.StatusOK, struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		}{"message", reply})
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Text string `json:"text"`
+	}{reply})
+}
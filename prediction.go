@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+var weeklyPredictionArg = flag.Bool("weeklyPrediction", false, "add a for-fun projection of each member's chance of winning to the weekly recap, from simulating the rest of the event")
+
+const (
+	// predictionTrials is how many times the rest of the event is simulated for a projection.
+	predictionTrials = 2000
+	// predictionShown is how many of the likeliest winners a projection lists.
+	predictionShown = 5
+)
+
+// pastPlacing is how a member placed on each part of a day they could have played, as how far down the part's
+// finishers they were, from 0 for first to just under 1 for last, or -1 if they didn't finish it.
+type pastPlacing [2]float64
+
+// pastPlacings is how each member placed on every day that's unlocked by now, by member ID.
+func pastPlacings(leaderboard *leaderboardData, days int) map[int][]pastPlacing {
+	placings := map[int][]pastPlacing{}
+	for day := 1; day <= days; day++ {
+		placed := map[int]*pastPlacing{}
+		for _, member := range leaderboard.Members {
+			placed[member.ID] = &pastPlacing{-1, -1}
+		}
+		for part := 0; part < 2; part++ {
+			finishers := dayFinishers(leaderboard, day, part+1)
+			for rank, f := range finishers {
+				placed[f.Member.ID][part] = float64(rank) / float64(len(finishers))
+			}
+		}
+		for id, p := range placed {
+			placings[id] = append(placings[id], *p)
+		}
+	}
+	return placings
+}
+
+// winProbabilities projects each member's chance of winning on local score, by member ID. Each trial plays out the
+// days that haven't unlocked by now, with every member doing on each one the way they did on one of their earlier
+// days picked at random, and scores the result the way the site does. A tie for first shares the win. Members in
+// excludeMembers don't take part. It returns nil until a day has been played, and after the last one has unlocked.
+func winProbabilities(leaderboard *leaderboardData, year string, now time.Time, rng *rand.Rand) map[int]float64 {
+	leaderboard = rankedLeaderboard(leaderboard)
+	played := 0
+	for day := 1; day <= eventDays(year) && !dayUnlock(year, day).After(now); day++ {
+		played = day
+	}
+	remaining := eventDays(year) - played
+	if played == 0 || remaining == 0 || len(leaderboard.Members) == 0 {
+		return nil
+	}
+
+	placings := pastPlacings(leaderboard, played)
+	base := computeLocalScores(leaderboard, eventDays(year))
+	wins := map[int]float64{}
+	scores := make([]int, len(leaderboard.Members))
+	type entry struct {
+		member    int
+		placement float64
+	}
+	entries := make([]entry, 0, len(leaderboard.Members))
+	for trial := 0; trial < predictionTrials; trial++ {
+		for idx, member := range leaderboard.Members {
+			scores[idx] = base[member.ID]
+		}
+
+		for day := 0; day < remaining; day++ {
+			picked := make([]pastPlacing, len(leaderboard.Members))
+			for idx, member := range leaderboard.Members {
+				past := placings[member.ID]
+				picked[idx] = past[rng.Intn(len(past))]
+			}
+			for part := 0; part < 2; part++ {
+				entries = entries[:0]
+				for idx := range leaderboard.Members {
+					if picked[idx][part] >= 0 {
+						entries = append(entries, entry{member: idx, placement: picked[idx][part]})
+					}
+				}
+				// shuffling first breaks ties between members who placed the same at random
+				rng.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+				sort.SliceStable(entries, func(i, j int) bool { return entries[i].placement < entries[j].placement })
+				for rank, e := range entries {
+					scores[e.member] += len(leaderboard.Members) - rank
+				}
+			}
+		}
+
+		best, leaders := -1, 0
+		for _, score := range scores {
+			switch {
+			case score > best:
+				best, leaders = score, 1
+			case score == best:
+				leaders++
+			}
+		}
+		for idx, score := range scores {
+			if score == best {
+				wins[leaderboard.Members[idx].ID] += 1 / float64(leaders)
+			}
+		}
+	}
+
+	for id := range wins {
+		wins[id] /= predictionTrials
+	}
+	return wins
+}
+
+// predictionSection is the likeliest winners for a weekly recap, or is empty if recaps don't include a projection or
+// there's nothing to project.
+func predictionSection(leaderboard *leaderboardData, year string, now time.Time) string {
+	if !*weeklyPredictionArg {
+		return ""
+	}
+	// the same leaderboard always gives the same projection
+	wins := winProbabilities(leaderboard, year, now, rand.New(rand.NewSource(1)))
+	if wins == nil {
+		return ""
+	}
+
+	members := rankedStandings(leaderboard, year)
+	sort.SliceStable(members, func(i, j int) bool { return wins[members[i].ID] > wins[members[j].ID] })
+	var lines []string
+	for _, member := range members {
+		if len(lines) == predictionShown || wins[member.ID] < 0.005 {
+			break
+		}
+		lines = append(lines, fmt.Sprintf("* %s: %.0f%%", displayName(member), 100*wins[member.ID]))
+	}
+
+	return fmt.Sprintf("\n:crystal_ball: Just for fun, the likeliest winners on local score if the rest of the event goes like everyone's days so far, from %d simulations:\n%s\n", predictionTrials, strings.Join(lines, "\n"))
+}
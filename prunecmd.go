@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func runPruneCommand(args []string) error {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	olderThan := fs.Duration("olderThan", 0, "remove stored data older than this, e.g. 720h for 30 days")
+	dryRun := fs.Bool("dryRun", false, "report what would be removed without removing anything")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *olderThan <= 0 || fs.NArg() > 0 {
+		return errors.New("usage: prune -olderThan <duration> [-dryRun]")
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	cutoff := time.Now().Add(-*olderThan)
+	verb := "Removed"
+	if *dryRun {
+		verb = "Would remove"
+	}
+
+	if history := historyFor(store); history != nil {
+		snaps, listErr := history.Snapshots(partition)
+		if listErr != nil {
+			return listErr
+		}
+
+		var ids []int64
+		for _, snap := range snaps {
+			if snap.FetchedAt.Before(cutoff) {
+				ids = append(ids, snap.ID)
+			}
+		}
+
+		if len(ids) > 0 && !*dryRun {
+			pruner, ok := history.(historyPruner)
+			if !ok {
+				return errors.New("the configured history doesn't support pruning")
+			}
+			if err := pruner.DeleteSnapshots(ids); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("%s %d of %d history snapshots\n", verb, len(ids), len(snaps))
+	}
+
+	// the outbox retries undelivered notifications forever, so anything that's been stuck long enough is dead
+	state, loadErr := store.Load(partition)
+	if loadErr != nil {
+		return loadErr
+	}
+	var kept []outboxEntry
+	for _, entry := range state.Outbox {
+		if entry.Queued > 0 && time.Unix(entry.Queued, 0).Before(cutoff) {
+			logDebug("Dropping undelivered notification", entry.Key, "queued at", time.Unix(entry.Queued, 0).Format(time.RFC3339))
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	if dropped := len(state.Outbox) - len(kept); dropped > 0 && !*dryRun {
+		state.Outbox = kept
+		if err := store.Save(partition, state); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("%s %d undelivered notifications from the outbox\n", verb, len(state.Outbox)-len(kept))
+
+	if len(*recordDirArg) > 0 {
+		count, freed, pruneErr := pruneRecordings(cutoff, *dryRun)
+		if pruneErr != nil {
+			return pruneErr
+		}
+		fmt.Printf("%s %d raw response recordings, freeing %d bytes\n", verb, count, freed)
+	}
+
+	return nil
+}
+
+// pruneRecordings removes the response recordings in recordDir made before cutoff, and returns how many were removed
+// and their total size.
+func pruneRecordings(cutoff time.Time, dryRun bool) (int, int64, error) {
+	entries, readErr := os.ReadDir(*recordDirArg)
+	if errors.Is(readErr, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if readErr != nil {
+		return 0, 0, fmt.Errorf("error reading %s: %w", *recordDirArg, readErr)
+	}
+
+	count := 0
+	var freed int64
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), recordSuffix) {
+			continue
+		}
+		info, infoErr := entry.Info()
+		if infoErr != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+
+		if !dryRun {
+			if err := os.Remove(filepath.Join(*recordDirArg, entry.Name())); err != nil {
+				return count, freed, fmt.Errorf("error removing %s: %w", entry.Name(), err)
+			}
+		}
+		count++
+		freed += info.Size()
+	}
+
+	return count, freed, nil
+}
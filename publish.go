@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+var publishDirArg = flag.String("publishDir", "", "directory to render the dashboard and api into as static files after each scan, for hosting without a server")
+
+// publish renders what the web server would show for the cached leaderboard into dir as static files, and returns how
+// many were written. Paths match the server's, with extensions added so that static hosts serve the right types.
+func (s *server) publish(dir string) (int, error) {
+	leaderboard, state, loadErr := s.cachedLeaderboard()
+	if loadErr != nil {
+		return 0, loadErr
+	}
+	if leaderboard == nil {
+		return 0, errNoData
+	}
+
+	files := map[string][]byte{}
+	addJSON := func(name string, v any) {
+		data, _ := json.MarshalIndent(v, "", "  ")
+		files[name] = append(data, '\n')
+	}
+
+	var page bytes.Buffer
+	if err := s.renderDashboard(&page, leaderboard, state); err != nil {
+		return 0, fmt.Errorf("error rendering dashboard: %w", err)
+	}
+	files["index.html"] = page.Bytes()
+	for _, member := range leaderboard.Members {
+		var memberPage bytes.Buffer
+		if err := s.renderMemberPage(&memberPage, leaderboard, member.ID, state); err != nil {
+			return 0, fmt.Errorf("error rendering page for member %d: %w", member.ID, err)
+		}
+		files[memberPagePath(member.ID)+"index.html"] = memberPage.Bytes()
+	}
+
+	addJSON("api/leaderboard.json", s.apiLeaderboard(leaderboard, time.Unix(state.LastRead, 0)))
+	for name, mode := range scoringModes {
+		addJSON("api/standings/"+name+".json", s.apiStandings(leaderboard, time.Unix(state.LastRead, 0), name, mode))
+	}
+	for _, member := range exportMembers(leaderboard, s.board) {
+		addJSON(fmt.Sprintf("api/members/%d.json", member.ID), member)
+	}
+	for day := 1; day <= exportedDays(leaderboard); day++ {
+		addJSON(fmt.Sprintf("api/days/%d.json", day), s.apiDay(leaderboard, day))
+		addJSON(fmt.Sprintf("api/days/%d/times.json", day), s.apiDayTimes(leaderboard, day))
+		addJSON(fmt.Sprintf("api/days/%d/stats.json", day), newAPIDayStats(computeDayStats(leaderboard, s.partition.Year, day), s.partition.Year, s.board.Location))
+	}
+
+	files["badge/total.svg"] = leaderboardBadge(leaderboard, s.partition.Year)
+	for _, member := range leaderboard.Members {
+		files[fmt.Sprintf("badge/%d.svg", member.ID)] = memberBadge(member, s.partition.Year)
+	}
+	files["heatmap.svg"] = buildHeatmap(leaderboard, s.partition.Year, *heatmapByArg).svg()
+	files["calendar.ics"] = []byte(buildCalendar(s.partition.Year, s.board, time.Now()))
+	for _, id := range configuredLeaderboards() {
+		if id == s.board.ID {
+			continue
+		}
+		board, boardErr := configuredBoard(id)
+		if boardErr != nil {
+			return 0, boardErr
+		}
+		files[fmt.Sprintf("calendar-%s.ics", id)] = []byte(buildCalendar(s.partition.Year, board, time.Now()))
+	}
+
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return 0, fmt.Errorf("error creating %s: %w", filepath.Dir(path), err)
+		}
+		if err := writeFileAtomic(path, contents, 0644); err != nil {
+			return 0, fmt.Errorf("error writing %s: %w", path, err)
+		}
+	}
+
+	return len(files), nil
+}
+
+func runPublishCommand(args []string) error {
+	fs := flag.NewFlagSet("publish", flag.ContinueOnError)
+	out := fs.String("o", *publishDirArg, "the directory to write the site to; defaults to publishDir")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*out) == 0 {
+		*out = "public"
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	board, boardErr := configuredBoard(partition.Leaderboard)
+	if boardErr != nil {
+		return boardErr
+	}
+
+	count, publishErr := (&server{store: store, partition: partition, board: board}).publish(*out)
+	if publishErr != nil {
+		return publishErr
+	}
+
+	fmt.Printf("Wrote %d files to %s\n", count, *out)
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ratingSinceArg   = flag.String("ratingSince", "", "the first event year whose stored leaderboard counts toward members' ratings, to carry them across years; defaults to only the year being scanned")
+	digestRatingsArg = flag.Bool("digestRatings", false, "add members' Elo ratings to the daily and final digests")
+)
+
+const (
+	// initialRating is every member's rating before the first day they finish anything.
+	initialRating = 1500
+	// ratingK is the most a member's rating can move in one day.
+	ratingK = 32
+	// digestRatings is how many of the highest-rated members a digest lists.
+	digestRatings = 10
+)
+
+// dayFinishOrder is the day's result as a race: everyone who finished both parts in the order they finished, then
+// everyone who only finished the first part in the order they did. Members who didn't start the day don't take part.
+func dayFinishOrder(leaderboard *leaderboardData, day int) []int {
+	var order []int
+	finished := map[int]bool{}
+	for _, f := range dayFinishers(leaderboard, day, 2) {
+		order = append(order, f.Member.ID)
+		finished[f.Member.ID] = true
+	}
+	for _, f := range dayFinishers(leaderboard, day, 1) {
+		if !finished[f.Member.ID] {
+			order = append(order, f.Member.ID)
+		}
+	}
+	return order
+}
+
+// rateLeaderboard updates ratings with each day of a leaderboard in turn. Every day is scored as a head-to-head game
+// between each pair of members who took part, won by whoever finished ahead, with K shared out across the games so
+// that a day moves a rating by no more than K however many members played it.
+func rateLeaderboard(ratings map[int]float64, leaderboard *leaderboardData, year string) {
+	for day := 1; day <= eventDays(year); day++ {
+		order := dayFinishOrder(leaderboard, day)
+		if len(order) < 2 {
+			continue
+		}
+		for _, id := range order {
+			if _, ok := ratings[id]; !ok {
+				ratings[id] = initialRating
+			}
+		}
+
+		deltas := make([]float64, len(order))
+		for i, winner := range order {
+			for j := i + 1; j < len(order); j++ {
+				loser := order[j]
+				expected := 1 / (1 + math.Pow(10, (ratings[loser]-ratings[winner])/400))
+				deltas[i] += 1 - expected
+				deltas[j] -= 1 - expected
+			}
+		}
+		share := ratingK / float64(len(order)-1)
+		for i, id := range order {
+			ratings[id] += share * deltas[i]
+		}
+	}
+}
+
+// memberRatings rates the leaderboard's members by how they've finished each day against each other. With
+// ratingSince, the stored leaderboards of the years from then on are rated first, in order, so ratings carry over
+// from one event to the next; years the store has nothing for are skipped. store can be nil to rate only the given
+// leaderboard. Members in excludeMembers aren't rated; every day is rated as if they hadn't played it.
+func memberRatings(store stateStore, partition statePartition, leaderboard *leaderboardData) (map[int]float64, error) {
+	leaderboard = rankedLeaderboard(leaderboard)
+	ratings := map[int]float64{}
+	if len(*ratingSinceArg) > 0 && store != nil {
+		since, sinceErr := strconv.Atoi(*ratingSinceArg)
+		if sinceErr != nil {
+			return nil, fmt.Errorf("invalid ratingSince %q; expected a year", *ratingSinceArg)
+		}
+		if err := eachStoredYear(store, partition, since, func(year string, past *leaderboardData) {
+			rateLeaderboard(ratings, rankedLeaderboard(past), year)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	rateLeaderboard(ratings, leaderboard, partition.Year)
+	for _, member := range leaderboard.Members {
+		if _, ok := ratings[member.ID]; !ok {
+			ratings[member.ID] = initialRating
+		}
+	}
+	return ratings, nil
+}
+
+// eachStoredYear calls fn with the stored leaderboard of each year from since up to the one before the partition's, in
+// order, skipping years the store has nothing for.
+func eachStoredYear(store stateStore, partition statePartition, since int, fn func(year string, past *leaderboardData)) error {
+	current, currentErr := strconv.Atoi(partition.Year)
+	if currentErr != nil {
+		return fmt.Errorf("invalid year %q", partition.Year)
+	}
+
+	for year := since; year < current; year++ {
+		earlier := statePartition{Year: strconv.Itoa(year), Leaderboard: partition.Leaderboard}
+		state, loadErr := store.Load(earlier)
+		if loadErr != nil {
+			return fmt.Errorf("error loading the %d leaderboard: %w", year, loadErr)
+		}
+		if len(state.LastBody) == 0 {
+			logDebug("No stored leaderboard for", year)
+			continue
+		}
+		past, buildErr := buildLeaderboard(state.LastBody)
+		if buildErr != nil {
+			return fmt.Errorf("error building the %d leaderboard: %w", year, buildErr)
+		}
+		fn(earlier.Year, &past)
+	}
+	return nil
+}
+
+// digestRatingsFor is the ratings to put in a digest, or nil if digests don't show them or they can't be computed.
+func digestRatingsFor(store stateStore, partition statePartition, leaderboard *leaderboardData) map[int]float64 {
+	if !*digestRatingsArg {
+		return nil
+	}
+	ratings, err := memberRatings(store, partition, leaderboard)
+	if err != nil {
+		logError("Error rating members for the digest:", err)
+		return nil
+	}
+	return ratings
+}
+
+// ratingsSection lists the highest-rated members for a digest, leaving out anyone without a star or a rating, or is
+// empty without ratings.
+func ratingsSection(leaderboard *leaderboardData, ratings map[int]float64) string {
+	if ratings == nil {
+		return ""
+	}
+
+	var members []memberData
+	for _, member := range leaderboard.Members {
+		if _, rated := ratings[member.ID]; rated && member.Stars > 0 {
+			members = append(members, member)
+		}
+	}
+	if len(members) == 0 {
+		return ""
+	}
+	sort.SliceStable(members, func(i, j int) bool { return ratings[members[i].ID] > ratings[members[j].ID] })
+	if len(members) > digestRatings {
+		members = members[:digestRatings]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\nRatings:\n\n| Rank | Name | Rating |\n| ---: | ---- | -----: |\n")
+	for idx, member := range members {
+		fmt.Fprintf(&sb, "| %d | %s | %.0f |\n", idx+1, displayName(member), ratings[member.ID])
+	}
+	return sb.String()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	recordDirArg  = flag.String("recordDir", "", "debug option: directory to save every raw response from adventofcode.com in, for bug reports")
+	recordKeepArg = flag.Int("recordKeep", 50, "how many of the most recent raw responses to keep in recordDir")
+)
+
+const recordSuffix = ".http"
+
+// recordResponse saves a response's status line, headers, and body to recordDir, then removes the oldest recordings
+// beyond recordKeep. Cookies the server tries to set are redacted so recordings are safe to attach to bug reports.
+func recordResponse(resp *http.Response, body []byte, year, leaderboardID string) {
+	if len(*recordDirArg) == 0 {
+		return
+	}
+
+	if err := os.MkdirAll(*recordDirArg, 0755); err != nil {
+		logError("Error creating response recording directory:", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s\r\n", resp.Proto, resp.Status)
+	header := resp.Header.Clone()
+	if len(header.Values("Set-Cookie")) > 0 {
+		header.Set("Set-Cookie", "<redacted>")
+	}
+	header.Write(&buf)
+	buf.WriteString("\r\n")
+	buf.Write(body)
+
+	path := filepath.Join(*recordDirArg, fmt.Sprintf("%d-%s-%s%s", time.Now().UnixNano(), year, leaderboardID, recordSuffix))
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		logError("Error recording response:", err)
+		return
+	}
+	logDebug("Recorded raw response to", path)
+
+	rotateRecordings()
+}
+
+func rotateRecordings() {
+	entries, readErr := os.ReadDir(*recordDirArg)
+	if readErr != nil {
+		logError("Error reading response recording directory:", readErr)
+		return
+	}
+
+	var recordings []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), recordSuffix) {
+			recordings = append(recordings, entry.Name())
+		}
+	}
+	// names start with a nanosecond timestamp of the same width for the foreseeable future, so this sorts oldest first
+	sort.Strings(recordings)
+
+	for len(recordings) > *recordKeepArg && *recordKeepArg > 0 {
+		if err := os.Remove(filepath.Join(*recordDirArg, recordings[0])); err != nil {
+			logError("Error removing old response recording:", err)
+			return
+		}
+		recordings = recordings[1:]
+	}
+}
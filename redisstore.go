@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRedisPrefix = "aoc-scanner"
+
+// redisStore keeps each piece of state under its own key so that one-shot invocations can share state without a
+// filesystem.
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// newRedisStore takes a redis URL, e.g. redis://:password@host:6379/0, with an optional prefix query parameter that
+// namespaces the keys (default "aoc-scanner").
+func newRedisStore(spec string) (stateStore, error) {
+	u, parseErr := url.Parse(spec)
+	if parseErr != nil {
+		return nil, fmt.Errorf("error parsing redis store %q: %w", spec, parseErr)
+	}
+
+	// go-redis rejects query parameters it doesn't know about, so pull ours out first
+	query := u.Query()
+	prefix := query.Get("prefix")
+	if len(prefix) == 0 {
+		prefix = defaultRedisPrefix
+	}
+	query.Del("prefix")
+	u.RawQuery = query.Encode()
+
+	opts, optsErr := redis.ParseURL(u.String())
+	if optsErr != nil {
+		return nil, fmt.Errorf("error parsing redis store %q: %w", spec, optsErr)
+	}
+
+	return &redisStore{client: redis.NewClient(opts), prefix: prefix}, nil
+}
+
+// key namespaces a key by the store's prefix and, when given, the partition.
+func (s *redisStore) key(p *statePartition, name string) string {
+	if p == nil {
+		return s.prefix + ":" + name
+	}
+
+	return s.prefix + ":" + p.Year + ":" + p.Leaderboard + ":" + name
+}
+
+func (s *redisStore) Load(p statePartition) (scanState, error) {
+	var state scanState
+	values, err := s.client.MGet(context.Background(), s.key(&p, "last_read"), s.key(&p, "last_body"), s.key(&p, "failed_sessions"), s.key(&p, "outbox"), s.key(&p, "etag"), s.key(&p, "last_modified"), s.key(&p, "failures"), s.key(&p, "last_failure")).Result()
+	if err != nil {
+		return state, fmt.Errorf("error reading state from redis: %w", err)
+	}
+
+	if lastRead, ok := values[0].(string); ok {
+		state.LastRead, _ = strconv.ParseInt(lastRead, 10, 64)
+	}
+	if lastBody, ok := values[1].(string); ok {
+		if state.LastBody, err = openBytes([]byte(lastBody)); err != nil {
+			return state, err
+		}
+	}
+	if failedSessions, ok := values[2].(string); ok {
+		if err := json.Unmarshal([]byte(failedSessions), &state.FailedSessions); err != nil {
+			return state, fmt.Errorf("error parsing failed sessions from redis: %w", err)
+		}
+	}
+	if outbox, ok := values[3].(string); ok {
+		data, openErr := openBytes([]byte(outbox))
+		if openErr != nil {
+			return state, openErr
+		}
+		if err := json.Unmarshal(data, &state.Outbox); err != nil {
+			return state, fmt.Errorf("error parsing outbox from redis: %w", err)
+		}
+	}
+
+	state.ETag, _ = values[4].(string)
+	state.LastModified, _ = values[5].(string)
+	if failures, ok := values[6].(string); ok {
+		state.Failures, _ = strconv.Atoi(failures)
+	}
+	state.LastFailure, _ = values[7].(string)
+
+	return state, nil
+}
+
+func (s *redisStore) Save(p statePartition, state scanState) error {
+	failedSessions, _ := json.Marshal(state.FailedSessions)
+	lastBody, sealErr := sealBytes(state.LastBody)
+	if sealErr != nil {
+		return sealErr
+	}
+	outboxJSON, _ := json.Marshal(state.Outbox)
+	outbox, sealErr := sealBytes(outboxJSON)
+	if sealErr != nil {
+		return sealErr
+	}
+
+	_, err := s.client.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		pipe.Set(context.Background(), s.key(&p, "last_read"), state.LastRead, 0)
+		pipe.Set(context.Background(), s.key(&p, "last_body"), lastBody, 0)
+		pipe.Set(context.Background(), s.key(&p, "failed_sessions"), failedSessions, 0)
+		pipe.Set(context.Background(), s.key(&p, "outbox"), outbox, 0)
+		pipe.Set(context.Background(), s.key(&p, "etag"), state.ETag, 0)
+		pipe.Set(context.Background(), s.key(&p, "last_modified"), state.LastModified, 0)
+		pipe.Set(context.Background(), s.key(&p, "failures"), state.Failures, 0)
+		pipe.Set(context.Background(), s.key(&p, "last_failure"), state.LastFailure, 0)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error writing state to redis: %w", err)
+	}
+
+	return nil
+}
+
+func (s *redisStore) HasDelivered(key string) (bool, error) {
+	delivered, err := s.client.HExists(context.Background(), s.key(nil, "delivered"), key).Result()
+	if err != nil {
+		return false, fmt.Errorf("error reading delivery ledger from redis: %w", err)
+	}
+
+	return delivered, nil
+}
+
+func (s *redisStore) MarkDelivered(key string, at time.Time) error {
+	if err := s.client.HSet(context.Background(), s.key(nil, "delivered"), key, at.Unix()).Err(); err != nil {
+		return fmt.Errorf("error recording delivery in redis: %w", err)
+	}
+
+	return nil
+}
+
+func (s *redisStore) Deliveries() (map[string]int64, error) {
+	values, err := s.client.HGetAll(context.Background(), s.key(nil, "delivered")).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error reading delivery ledger from redis: %w", err)
+	}
+
+	deliveries := make(map[string]int64, len(values))
+	for key, value := range values {
+		deliveries[key], _ = strconv.ParseInt(value, 10, 64)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadableOptions can be changed in the config file while the scanner is running. They're the ones that are read
+// each time they're needed rather than once at startup, and that don't interrupt anything by changing: where
+// notifications go, what's worth announcing, and how often to scan. Anything else needs a restart.
+var reloadableOptions = map[string]bool{
+	"webhookURL":             true,
+	"adminWebhookURL":        true,
+	"minStars":               true,
+	"hookCommand":            true,
+	"idleFetchInterval":      true,
+	"burstHours":             true,
+	"lateFetchInterval":      true,
+	"offSeasonFetchInterval": true,
+	"fetchRetries":           true,
+	"fetchRetryDelay":        true,
+	"breakerThreshold":       true,
+	"breakerCooldown":        true,
+	"escalateAfter":          true,
+	"degradedFetchInterval":  true,
+}
+
+// reloadMu keeps a reload from changing options in the middle of a scan, digest, or delivery, which all read them as
+// they go, and from racing anything else that reads them, like readiness checks and live events. The scanner's webhooks
+// are only replaced while it's held too.
+var reloadMu sync.RWMutex
+
+// configSettleTime is how long the config file has to go without changing before it's reloaded, since saving it can
+// take several writes and renames.
+const configSettleTime = 500 * time.Millisecond
+
+// watchConfig reloads the config file whenever it changes or the process gets SIGHUP, until ctx is cancelled. The
+// file's directory is watched rather than the file itself, since editors and Kubernetes config maps replace the file
+// instead of writing to it.
+func (s *scanner) watchConfig(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	path := filepath.Clean(*configArg)
+	var changes <-chan fsnotify.Event
+	var watchErrs <-chan error
+	if watcher, err := fsnotify.NewWatcher(); err != nil {
+		logError("Unable to watch the config file for changes; send SIGHUP to reload it instead:", err)
+	} else if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		logError("Unable to watch the config file for changes; send SIGHUP to reload it instead:", err)
+	} else {
+		defer watcher.Close()
+		changes, watchErrs = watcher.Events, watcher.Errors
+		logDebug("Watching", path, "for changes")
+	}
+
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logInfo("Got SIGHUP; reloading the config file")
+			s.reloadConfig()
+		case event := <-changes:
+			// a config map swaps in its new contents by renaming a ..data link next to the file
+			if filepath.Clean(event.Name) == path || strings.HasPrefix(filepath.Base(event.Name), "..") {
+				settled = time.After(configSettleTime)
+			}
+		case err := <-watchErrs:
+			logError("Error watching the config file:", err)
+		case <-settled:
+			settled = nil
+			s.reloadConfig()
+		}
+	}
+}
+
+// reloadConfig applies what's changed in the config file since it was last read, logging rather than returning what
+// went wrong so that a bad edit leaves the scanner running as it was.
+func (s *scanner) reloadConfig() {
+	applied, err := s.applyConfigChanges()
+	if err != nil {
+		logError("Not reloading the config file:", err)
+		return
+	}
+	if len(applied) > 0 {
+		logInfo("Reloaded", strings.Join(applied, ", "), "from the config file")
+	}
+}
+
+// applyConfigChanges re-reads the config file and sets every reloadable option whose value in it has changed, going
+// back to an option's default if it's been removed. Options given as arguments or environment variables still take
+// precedence, and changes to options that can't be reloaded are only logged. If any changed value is invalid,
+// nothing is applied.
+func (s *scanner) applyConfigChanges() ([]string, error) {
+	values, loadErr := loadConfigFile(*configArg)
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	var names []string
+	for name := range values {
+		if flag.Lookup(name) == nil {
+			return nil, fmt.Errorf("unknown option %q in config file %s", name, *configArg)
+		}
+		names = append(names, name)
+	}
+	for name := range configValues {
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changed []string
+	for _, name := range names {
+		value, inConfig := values[name]
+		if prev, wasInConfig := configValues[name]; inConfig == wasInConfig && value == prev {
+			continue
+		}
+		switch {
+		case optionSources[name] == sourceArgument || optionSources[name] == sourceEnv:
+			logInfo("Ignoring the change to", name, "in the config file, since it's set by", optionSources[name], "which takes precedence")
+		case !reloadableOptions[name]:
+			logWarn("The change to", name, "in the config file won't take effect until the scanner is restarted")
+		default:
+			changed = append(changed, name)
+		}
+	}
+
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	// everything is set first and put back if anything turns out to be invalid, since flags can only be validated by
+	// setting them
+	previous := map[string]string{}
+	restore := func() {
+		for name, value := range previous {
+			flag.Set(name, value)
+		}
+	}
+	var setErr error
+	for _, name := range changed {
+		f := flag.Lookup(name)
+		previous[name] = f.Value.String()
+		value, inConfig := values[name]
+		if !inConfig {
+			value = f.DefValue
+		}
+		if err := f.Value.Set(value); err != nil {
+			setErr = errors.Join(setErr, fmt.Errorf("invalid value %q for %s: %w", value, name, err))
+		}
+	}
+	if setErr != nil {
+		restore()
+		return nil, setErr
+	}
+	if slices.Contains(changed, "webhookURL") || slices.Contains(changed, "adminWebhookURL") {
+		if err := s.setWebhooks(); err != nil {
+			restore()
+			return nil, err
+		}
+	}
+
+	for _, name := range changed {
+		if _, inConfig := values[name]; inConfig {
+			optionSources[name] = sourceConfig
+		} else {
+			optionSources[name] = sourceDefault
+		}
+	}
+	configValues = values
+	return changed, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+func runReplayCommand(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	since := fs.Duration("since", 0, "replay everything since this long ago, e.g. 72h")
+	fromID := fs.Int64("from", 0, "the snapshot id to start replaying from; defaults to the oldest")
+	toID := fs.Int64("to", 0, "the snapshot id to stop replaying at; defaults to the newest")
+	send := fs.Bool("send", false, "send the regenerated notifications that haven't been delivered yet instead of only printing them")
+	yes := fs.Bool("yes", false, "don't ask for confirmation before sending")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 || (*since > 0 && *fromID > 0) {
+		return errors.New("usage: replay [-since <duration> | -from <id>] [-to <id>] [-send [-yes]]")
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	history := historyFor(store)
+	if history == nil {
+		return errors.New("the configured store doesn't keep leaderboard history; use a store that does or set -archiveDir")
+	}
+
+	board, boardErr := configuredBoard(partition.Leaderboard)
+	if boardErr != nil {
+		return boardErr
+	}
+
+	snaps, listErr := history.Snapshots(partition)
+	if listErr != nil {
+		return listErr
+	}
+
+	// the first selected snapshot is the baseline that everything after it is compared against
+	var selected []snapshot
+	cutoff := time.Now().Add(-*since)
+	for idx, snap := range snaps {
+		if *since > 0 && idx+1 < len(snaps) && !snaps[idx+1].FetchedAt.After(cutoff) {
+			continue
+		}
+		if *fromID > 0 && snap.ID < *fromID {
+			continue
+		}
+		if *toID > 0 && snap.ID > *toID {
+			break
+		}
+		selected = append(selected, snap)
+	}
+	if len(selected) < 2 {
+		return fmt.Errorf("need at least two snapshots of %s to replay", partition)
+	}
+
+	var events []outboxEntry
+	_, prev, loadErr := loadSnapshotLeaderboard(history, selected[0].ID)
+	if loadErr != nil {
+		return loadErr
+	}
+	for _, listed := range selected[1:] {
+		_, curr, loadErr := loadSnapshotLeaderboard(history, listed.ID)
+		if loadErr != nil {
+			return loadErr
+		}
+
+		for _, event := range detectEvents(prev, curr, partition.Year, board) {
+			fmt.Printf("[%s] %s\n", listed.FetchedAt.In(board.Location).Format(time.RFC3339), event.Content)
+			events = append(events, event)
+		}
+		prev = curr
+	}
+
+	if len(events) == 0 {
+		fmt.Println("No notifications would have been sent.")
+		return nil
+	}
+	if !*send {
+		fmt.Printf("%d notifications would have been sent. Use -send to deliver the ones that weren't.\n", len(events))
+		return nil
+	}
+
+	s, scannerErr := newScanner(store)
+	if scannerErr != nil {
+		return scannerErr
+	}
+	if !*yes && !confirm(fmt.Sprintf("Send the notifications above that haven't been delivered yet to %s?", webhookHost(*webhookURLArg))) {
+		return errors.New("cancelled")
+	}
+
+	state, stateErr := store.Load(partition)
+	if stateErr != nil {
+		return stateErr
+	}
+	var saveErr error
+	save := func(state scanState) {
+		if err := store.Save(partition, state); err != nil {
+			saveErr = err
+		}
+	}
+
+	// going through the outbox means anything the ledger already has is skipped, and an interrupted replay can be
+	// picked up by the next scan
+	state.Enqueue(events)
+	save(state)
+	s.flushOutbox(context.Background(), &state, ledgerFor(store, &state, save), save)
+	if saveErr != nil {
+		return saveErr
+	}
+	if len(state.Outbox) > 0 {
+		return fmt.Errorf("%d notifications couldn't be delivered and will be retried on the next scan", len(state.Outbox))
+	}
+
+	return nil
+}
+
+// stdinReader is shared by everything that prompts, so that input buffered for one prompt isn't lost to the next.
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on the terminal and reports whether the answer was yes.
+func confirm(question string) bool {
+	fmt.Print(question, " [y/N] ")
+	answer, _ := stdinReader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	historyKeepDaysArg      = flag.Int("historyKeepDays", 0, "prune leaderboard history snapshots older than this many days; 0 keeps them forever")
+	historyKeepSnapshotsArg = flag.Int("historyKeepSnapshots", 0, "prune all but this many of the most recent leaderboard history snapshots; 0 keeps them all")
+	historyEventOnlyArg     = flag.Bool("historyEventOnly", false, "prune leaderboard history snapshots taken outside of December of the event year")
+)
+
+// historyPruner is implemented by historyStores that can delete snapshots.
+type historyPruner interface {
+	DeleteSnapshots(ids []int64) error
+}
+
+func retentionEnabled() bool {
+	return *historyKeepDaysArg > 0 || *historyKeepSnapshotsArg > 0 || *historyEventOnlyArg
+}
+
+// snapshotsToPrune applies the configured retention policy to snaps (oldest first) and returns the IDs to delete.
+func snapshotsToPrune(snaps []snapshot, now time.Time) []int64 {
+	var kept []snapshot
+	var pruned []int64
+	for _, snap := range snaps {
+		tooOld := *historyKeepDaysArg > 0 && now.Sub(snap.FetchedAt) > time.Duration(*historyKeepDaysArg)*24*time.Hour
+		outsideEvent := *historyEventOnlyArg && (snap.FetchedAt.UTC().Month() != time.December || strconv.Itoa(snap.FetchedAt.UTC().Year()) != snap.Year)
+		if tooOld || outsideEvent {
+			pruned = append(pruned, snap.ID)
+			continue
+		}
+		kept = append(kept, snap)
+	}
+
+	if *historyKeepSnapshotsArg > 0 && len(kept) > *historyKeepSnapshotsArg {
+		for _, snap := range kept[:len(kept)-*historyKeepSnapshotsArg] {
+			pruned = append(pruned, snap.ID)
+		}
+	}
+
+	return pruned
+}
+
+// pruneHistory deletes the snapshots in a partition's history that fall outside the retention policy and returns
+// how many were removed.
+func pruneHistory(history historyStore, p statePartition, now time.Time) (int, error) {
+	if !retentionEnabled() {
+		return 0, nil
+	}
+
+	pruner, ok := history.(historyPruner)
+	if !ok {
+		return 0, fmt.Errorf("the configured history doesn't support pruning")
+	}
+
+	snaps, listErr := history.Snapshots(p)
+	if listErr != nil {
+		return 0, listErr
+	}
+
+	ids := snapshotsToPrune(snaps, now)
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := pruner.DeleteSnapshots(ids); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+func (a *archiveHistory) DeleteSnapshots(ids []int64) error {
+	for _, id := range ids {
+		for _, match := range a.snapshotFiles(id) {
+			if err := os.Remove(match); err != nil {
+				return fmt.Errorf("error removing archive file %s: %w", match, err)
+			}
+		}
+	}
+
+	return nil
+}
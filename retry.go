@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/aocclient"
+)
+
+var (
+	fetchRetriesArg    = flag.Int("fetchRetries", 3, "how many times a scan retries a download that failed with a timeout or server error before giving up until the next scan")
+	fetchRetryDelayArg = flag.Duration("fetchRetryDelay", 10*time.Second, "how long to wait before the first retry of a failed download; each retry after that waits twice as long")
+)
+
+// maxFetchRetryDelay caps the time between retries, so that they all happen well within one scan's interval.
+const maxFetchRetryDelay = 2 * time.Minute
+
+// isTransientDownloadError reports whether a failed download is worth retrying right away: the site was briefly
+// unreachable, too slow, or had a problem of its own. Rejected sessions and other answers from the site won't change
+// by asking again.
+func isTransientDownloadError(err error) bool {
+	var statusErr aocclient.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// fetchRetryDelay is how long to wait before the given retry, counting from 0.
+func fetchRetryDelay(retry int) time.Duration {
+	delay := *fetchRetryDelayArg
+	for i := 0; i < retry && delay < maxFetchRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxFetchRetryDelay)
+}
@@ -0,0 +1,256 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: scanner.proto
+
+package scannerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Scanner_GetStandings_FullMethodName = "/aoc.scanner.v1.Scanner/GetStandings"
+	Scanner_GetMember_FullMethodName    = "/aoc.scanner.v1.Scanner/GetMember"
+	Scanner_GetDay_FullMethodName       = "/aoc.scanner.v1.Scanner/GetDay"
+	Scanner_StreamEvents_FullMethodName = "/aoc.scanner.v1.Scanner/StreamEvents"
+)
+
+// ScannerClient is the client API for Scanner service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ScannerClient interface {
+	// GetStandings returns every member of the leaderboard in standings order.
+	GetStandings(ctx context.Context, in *GetStandingsRequest, opts ...grpc.CallOption) (*Standings, error)
+	// GetMember returns a single member of the leaderboard.
+	GetMember(ctx context.Context, in *GetMemberRequest, opts ...grpc.CallOption) (*Member, error)
+	// GetDay returns everyone who has finished each part of a day, fastest first.
+	GetDay(ctx context.Context, in *GetDayRequest, opts ...grpc.CallOption) (*Day, error)
+	// StreamEvents sends each join, star, and change in the standings as scans find them, until the client hangs up.
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Scanner_StreamEventsClient, error)
+}
+
+type scannerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewScannerClient(cc grpc.ClientConnInterface) ScannerClient {
+	return &scannerClient{cc}
+}
+
+func (c *scannerClient) GetStandings(ctx context.Context, in *GetStandingsRequest, opts ...grpc.CallOption) (*Standings, error) {
+	out := new(Standings)
+	err := c.cc.Invoke(ctx, Scanner_GetStandings_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scannerClient) GetMember(ctx context.Context, in *GetMemberRequest, opts ...grpc.CallOption) (*Member, error) {
+	out := new(Member)
+	err := c.cc.Invoke(ctx, Scanner_GetMember_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scannerClient) GetDay(ctx context.Context, in *GetDayRequest, opts ...grpc.CallOption) (*Day, error) {
+	out := new(Day)
+	err := c.cc.Invoke(ctx, Scanner_GetDay_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scannerClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Scanner_StreamEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Scanner_ServiceDesc.Streams[0], Scanner_StreamEvents_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &scannerStreamEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Scanner_StreamEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type scannerStreamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *scannerStreamEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ScannerServer is the server API for Scanner service.
+// All implementations must embed UnimplementedScannerServer
+// for forward compatibility
+type ScannerServer interface {
+	// GetStandings returns every member of the leaderboard in standings order.
+	GetStandings(context.Context, *GetStandingsRequest) (*Standings, error)
+	// GetMember returns a single member of the leaderboard.
+	GetMember(context.Context, *GetMemberRequest) (*Member, error)
+	// GetDay returns everyone who has finished each part of a day, fastest first.
+	GetDay(context.Context, *GetDayRequest) (*Day, error)
+	// StreamEvents sends each join, star, and change in the standings as scans find them, until the client hangs up.
+	StreamEvents(*StreamEventsRequest, Scanner_StreamEventsServer) error
+	mustEmbedUnimplementedScannerServer()
+}
+
+// UnimplementedScannerServer must be embedded to have forward compatible implementations.
+type UnimplementedScannerServer struct {
+}
+
+func (UnimplementedScannerServer) GetStandings(context.Context, *GetStandingsRequest) (*Standings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStandings not implemented")
+}
+func (UnimplementedScannerServer) GetMember(context.Context, *GetMemberRequest) (*Member, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMember not implemented")
+}
+func (UnimplementedScannerServer) GetDay(context.Context, *GetDayRequest) (*Day, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDay not implemented")
+}
+func (UnimplementedScannerServer) StreamEvents(*StreamEventsRequest, Scanner_StreamEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedScannerServer) mustEmbedUnimplementedScannerServer() {}
+
+// UnsafeScannerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ScannerServer will
+// result in compilation errors.
+type UnsafeScannerServer interface {
+	mustEmbedUnimplementedScannerServer()
+}
+
+func RegisterScannerServer(s grpc.ServiceRegistrar, srv ScannerServer) {
+	s.RegisterService(&Scanner_ServiceDesc, srv)
+}
+
+func _Scanner_GetStandings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStandingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScannerServer).GetStandings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scanner_GetStandings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScannerServer).GetStandings(ctx, req.(*GetStandingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scanner_GetMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScannerServer).GetMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scanner_GetMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScannerServer).GetMember(ctx, req.(*GetMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scanner_GetDay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScannerServer).GetDay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Scanner_GetDay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScannerServer).GetDay(ctx, req.(*GetDayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Scanner_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ScannerServer).StreamEvents(m, &scannerStreamEventsServer{stream})
+}
+
+type Scanner_StreamEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type scannerStreamEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *scannerStreamEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Scanner_ServiceDesc is the grpc.ServiceDesc for Scanner service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Scanner_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "aoc.scanner.v1.Scanner",
+	HandlerType: (*ScannerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStandings",
+			Handler:    _Scanner_GetStandings_Handler,
+		},
+		{
+			MethodName: "GetMember",
+			Handler:    _Scanner_GetMember_Handler,
+		},
+		{
+			MethodName: "GetDay",
+			Handler:    _Scanner_GetDay_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _Scanner_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "scanner.proto",
+}
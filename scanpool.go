@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	scanWorkersArg       = flag.Int("scanWorkers", 4, "how many leaderboards to scan at once when several are configured")
+	aocRequestSpacingArg = flag.Duration("aocRequestSpacing", 2*time.Second, "the least time between the start of any two leaderboard downloads, however many leaderboards are being scanned")
+)
+
+// configuredLeaderboards returns the IDs in -leaderboard, which can list several separated by commas. Commands that
+// work with a single leaderboard use the first.
+func configuredLeaderboards() []string {
+	var ids []string
+	for _, entry := range strings.Split(*leaderboardArg, ",") {
+		if id, _, _ := strings.Cut(strings.TrimSpace(entry), "@"); len(id) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// configuredBoard returns the settings of a leaderboard. An entry in -leaderboard can give the board its own timezone
+// and digest time as id@timezone@HH:MM, e.g. 1234567@Europe/Berlin@09:00; either can be left out or empty to use
+// timezone and digestTime.
+func configuredBoard(id string) (leaderboardSettings, error) {
+	timezone, digestTime := *timezoneArg, *digestTimeArg
+	for _, entry := range strings.Split(*leaderboardArg, ",") {
+		entryID, overrides, _ := strings.Cut(strings.TrimSpace(entry), "@")
+		if entryID != id {
+			continue
+		}
+		entryTimezone, entryDigestTime, _ := strings.Cut(overrides, "@")
+		if len(entryTimezone) > 0 {
+			timezone = entryTimezone
+		}
+		if len(entryDigestTime) > 0 {
+			digestTime = entryDigestTime
+		}
+		break
+	}
+
+	return newLeaderboardSettings(id, timezone, digestTime)
+}
+
+// primaryLeaderboard is the first configured leaderboard, or "" if there isn't one.
+func primaryLeaderboard() string {
+	if ids := configuredLeaderboards(); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+// scanResult is how one board's scan went.
+type scanResult struct {
+	board  string
+	result string
+	err    error
+}
+
+// scanAll scans every board, no more than scanWorkers at a time, and returns how each went in the same order.
+func scanAll(scanners []*boardScanner) []scanResult {
+	results := make([]scanResult, len(scanners))
+	workers := make(chan struct{}, max(*scanWorkersArg, 1))
+	var wg sync.WaitGroup
+	for idx, scanner := range scanners {
+		workers <- struct{}{}
+		wg.Add(1)
+		go func(idx int, scanner *boardScanner) {
+			defer wg.Done()
+			defer func() { <-workers }()
+			result, err := scanner.refresh()
+			results[idx] = scanResult{board: scanner.board.ID, result: result, err: err}
+		}(idx, scanner)
+	}
+	wg.Wait()
+	return results
+}
+
+// requestSpacer spaces out requests so that scanning many leaderboards at once doesn't send the site a burst of them.
+type requestSpacer struct {
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until it's been at least spacing since the last request it allowed, or until ctx is cancelled.
+func (r *requestSpacer) wait(ctx context.Context, spacing time.Duration) error {
+	r.mu.Lock()
+	at := r.next
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	r.next = at.Add(spacing)
+	r.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// aocRequests spaces out every leaderboard download, since they all go to the same site, whichever scanner they're for.
+var aocRequests requestSpacer
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	idleFetchIntervalArg      = flag.Duration("idleFetchInterval", 0, "minimum time between leaderboard downloads outside of the burst window after each puzzle unlocks; 0 always uses minFetchInterval")
+	burstHoursArg             = flag.Int("burstHours", 6, "how many hours after each puzzle unlocks to download as often as minFetchInterval allows, when idleFetchInterval is set")
+	lateFetchIntervalArg      = flag.Duration("lateFetchInterval", time.Hour, "minimum time between leaderboard downloads in the January after the event, while stragglers finish up")
+	offSeasonFetchIntervalArg = flag.Duration("offSeasonFetchInterval", 24*time.Hour, "minimum time between leaderboard downloads the rest of the year; 0 stops scanning outside of December and January")
+)
+
+// season is where a time falls in an event's calendar.
+type season int
+
+const (
+	// seasonOff is any time before the event's December or after the January that follows it.
+	seasonOff season = iota
+	// seasonEvent is the event's December, from the first puzzle's unlock.
+	seasonEvent
+	// seasonLate is the January after the event, when stragglers are still finishing.
+	seasonLate
+)
+
+// eventSeason returns where now falls in the given event's calendar, whose months start and end at midnight US
+// Eastern like the puzzles do.
+func eventSeason(year string, now time.Time) season {
+	start := dayUnlock(year, 1)
+	end := start.AddDate(0, 1, 0)
+	switch {
+	case now.Before(start):
+		return seasonOff
+	case now.Before(end):
+		return seasonEvent
+	case now.Before(end.AddDate(0, 1, 0)):
+		return seasonLate
+	default:
+		return seasonOff
+	}
+}
+
+// scanningPaused reports whether scheduled scans don't happen at all at the given time.
+func scanningPaused(year string, now time.Time) bool {
+	return eventSeason(year, now) == seasonOff && *offSeasonFetchIntervalArg <= 0
+}
+
+// inBurstWindow reports whether now is within burstHours of a puzzle unlocking for the given event.
+func inBurstWindow(year string, now time.Time) bool {
+	window := time.Duration(*burstHoursArg) * time.Hour
+	for day := 1; day <= eventDays(year); day++ {
+		unlock := dayUnlock(year, day)
+		if !now.Before(unlock) && now.Before(unlock.Add(window)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fetchInterval is how long to wait between leaderboard downloads at the given time. Right after a puzzle unlocks is
+// when stars come in, so that's when scans happen as often as allowed; otherwise they can relax to the idle interval.
+// Outside of December, when stars are rare, scans slow down further, and when scanning is paused the interval is
+// effectively forever.
+func fetchInterval(year string, now time.Time) time.Duration {
+	switch eventSeason(year, now) {
+	case seasonLate:
+		return max(*lateFetchIntervalArg, *idleFetchIntervalArg, *minFetchIntervalArg)
+	case seasonOff:
+		if scanningPaused(year, now) {
+			return math.MaxInt64
+		}
+		return max(*offSeasonFetchIntervalArg, *idleFetchIntervalArg, *minFetchIntervalArg)
+	}
+
+	if *idleFetchIntervalArg <= *minFetchIntervalArg || inBurstWindow(year, now) {
+		return *minFetchIntervalArg
+	}
+
+	return *idleFetchIntervalArg
+}
+
+// nextScanTick returns the first scheduled scan after now. Scans happen every scanTick counted from the moment the
+// event's puzzles unlock, so that one always lands right on an unlock whatever the local timezone is.
+func nextScanTick(year string, now time.Time) time.Time {
+	anchor := dayUnlock(year, 1)
+	next := anchor.Add(now.Sub(anchor) / scanTick * scanTick)
+	for !next.After(now) {
+		next = next.Add(scanTick)
+	}
+	return next
+}
+
+// maxTickerSleep is the longest a scanTicker sleeps before checking the clock again.
+const maxTickerSleep = time.Minute
+
+// scanTicker runs scans on every scanTick until it's stopped. Timers don't count time a machine spends asleep and can
+// drift from the wall clock, so rather than trusting one to fire on time, it never sleeps longer than maxTickerSleep
+// and checks the clock each time it wakes. A scan still running when the next tick comes, or a wakeup after several
+// missed ticks, results in one scan rather than one for every tick that went by.
+type scanTicker struct {
+	stop chan struct{}
+	done chan struct{}
+	// checkIn is the unix time, in nanoseconds, the ticker last woke up or finished a scan.
+	checkIn atomic.Int64
+}
+
+func startScanTicker(year string, scan func()) *scanTicker {
+	t := &scanTicker{stop: make(chan struct{}), done: make(chan struct{})}
+	t.checkIn.Store(time.Now().UnixNano())
+	go t.run(year, scan)
+	return t
+}
+
+// lastCheckIn is when the ticker was last known not to be stuck.
+func (t *scanTicker) lastCheckIn() time.Time {
+	return time.Unix(0, t.checkIn.Load())
+}
+
+func (t *scanTicker) run(year string, scan func()) {
+	defer close(t.done)
+
+	next := nextScanTick(year, time.Now())
+	for {
+		t.checkIn.Store(time.Now().UnixNano())
+		timer := time.NewTimer(min(time.Until(next), maxTickerSleep))
+		select {
+		case <-t.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		if now.Before(next) {
+			continue
+		}
+		if missed := int(now.Sub(next) / scanTick); missed > 0 {
+			logInfo("Missed", missed, "scheduled scans, probably while the machine was asleep; scanning once now")
+		}
+		scan()
+		next = nextScanTick(year, time.Now())
+	}
+}
+
+// Stop stops the ticker, and returns a channel that's closed once a scan it started has finished.
+func (t *scanTicker) Stop() <-chan struct{} {
+	close(t.stop)
+	return t.done
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goccy/go-json"
+)
+
+func scoringModeNames() []string {
+	var names []string
+	for name := range scoringModes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func runScoreCommand(args []string) error {
+	fs := flag.NewFlagSet("score", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	modeName := fs.String("mode", *scoringArg, "the scoring mode: "+strings.Join(scoringModeNames(), ", "))
+	format := fs.String("format", "table", "output format: table, csv, or json")
+	out := fs.String("o", "", "file to write to instead of stdout")
+	list := fs.Bool("list", false, "list the scoring modes and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *list {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, name := range scoringModeNames() {
+			fmt.Fprintf(w, "%s\t%s\n", name, scoringModes[name].description)
+		}
+		return w.Flush()
+	}
+
+	mode, ok := scoringModes[*modeName]
+	if !ok {
+		return fmt.Errorf("unknown scoring mode %q; expected one of %s", *modeName, strings.Join(scoringModeNames(), ", "))
+	}
+
+	var write func(io.Writer, []scoredMember) error
+	switch *format {
+	case "table":
+		write = func(w io.Writer, standings []scoredMember) error { return writeScoresTable(w, standings, mode) }
+	case "csv":
+		write = writeScoresCSV
+	case "json":
+		write = func(w io.Writer, standings []scoredMember) error {
+			data, _ := json.MarshalIndent(standings, "", "  ")
+			_, err := fmt.Fprintln(w, string(data))
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown score format %q; expected table, csv, or json", *format)
+	}
+
+	leaderboard, _, loadErr := loadLeaderboard(*cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+	standings := scoreStandings(leaderboard, *yearArg, mode)
+
+	if len(*out) == 0 {
+		return write(os.Stdout, standings)
+	}
+
+	f, createErr := os.Create(*out)
+	if createErr != nil {
+		return fmt.Errorf("error creating %s: %w", *out, createErr)
+	}
+	if err := write(f, standings); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func writeScoresTable(w io.Writer, standings []scoredMember, mode scoringMode) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Rank\tName\tStars\tScore")
+	for _, member := range standings {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\n", member.Rank, member.Name, member.Stars, mode.formatScore(member.Score))
+	}
+	return tw.Flush()
+}
+
+func writeScoresCSV(w io.Writer, standings []scoredMember) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"rank", "id", "name", "stars", "score"})
+	for _, member := range standings {
+		cw.Write([]string{strconv.Itoa(member.Rank), strconv.Itoa(member.ID), member.Name, strconv.Itoa(member.Stars), strconv.Itoa(member.Score)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"flag"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var scoringArg = flag.String("scoring", "local", "the scoring mode that ranks members in digests, rank changes, and chat replies; see the score command's -list")
+
+// unlockScoringHours is how long after a puzzle unlocks its stars are worth more than a single point under the unlock
+// scoring mode.
+const unlockScoringHours = 24
+
+// scoredMember is a member's standing under one of the scoring modes.
+type scoredMember struct {
+	Rank  int    `json:"rank"`
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Stars int    `json:"stars"`
+	Score int    `json:"score"`
+}
+
+// scoringMode is a way of scoring a leaderboard other than trusting the local scores the site reports. Scores are keyed
+// by member ID, and higher is better unless lowerIsBetter.
+type scoringMode struct {
+	description string
+	score       func(leaderboard *leaderboardData, year string) map[int]int
+	// lowerIsBetter modes rank members by their stars before their scores, since a member with fewer stars has had
+	// less to add to their score.
+	lowerIsBetter bool
+	// format writes a score the way it's shown beside a rank, or is nil to show it as a number.
+	format func(score int) string
+}
+
+// formatScore writes a score under the mode the way it's shown beside a rank.
+func (m scoringMode) formatScore(score int) string {
+	if m.format == nil {
+		return strconv.Itoa(score)
+	}
+	return m.format(score)
+}
+
+// formatScore writes a score under the scoring mode set by scoring.
+func formatScore(score int) string {
+	return scoringModes[*scoringArg].formatScore(score)
+}
+
+// scoreText is formatScore for a chat reply, where a bare number needs saying what it is.
+func scoreText(score int) string {
+	if scoringModes[*scoringArg].format == nil {
+		return formatScore(score) + " pts"
+	}
+	return formatScore(score)
+}
+
+var scoringModes = map[string]scoringMode{
+	"local": {
+		description: "the site's local score, recomputed from the completion times: each star is worth one point for every member who didn't get it first",
+		score: func(leaderboard *leaderboardData, year string) map[int]int {
+			return computeLocalScores(leaderboard, eventDays(year))
+		},
+	},
+	"unlock": {
+		description: "points for how soon after its puzzle unlocked each star came rather than who got it first: a point for every hour left in the first day after unlock, and one point for a star after that, so members asleep at unlock lose a few points instead of nearly all of them",
+		score:       unlockScores,
+	},
+	"stars": {
+		description: "one point per star, so only how much has been solved counts and not how fast",
+		score: func(leaderboard *leaderboardData, year string) map[int]int {
+			scores := map[int]int{}
+			for _, member := range leaderboard.Members {
+				scores[member.ID] = member.Stars
+			}
+			return scores
+		},
+	},
+	"delta": {
+		description:   "the total time between getting each day's first and second star, lowest first, among members with the same number of stars; days with only the first star don't add to it",
+		score:         deltaScores,
+		lowerIsBetter: true,
+		format: func(score int) string {
+			return formatElapsed(time.Duration(score) * time.Second)
+		},
+	},
+}
+
+// deltaScores adds up how many seconds each member took to go from the first star to the second on each day they have
+// both for.
+func deltaScores(leaderboard *leaderboardData, year string) map[int]int {
+	scores := map[int]int{}
+	for _, member := range leaderboard.Members {
+		scores[member.ID] = 0
+		for dayIdx, day := range member.CompletionDayLevel {
+			if dayIdx >= eventDays(year) || day.Part1 == nil || day.Part2 == nil {
+				continue
+			}
+			scores[member.ID] += int(max(day.Part2.GotStarAt-day.Part1.GotStarAt, 0))
+		}
+	}
+	return scores
+}
+
+// unlockScores scores each star by how many hours after its puzzle unlocked it was earned.
+func unlockScores(leaderboard *leaderboardData, year string) map[int]int {
+	scores := map[int]int{}
+	for _, member := range leaderboard.Members {
+		scores[member.ID] = 0
+		for dayIdx, day := range member.CompletionDayLevel {
+			unlock := dayUnlock(year, dayIdx+1)
+			for _, part := range []*completionPartData{day.Part1, day.Part2} {
+				if part == nil {
+					continue
+				}
+				// a clock that's off can put a star a moment before its unlock, which is as soon as it gets
+				hours := max(int(time.Unix(part.GotStarAt, 0).Sub(unlock)/time.Hour), 0)
+				scores[member.ID] += max(unlockScoringHours-hours, 1)
+			}
+		}
+	}
+	return scores
+}
+
+// rankMembers orders the leaderboard's members by the given scores under mode. Members with equal scores are ordered by
+// stars and then by who got their last star first, except that stars come first for modes where lower is better. The
+// returned less reports whether one member ranks above another.
+func rankMembers(leaderboard *leaderboardData, scores map[int]int, mode scoringMode) ([]memberData, func(a, b memberData) bool) {
+	members := make([]memberData, len(leaderboard.Members))
+	copy(members, leaderboard.Members)
+	less := func(a, b memberData) bool {
+		if mode.lowerIsBetter && a.Stars != b.Stars {
+			return a.Stars > b.Stars
+		}
+		if scores[a.ID] != scores[b.ID] {
+			return (scores[a.ID] > scores[b.ID]) != mode.lowerIsBetter
+		}
+		if a.Stars != b.Stars {
+			return a.Stars > b.Stars
+		}
+		return a.LastStarTimestamp < b.LastStarTimestamp
+	}
+	sort.SliceStable(members, func(i, j int) bool { return less(members[i], members[j]) })
+	return members, less
+}
+
+// rankedStandings is sortedStandings under the scoring mode set by scoring and without the members in excludeMembers,
+// with each member's LocalScore replaced by their score under it so that the score shown beside a rank is the one it
+// came from. The site's own ranking is used as it is for the local mode when nobody is excluded.
+func rankedStandings(leaderboard *leaderboardData, year string) []memberData {
+	ranked := rankedLeaderboard(leaderboard)
+	if *scoringArg == "local" && ranked == leaderboard {
+		return sortedStandings(leaderboard)
+	}
+	leaderboard = ranked
+
+	mode := scoringModes[*scoringArg]
+	scores := mode.score(leaderboard, year)
+	members, _ := rankMembers(leaderboard, scores, mode)
+	for idx := range members {
+		members[idx].LocalScore = scores[members[idx].ID]
+	}
+	return members
+}
+
+// scoreStandings ranks the leaderboard under the given scoring mode, with members sharing a rank only if their score,
+// stars, and last star time are all equal. Members in excludeMembers are left out.
+func scoreStandings(leaderboard *leaderboardData, year string, mode scoringMode) []scoredMember {
+	leaderboard = rankedLeaderboard(leaderboard)
+	scores := mode.score(leaderboard, year)
+	members, less := rankMembers(leaderboard, scores, mode)
+
+	standings := make([]scoredMember, 0, len(members))
+	for idx, member := range members {
+		rank := idx + 1
+		if idx > 0 && !less(members[idx-1], member) {
+			rank = standings[idx-1].Rank
+		}
+		standings = append(standings, scoredMember{
+			Rank:  rank,
+			ID:    member.ID,
+			Name:  displayName(member),
+			Stars: member.Stars,
+			Score: scores[member.ID],
+		})
+	}
+
+	return standings
+}
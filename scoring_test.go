@@ -0,0 +1,182 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"pernicious.games/advent-of-code-scanner/leaderboard/leaderboardtest"
+)
+
+// day1 and day2 are when the first two puzzles of 2023 unlocked.
+const (
+	day1 = 1701406800
+	day2 = day1 + 24*60*60
+)
+
+// scoringLeaderboard is three members who each come out ahead under a different mode: Ada got the most stars and the
+// most points, Bea was first to the first star, and Cy went from the first star to the second fastest.
+func scoringLeaderboard(t *testing.T) *leaderboardData {
+	t.Helper()
+	return leaderboardtest.New(t, "2023",
+		leaderboardtest.Member{ID: 1, Name: "Ada", LocalScore: 8, Days: map[int][2]int64{1: {day1 + 600, day1 + 1200}, 2: {day2 + 30*60*60, 0}}},
+		leaderboardtest.Member{ID: 2, Name: "Bea", LocalScore: 5, Days: map[int][2]int64{1: {day1 + 300, day1 + 4000}}},
+		leaderboardtest.Member{ID: 3, Name: "Cy", LocalScore: 2, Days: map[int][2]int64{1: {day1 + 7200, day1 + 7300}}},
+	)
+}
+
+// setFlag sets a flag for the rest of a test.
+func setFlag(t *testing.T, arg *string, value string) {
+	t.Helper()
+	old := *arg
+	*arg = value
+	t.Cleanup(func() { *arg = old })
+}
+
+func TestScoreStandings(t *testing.T) {
+	tests := []struct {
+		mode string
+		want []scoredMember
+	}{
+		{"local", []scoredMember{{1, 1, "Ada", 3, 8}, {2, 2, "Bea", 2, 5}, {3, 3, "Cy", 2, 2}}},
+		// a star 30 hours after its unlock is still worth a point
+		{"unlock", []scoredMember{{1, 1, "Ada", 3, 49}, {2, 2, "Bea", 2, 47}, {3, 3, "Cy", 2, 44}}},
+		// Bea and Cy tie on stars, so whoever got there first ranks higher
+		{"stars", []scoredMember{{1, 1, "Ada", 3, 3}, {2, 2, "Bea", 2, 2}, {3, 3, "Cy", 2, 2}}},
+		// lower is better, but only among members with as many stars
+		{"delta", []scoredMember{{1, 1, "Ada", 3, 600}, {2, 3, "Cy", 2, 100}, {3, 2, "Bea", 2, 3700}}},
+	}
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			got := scoreStandings(scoringLeaderboard(t), "2023", scoringModes[test.mode])
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScoreStandingsSharesRanks(t *testing.T) {
+	leaderboard := leaderboardtest.New(t, "2023",
+		leaderboardtest.Member{ID: 1, Name: "Ada", Days: map[int][2]int64{1: {day1 + 600, 0}}},
+		leaderboardtest.Member{ID: 2, Name: "Bea", Days: map[int][2]int64{1: {day1 + 600, 0}}},
+		leaderboardtest.Member{ID: 3, Name: "Cy"},
+	)
+	want := []scoredMember{{1, 1, "Ada", 1, 1}, {1, 2, "Bea", 1, 1}, {3, 3, "Cy", 0, 0}}
+	if got := scoreStandings(leaderboard, "2023", scoringModes["stars"]); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestListedStandings(t *testing.T) {
+	tests := []struct {
+		mode string
+		// want is each member's ID, rank, and the score shown beside it
+		want [][3]any
+	}{
+		// the site's own ranking and scores are used as they are
+		{"local", [][3]any{{1, "1", "8"}, {2, "2", "5"}, {3, "3", "2"}}},
+		{"unlock", [][3]any{{1, "1", "49"}, {2, "2", "47"}, {3, "3", "44"}}},
+		{"delta", [][3]any{{1, "1", "0:10:00"}, {3, "2", "0:01:40"}, {2, "3", "1:01:40"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			setFlag(t, scoringArg, test.mode)
+			var got [][3]any
+			for _, s := range listedStandings(scoringLeaderboard(t), "2023") {
+				got = append(got, [3]any{s.ID, rankText(s.Rank), listedScoreText(s)})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScoreStandingsSkipsExcludedMembers(t *testing.T) {
+	tests := []struct {
+		mode     string
+		excluded string
+		want     []scoredMember
+	}{
+		// nobody loses points to an excluded member having beaten them to a star
+		{"local", "1", []scoredMember{{1, 2, "Bea", 2, 4}, {2, 3, "Cy", 2, 2}}},
+		{"unlock", "1, 3", []scoredMember{{1, 2, "Bea", 2, 47}}},
+		{"delta", "2", []scoredMember{{1, 1, "Ada", 3, 600}, {2, 3, "Cy", 2, 100}}},
+		{"local", "1,2,3", []scoredMember{}},
+		// IDs that aren't on the leaderboard, or aren't IDs, change nothing
+		{"local", "99,ada", []scoredMember{{1, 1, "Ada", 3, 8}, {2, 2, "Bea", 2, 5}, {3, 3, "Cy", 2, 2}}},
+	}
+	for _, test := range tests {
+		t.Run(test.mode+" excluding "+test.excluded, func(t *testing.T) {
+			setFlag(t, excludeMembersArg, test.excluded)
+			got := scoreStandings(scoringLeaderboard(t), "2023", scoringModes[test.mode])
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestListedStandingsListsExcludedMembersLast(t *testing.T) {
+	tests := []struct {
+		mode     string
+		excluded string
+		// want is each member's ID, rank, and the score shown beside it
+		want [][3]any
+	}{
+		{"local", "1", [][3]any{{2, "1", "4"}, {3, "2", "2"}, {1, "-", "-"}}},
+		{"stars", "2", [][3]any{{1, "1", "3"}, {3, "2", "2"}, {2, "-", "-"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.mode+" excluding "+test.excluded, func(t *testing.T) {
+			setFlag(t, scoringArg, test.mode)
+			setFlag(t, excludeMembersArg, test.excluded)
+			var got [][3]any
+			for _, s := range listedStandings(scoringLeaderboard(t), "2023") {
+				got = append(got, [3]any{s.ID, rankText(s.Rank), listedScoreText(s)})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCompletionRankSkipsExcludedMembers(t *testing.T) {
+	setFlag(t, excludeMembersArg, "2")
+	leaderboard := scoringLeaderboard(t)
+	tests := []struct {
+		id, day, part int
+		want          int
+	}{
+		// Bea was first to day 1's first star but is excluded, so Ada counts as first
+		{1, 0, 1, 1},
+		{2, 0, 1, 0},
+		{3, 0, 1, 2},
+		{1, 0, 2, 1},
+		{3, 0, 2, 2},
+	}
+	for _, test := range tests {
+		member := arrayFind(leaderboard.Members, func(m memberData) bool { return m.ID == test.id })
+		if got := completionRank(leaderboard, member, test.day, test.part); got != test.want {
+			t.Errorf("member %d's rank on day %d part %d = %d, want %d", test.id, test.day+1, test.part, got, test.want)
+		}
+	}
+}
+
+func TestUnrankedSection(t *testing.T) {
+	tests := []struct {
+		excluded string
+		want     string
+	}{
+		{"", ""},
+		{"2", "\nNot ranked: Bea (2 stars)\n"},
+		{"3,1", "\nNot ranked: Ada (3 stars), Cy (2 stars)\n"},
+	}
+	for _, test := range tests {
+		setFlag(t, excludeMembersArg, test.excluded)
+		if got := unrankedSection(scoringLeaderboard(t)); got != test.want {
+			t.Errorf("excluding %q: got %q, want %q", test.excluded, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-json"
+)
+
+var scoringTableArg = flag.String("scoringTable", "", "path to a json file of custom scoring rules (points per rank for each part, participation points, and caps), which adds a column scored by them to digests and exports and a custom scoring mode")
+
+// scoringTable is a leaderboard's own prize rules, for groups that award points their own way instead of by the
+// site's local score.
+type scoringTable struct {
+	// Name heads the column of custom scores.
+	Name string `json:"name"`
+	// Part1 and Part2 are the points for finishing each part of a day first, second, and so on.
+	Part1 []int `json:"part1"`
+	Part2 []int `json:"part2"`
+	// Participation is what a star earns when it's past the end of its part's list.
+	Participation int `json:"participation"`
+	// DayCap is the most a member can earn on a single day, and Cap the most over the whole event. Zero is no limit.
+	DayCap int `json:"day_cap"`
+	Cap    int `json:"cap"`
+}
+
+// customScoring is the table loaded from scoringTable, or nil if there isn't one.
+var customScoring *scoringTable
+
+// loadScoringTable reads scoringTable if it's set, and adds the custom mode to the scoring modes.
+func loadScoringTable() error {
+	if len(*scoringTableArg) == 0 {
+		return nil
+	}
+
+	contents, readErr := os.ReadFile(*scoringTableArg)
+	if readErr != nil {
+		return fmt.Errorf("error reading scoring table %s: %w", *scoringTableArg, readErr)
+	}
+	var table scoringTable
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&table); err != nil {
+		return fmt.Errorf("error parsing scoring table %s: %w", *scoringTableArg, err)
+	}
+	if len(table.Part1) == 0 && len(table.Part2) == 0 && table.Participation == 0 {
+		return fmt.Errorf("scoring table %s doesn't award any points", *scoringTableArg)
+	}
+	if table.Participation < 0 || table.DayCap < 0 || table.Cap < 0 {
+		return errors.New("the scoring table's participation points and caps can't be negative")
+	}
+	if len(table.Name) == 0 {
+		table.Name = "Custom"
+	}
+
+	customScoring = &table
+	scoringModes["custom"] = scoringMode{
+		description: "the rules in scoringTable (" + *scoringTableArg + ")",
+		score:       table.scores,
+	}
+	return nil
+}
+
+// scores awards each member the table's points for each star by where they finished it among the leaderboard,
+// keyed by member ID.
+func (t *scoringTable) scores(leaderboard *leaderboardData, year string) map[int]int {
+	scores := map[int]int{}
+	for _, member := range leaderboard.Members {
+		scores[member.ID] = 0
+	}
+
+	for day := 1; day <= eventDays(year); day++ {
+		dayScores := map[int]int{}
+		for part, points := range [][]int{t.Part1, t.Part2} {
+			for rank, f := range dayFinishers(leaderboard, day, part+1) {
+				if rank < len(points) {
+					dayScores[f.Member.ID] += points[rank]
+				} else {
+					dayScores[f.Member.ID] += t.Participation
+				}
+			}
+		}
+		for id, score := range dayScores {
+			if t.DayCap > 0 {
+				score = min(score, t.DayCap)
+			}
+			scores[id] += score
+		}
+	}
+
+	if t.Cap > 0 {
+		for id, score := range scores {
+			scores[id] = min(score, t.Cap)
+		}
+	}
+	return scores
+}
+
+// customScores scores the leaderboard by the custom scoring table, or returns nil if there isn't one. Members in
+// excludeMembers aren't scored.
+func customScores(leaderboard *leaderboardData, year string) map[int]int {
+	if customScoring == nil {
+		return nil
+	}
+	return customScoring.scores(rankedLeaderboard(leaderboard), year)
+}
+
+// customColumn is the header and divider of the custom scores' column in a markdown table, which is left out without
+// a scoring table.
+func customColumn(custom map[int]int) (header, divider string) {
+	if custom == nil {
+		return "", ""
+	}
+	return " " + customScoring.Name + " |", " ----: |"
+}
+
+// customCell is a member's cell in the custom scores' column.
+func customCell(custom map[int]int, id int) string {
+	if custom == nil {
+		return ""
+	}
+	return fmt.Sprintf(" %d |", custom[id])
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"pernicious.games/advent-of-code-scanner/notify"
+)
+
+func runSendTestCommand(args []string) error {
+	fs := flag.NewFlagSet("send-test", flag.ContinueOnError)
+	message := fs.String("message", ":wave: This is a test message from the Advent of Code leaderboard scanner.", "the message to send")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, scannerErr := newScanner(nil)
+	if scannerErr != nil {
+		return scannerErr
+	}
+
+	destinations := []struct {
+		name     string
+		host     string
+		notifier notify.Notifier
+	}{
+		{"webhook", webhookHost(*webhookURLArg), s.notifier},
+		{"admin webhook", webhookHost(*adminURLArg), s.adminNotifier},
+	}
+
+	numFailed, numSent := 0, 0
+	for _, dest := range destinations {
+		if dest.notifier == nil {
+			continue
+		}
+
+		// test messages go straight out rather than through the outbox, and aren't recorded as delivered
+		if err := deliver(context.Background(), dest.notifier, notify.Event{Content: *message}); err != nil {
+			fmt.Printf("%s (%s): failed: %v\n", dest.name, dest.host, err)
+			numFailed++
+			continue
+		}
+		fmt.Printf("%s (%s): ok\n", dest.name, dest.host)
+		numSent++
+	}
+
+	if numFailed > 0 {
+		return fmt.Errorf("%d of %d destinations failed", numFailed, numFailed+numSent)
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// server serves the web features (dashboard, metrics, and so on) for a single leaderboard from whatever the
+// configured store has cached.
+type server struct {
+	store     stateStore
+	partition statePartition
+	board     leaderboardSettings
+	events    *eventHub
+	// scanner is set when the server is also scanning, and is what the admin endpoints drive.
+	scanner *scanControls
+}
+
+// routes returns the handler for everything the server exposes.
+func (s *server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleDashboard)
+	mux.HandleFunc("/members/", s.handleMemberPage)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.HandleFunc("/events", s.handleEvents)
+	mux.HandleFunc("/ws", s.handleWebSocket)
+	mux.HandleFunc("/feed.rss", s.handleRSS)
+	mux.HandleFunc("/feed.atom", s.handleAtom)
+	mux.HandleFunc("/calendar.ics", s.handleCalendar)
+	mux.HandleFunc("/badge/", s.handleBadge)
+	mux.HandleFunc("/heatmap.svg", s.handleHeatmap)
+	mux.HandleFunc("/slack/command", s.handleSlackCommand)
+	mux.HandleFunc("/discord/interactions", s.handleDiscordInteraction)
+	mux.HandleFunc("/federation", s.handleFederation)
+	mux.HandleFunc("/ping", s.handlePing)
+	mux.Handle("/api/leaderboard", apiHandler(s.handleAPILeaderboard))
+	mux.Handle("/api/standings", apiHandler(s.handleAPIStandings))
+	mux.Handle("/api/members/", apiHandler(s.handleAPIMember))
+	mux.Handle("/api/days/", apiHandler(s.handleAPIDay))
+	mux.Handle("/api/snapshots", apiHandler(s.handleAPISnapshots))
+	mux.HandleFunc("/openapi.json", s.handleOpenAPI)
+	mux.Handle("/graphql", s.graphqlHandler())
+	mux.Handle("/admin/scan", s.adminHandler(s.handleAdminScan))
+	mux.Handle("/admin/flush", s.adminHandler(s.handleAdminFlush))
+	mux.Handle("/admin/digest", s.adminHandler(s.handleAdminDigest))
+
+	return mux
+}
+
+// cachedLeaderboard returns the most recently scanned copy of the leaderboard, or nil if nothing has been scanned
+// yet, along with the state it came from.
+func (s *server) cachedLeaderboard() (*leaderboardData, scanState, error) {
+	state, loadErr := s.store.Load(s.partition)
+	if loadErr != nil || len(state.LastBody) == 0 {
+		return nil, state, loadErr
+	}
+
+	leaderboard, buildErr := buildLeaderboard(state.LastBody)
+	if buildErr != nil {
+		return nil, state, buildErr
+	}
+
+	return &leaderboard, state, nil
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="300">
+<title>Advent of Code {{.Year}} leaderboard</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 0.3em 0.8em; text-align: left; }
+td.num { text-align: right; }
+tr:nth-child(even) { background: #f0f0f0; }
+table.heatmap td, table.heatmap th { padding: 0; min-width: 1.1em; height: 1.1em; font-size: 0.7em; text-align: center; }
+table.heatmap td:first-child { padding-right: 0.8em; text-align: left; font-size: 1em; }
+table.heatmap tr { background: none; }
+table.heatmap { border-spacing: 2px; border-collapse: separate; }
+.swatch { display: inline-block; width: 0.9em; height: 0.9em; margin-left: 0.8em; }
+</style>
+</head>
+<body>
+<h1><a href="https://adventofcode.com/{{.Year}}/leaderboard/private/view/{{.Leaderboard}}">Advent of Code {{.Year}} leaderboard</a></h1>
+{{if .Members}}
+<table>
+<tr><th>Rank</th><th>Name</th><th>Stars</th><th>Score</th><th>Last star</th></tr>
+{{range .Members}}<tr><td class="num">{{.Rank}}</td><td><a href="{{.Page}}">{{.Name}}</a></td><td class="num">{{.Stars}}</td><td class="num">{{.Score}}</td><td>{{.LastStar}}</td></tr>
+{{end}}</table>
+{{with .Heatmap}}<h2>Each day by {{$.HeatmapBy}}</h2>
+{{.}}{{end}}<p>Last updated {{.Updated}}.</p>
+{{else}}
+<p>No leaderboard data has been scanned yet.</p>
+{{end}}
+</body>
+</html>
+`))
+
+type dashboardMember struct {
+	Rank     string
+	Name     string
+	Page     string
+	Stars    int
+	Score    string
+	LastStar string
+}
+
+func (s *server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	leaderboard, state, loadErr := s.cachedLeaderboard()
+	if loadErr != nil {
+		logError("Error loading leaderboard for dashboard:", loadErr)
+		http.Error(w, "error loading leaderboard", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.renderDashboard(w, leaderboard, state); err != nil {
+		logError("Error rendering dashboard:", err)
+	}
+}
+
+// renderDashboard writes the dashboard page for the given leaderboard, which is nil if nothing has been scanned yet.
+func (s *server) renderDashboard(w io.Writer, leaderboard *leaderboardData, state scanState) error {
+	data := struct {
+		Year        string
+		Leaderboard string
+		Updated     string
+		Members     []dashboardMember
+		Heatmap     template.HTML
+		HeatmapBy   string
+	}{
+		Year:        s.partition.Year,
+		Leaderboard: s.partition.Leaderboard,
+		Updated:     time.Unix(state.LastRead, 0).In(s.board.Location).Format("Jan 2 3:04pm MST"),
+		HeatmapBy:   *heatmapByArg,
+	}
+	if leaderboard != nil {
+		for _, member := range listedStandings(leaderboard, s.partition.Year) {
+			lastStar := "-"
+			if member.LastStarTimestamp > 0 {
+				lastStar = time.Unix(int64(member.LastStarTimestamp), 0).In(s.board.Location).Format("Jan 2 3:04:05pm")
+			}
+			data.Members = append(data.Members, dashboardMember{Rank: rankText(member.Rank), Name: displayName(member.memberData), Page: memberPagePath(member.ID), Stars: member.Stars, Score: listedScoreText(member), LastStar: lastStar})
+		}
+		data.Heatmap = buildHeatmap(leaderboard, s.partition.Year, *heatmapByArg).html()
+	}
+
+	return dashboardTemplate.Execute(w, data)
+}
+
+// handleMetrics exposes the cached leaderboard in the Prometheus text format.
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	leaderboard, state, loadErr := s.cachedLeaderboard()
+	if loadErr != nil {
+		logError("Error loading leaderboard for metrics:", loadErr)
+		http.Error(w, "error loading leaderboard", http.StatusInternalServerError)
+		return
+	}
+
+	var sb strings.Builder
+	labels := fmt.Sprintf(`year="%s",leaderboard="%s"`, s.partition.Year, s.partition.Leaderboard)
+	metric := func(name, help, kind string) {
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("aoc_last_scan_timestamp_seconds", "When the leaderboard was last downloaded.", "gauge")
+	fmt.Fprintf(&sb, "aoc_last_scan_timestamp_seconds{%s} %d\n", labels, state.LastRead)
+	metric("aoc_outbox_pending", "Notifications waiting to be delivered.", "gauge")
+	fmt.Fprintf(&sb, "aoc_outbox_pending{%s} %d\n", labels, len(state.Outbox))
+
+	if leaderboard != nil {
+		metric("aoc_members", "Members on the leaderboard.", "gauge")
+		fmt.Fprintf(&sb, "aoc_members{%s} %d\n", labels, len(leaderboard.Members))
+
+		// members can change their names, so the ID is what to group by when graphing history
+		memberLabels := func(member memberData) string {
+			return fmt.Sprintf("%s,member_id=\"%d\",member=%s", labels, member.ID, strconv.Quote(displayName(member)))
+		}
+		standings := sortedStandings(leaderboard)
+		metric("aoc_member_stars", "Stars earned by each member.", "gauge")
+		for _, member := range standings {
+			fmt.Fprintf(&sb, "aoc_member_stars{%s} %d\n", memberLabels(member), member.Stars)
+		}
+		metric("aoc_member_local_score", "Each member's local score.", "gauge")
+		for _, member := range standings {
+			fmt.Fprintf(&sb, "aoc_member_local_score{%s} %d\n", memberLabels(member), member.LocalScore)
+		}
+		metric("aoc_member_rank", "Each member's place in the standings under the scoring mode.", "gauge")
+		for idx, member := range rankedStandings(leaderboard, s.partition.Year) {
+			fmt.Fprintf(&sb, "aoc_member_rank{%s} %d\n", memberLabels(member), idx+1)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(sb.String()))
+}
+
+func runServeCommand(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	tlsCert := fs.String("tlsCert", "", "TLS certificate file; serves HTTPS when given along with -tlsKey")
+	tlsKey := fs.String("tlsKey", "", "TLS private key file")
+	scan := fs.Bool("scan", false, "also scan the leaderboard on a schedule, as with -d")
+	grpcAddr := fs.String("grpcAddr", "", "address to also serve the gRPC service in proto/scanner.proto on; empty disables it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (len(*tlsCert) == 0) != (len(*tlsKey) == 0) {
+		return errors.New("-tlsCert and -tlsKey must be given together")
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	board, boardErr := configuredBoard(partition.Leaderboard)
+	if boardErr != nil {
+		return boardErr
+	}
+
+	ctx, stop := shutdownContext()
+	defer stop()
+
+	srv := &server{store: store, partition: partition, board: board, events: newEventHub()}
+	var scheduler *scanScheduler
+	var controls []*scanControls
+	if *scan {
+		// the scanner shares the store so that the memory store works too; the server only shows the first board
+		scheduler, controls = runScanner(ctx, store, true)
+		srv.scanner = controls[0]
+	}
+	stopWatching := make(chan struct{})
+	defer close(stopWatching)
+	go srv.watchForEvents(stopWatching)
+
+	httpServer := &http.Server{Addr: *addr, Handler: requireReadAccess(srv.routes()), ReadHeaderTimeout: 10 * time.Second}
+
+	serveErr := make(chan error, 2)
+	go func() {
+		logInfo("Serving on", *addr)
+		if len(*tlsCert) > 0 {
+			serveErr <- httpServer.ListenAndServeTLS(*tlsCert, *tlsKey)
+		} else {
+			serveErr <- httpServer.ListenAndServe()
+		}
+	}()
+
+	if len(*grpcAddr) > 0 {
+		stopGRPC, grpcErr := startGRPCServer(srv, *grpcAddr, *tlsCert, *tlsKey, serveErr)
+		if grpcErr != nil {
+			return grpcErr
+		}
+		defer stopGRPC()
+	}
+
+	systemdReady(ctx, scheduler)
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+		stop()
+		logInfo("Shutting down.")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// requests are finished first, since admin requests can still be using the scanner
+		err := httpServer.Shutdown(shutdownCtx)
+		if scheduler != nil {
+			stopScanner(scheduler, controls)
+		}
+		return err
+	}
+}
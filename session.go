@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pernicious.games/advent-of-code-scanner/aocclient"
+)
+
+// errSessionRejected is returned when adventofcode.com refuses a session cookie, usually by serving the login page
+// instead of the leaderboard json.
+var errSessionRejected = aocclient.ErrSessionRejected
+
+// errNotModified is returned when a conditional download finds that the leaderboard hasn't changed.
+var errNotModified = aocclient.ErrNotModified
+
+// sessionPool is an ordered list of session cookies where the first one that hasn't been rejected is used.
+type sessionPool struct {
+	sessions []string
+	// failed is keyed by sessionFingerprint so that it can be persisted without storing the cookie itself.
+	failed map[string]bool
+	// alert, if set, is told whenever a session is rejected.
+	alert func(ctx context.Context, content string) error
+}
+
+func newSessionPool(sessionList string, failed []string) *sessionPool {
+	pool := &sessionPool{failed: make(map[string]bool)}
+	for _, session := range strings.Split(sessionList, ",") {
+		session = strings.TrimSpace(session)
+		if len(session) > 0 {
+			pool.sessions = append(pool.sessions, session)
+		}
+	}
+	for _, fingerprint := range failed {
+		pool.failed[fingerprint] = true
+	}
+
+	return pool
+}
+
+// sessionFingerprint identifies a session cookie without revealing it.
+func sessionFingerprint(session string) string {
+	sum := sha256.Sum256([]byte(session))
+	return hex.EncodeToString(sum[:8])
+}
+
+// failedFingerprints returns the fingerprints of every configured session that has been rejected.
+func (p *sessionPool) failedFingerprints() []string {
+	fingerprints := []string{}
+	for _, session := range p.sessions {
+		if fingerprint := sessionFingerprint(session); p.failed[fingerprint] {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+	}
+
+	return fingerprints
+}
+
+// nextUsable is the index of the first session from idx on that hasn't been rejected, or -1 if there isn't one.
+func (p *sessionPool) nextUsable(idx int) int {
+	for ; idx < len(p.sessions); idx++ {
+		if !p.failed[sessionFingerprint(p.sessions[idx])] {
+			return idx
+		}
+	}
+	return -1
+}
+
+// download fetches the leaderboard with the first usable session, failing over to the next configured session (and
+// raising an alert) whenever one is rejected. It returns errNotModified if the leaderboard hasn't changed since the
+// version that since came from.
+func (p *sessionPool) download(ctx context.Context, year, leaderboardID string, since aocclient.Validators) ([]byte, aocclient.Validators, error) {
+	for idx, session := range p.sessions {
+		fingerprint := sessionFingerprint(session)
+		if p.failed[fingerprint] {
+			continue
+		}
+
+		body, validators, err := downloadLeaderboardData(ctx, year, leaderboardID, session, since)
+		if !errors.Is(err, errSessionRejected) {
+			return body, validators, err
+		}
+
+		p.failed[fingerprint] = true
+		msg := fmt.Sprintf(":warning: AoC session #%d (%s) was rejected while reading leaderboard %s; it has probably expired.", idx+1, fingerprint, leaderboardID)
+		if next := p.nextUsable(idx + 1); next >= 0 {
+			msg += fmt.Sprintf(" Failing over to session #%d.", next+1)
+		} else {
+			msg += " No backup sessions remain, so scanning is stopped until a new session is configured."
+		}
+		logWarn(msg)
+		if p.alert != nil {
+			if alertErr := p.alert(ctx, msg); alertErr != nil {
+				logError("Error sending session failover alert:", alertErr)
+			}
+		}
+	}
+
+	return nil, aocclient.Validators{}, fmt.Errorf("all %d configured sessions have been rejected: %w", len(p.sessions), errSessionRejected)
+}
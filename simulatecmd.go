@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/notify"
+)
+
+var simulatedNames = []string{"Ada Lovelace", "Grace Hopper", "Alan Turing", "Edsger Dijkstra", "Barbara Liskov", "Donald Knuth", "Margaret Hamilton", "Ken Thompson", "Frances Allen", "John McCarthy", "Radia Perlman", "Dennis Ritchie"}
+
+// leaderboardSimulator makes up a leaderboard one scan at a time: each round unlocks the next day, some members solve
+// it, some finish at exactly the same second as someone else, and now and then somebody new joins.
+type leaderboardSimulator struct {
+	rng         *rand.Rand
+	year        string
+	leaderboard leaderboardData
+	day         int
+}
+
+func newLeaderboardSimulator(year string, members int, seed int64) *leaderboardSimulator {
+	sim := &leaderboardSimulator{rng: rand.New(rand.NewSource(seed)), year: year}
+	for i := 0; i < members; i++ {
+		sim.addMember()
+	}
+	sim.score()
+	return sim
+}
+
+func (sim *leaderboardSimulator) addMember() {
+	id := 100000 + len(sim.leaderboard.Members)
+	name := ""
+	// leave one member anonymous, since that's a case the real site produces too
+	if idx := len(sim.leaderboard.Members); idx != 3 {
+		name = simulatedNames[idx%len(simulatedNames)]
+		if idx >= len(simulatedNames) {
+			name = fmt.Sprintf("%s %d", name, idx/len(simulatedNames)+1)
+		}
+	}
+
+	sim.leaderboard.Members = append(sim.leaderboard.Members, memberData{
+		ID:                 id,
+		Name:               name,
+		CompletionDayLevel: make([]completionDayData, 25),
+	})
+	if len(sim.leaderboard.Members) == 1 {
+		sim.leaderboard.OwnerID = id
+	}
+}
+
+// snapshot returns a copy of the leaderboard that later rounds won't modify.
+func (sim *leaderboardSimulator) snapshot() *leaderboardData {
+	out := sim.leaderboard
+	out.Members = make([]memberData, len(sim.leaderboard.Members))
+	for idx, member := range sim.leaderboard.Members {
+		member.CompletionDayLevel = append([]completionDayData(nil), member.CompletionDayLevel...)
+		out.Members[idx] = member
+	}
+	return &out
+}
+
+// step simulates everything that happens between two scans, and reports false once every day has been played.
+func (sim *leaderboardSimulator) step() bool {
+	if sim.day >= eventDays(sim.year) {
+		return false
+	}
+	sim.day++
+	dayIdx := sim.day - 1
+	unlock := dayUnlock(sim.year, sim.day)
+
+	if sim.rng.Intn(3) == 0 {
+		sim.addMember()
+	}
+
+	var lastPart1, lastPart2 int64
+	for idx := range sim.leaderboard.Members {
+		member := &sim.leaderboard.Members[idx]
+		if sim.rng.Intn(4) == 0 {
+			continue
+		}
+
+		part1 := unlock.Add(time.Duration(5+sim.rng.Intn(120))*time.Minute + time.Duration(sim.rng.Intn(60))*time.Second).Unix()
+		// copying the previous solver's time now and then makes sure ties show up
+		if lastPart1 > 0 && sim.rng.Intn(5) == 0 {
+			part1 = lastPart1
+		}
+		member.CompletionDayLevel[dayIdx].Part1 = &completionPartData{GotStarAt: part1}
+		lastPart1 = part1
+
+		if sim.rng.Intn(3) == 0 {
+			continue
+		}
+		part2 := part1 + int64(60+sim.rng.Intn(90*60))
+		if lastPart2 > part1 && sim.rng.Intn(5) == 0 {
+			part2 = lastPart2
+		}
+		member.CompletionDayLevel[dayIdx].Part2 = &completionPartData{GotStarAt: part2}
+		lastPart2 = part2
+	}
+
+	sim.score()
+	return true
+}
+
+// score fills in each member's stars, local score, and last star time the way the site would.
+func (sim *leaderboardSimulator) score() {
+	scores := computeLocalScores(&sim.leaderboard, sim.day)
+	for idx := range sim.leaderboard.Members {
+		member := &sim.leaderboard.Members[idx]
+		member.Stars = getTotalStars(member, -1)
+		member.LocalScore = scores[member.ID]
+		member.LastStarTimestamp = 0
+		for _, day := range member.CompletionDayLevel {
+			for _, part := range []*completionPartData{day.Part1, day.Part2} {
+				if part != nil && int(part.GotStarAt) > member.LastStarTimestamp {
+					member.LastStarTimestamp = int(part.GotStarAt)
+				}
+			}
+		}
+	}
+}
+
+func runSimulateCommand(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	members := fs.Int("members", 5, "how many members the simulated leaderboard starts with")
+	rounds := fs.Int("rounds", 3, "how many scans to simulate; each one unlocks the next day")
+	seed := fs.Int64("seed", 0, "seed for the random data, to repeat a run; defaults to a random seed")
+	send := fs.Bool("send", false, "send the notifications to the configured webhook instead of only printing them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *members < 0 || *rounds <= 0 || fs.NArg() > 0 {
+		return errors.New("usage: simulate [-members N] [-rounds N] [-seed N] [-send]")
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	fmt.Printf("Simulating %d scans of %d members with -seed %d\n", *rounds, *members, *seed)
+
+	var s *scanner
+	if *send {
+		var scannerErr error
+		if s, scannerErr = newScanner(nil); scannerErr != nil {
+			return scannerErr
+		}
+	}
+
+	// links in the notifications point at the configured leaderboard when there is one, to preview exactly what it'd get
+	boardID := primaryLeaderboard()
+	if len(boardID) == 0 {
+		boardID = "1234567"
+	}
+	board, boardErr := configuredBoard(boardID)
+	if boardErr != nil {
+		return boardErr
+	}
+
+	sim := newLeaderboardSimulator(*yearArg, *members, *seed)
+	prev := sim.snapshot()
+	numEvents, numFailed := 0, 0
+	for round := 1; round <= *rounds && sim.step(); round++ {
+		curr := sim.snapshot()
+		fmt.Printf("\nScan %d (day %d):\n", round, sim.day)
+
+		// simulated notifications go straight out rather than through the outbox, and aren't recorded as delivered
+		for _, event := range detectEvents(prev, curr, *yearArg, board) {
+			fmt.Println(event.Content)
+			numEvents++
+			if !*send {
+				continue
+			}
+			if err := deliver(context.Background(), s.notifier, notify.Event{Key: event.Key, Content: event.Content}); err != nil {
+				logError("Error sending simulated notification:", err)
+				numFailed++
+			}
+		}
+		prev = curr
+	}
+
+	fmt.Println()
+	printSummary(prev, board)
+
+	if numFailed > 0 {
+		return fmt.Errorf("%d of %d simulated notifications couldn't be sent", numFailed, numEvents)
+	}
+	return nil
+}
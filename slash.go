@@ -0,0 +1,280 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+var (
+	slackSigningSecretArg = flag.String("slackSigningSecret", "", "signing secret of the Slack app whose /aoc slash command is answered by the web server")
+	discordPublicKeyArg   = flag.String("discordPublicKey", "", "public key of the Discord application whose /aoc command is answered by the web server")
+	chatMembersArg        = flag.String("chatMembers", "", "comma-separated chatUserID=memberID pairs telling /aoc me which leaderboard member each Slack or Discord user is")
+)
+
+// slashRequestMaxAge is how old a signed request can be before it's rejected as a possible replay.
+const slashRequestMaxAge = 5 * time.Minute
+
+const slashUsage = "Usage: `/aoc standings`, `/aoc day <n>`, or `/aoc me`"
+
+// chatMember finds the leaderboard member a chat user is, either from chatMembers or by a member having the same name.
+func chatMember(leaderboard *leaderboardData, userID, userName string) *memberData {
+	for _, pair := range strings.Split(*chatMembersArg, ",") {
+		user, member, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || user != userID {
+			continue
+		}
+		if id, err := strconv.Atoi(member); err == nil {
+			return arrayFind(leaderboard.Members, func(m memberData) bool { return m.ID == id })
+		}
+	}
+
+	return arrayFind(leaderboard.Members, func(m memberData) bool { return len(m.Name) > 0 && strings.EqualFold(m.Name, userName) })
+}
+
+// answerSlashCommand formats the answer to an /aoc command from the cached leaderboard.
+func (s *server) answerSlashCommand(args []string, userID, userName string) string {
+	leaderboard, state, loadErr := s.cachedLeaderboard()
+	if loadErr != nil {
+		logError("Error loading leaderboard for slash command:", loadErr)
+		return "Sorry, there was an error loading the leaderboard."
+	}
+	if leaderboard == nil {
+		return "The leaderboard hasn't been scanned yet."
+	}
+	updated := time.Unix(state.LastRead, 0).In(s.board.Location).Format("Jan 2 3:04pm MST")
+
+	if len(args) == 0 {
+		args = []string{"standings"}
+	}
+	switch {
+	case args[0] == "standings" && len(args) == 1:
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Advent of Code %s standings as of %s:\n```\n", s.partition.Year, updated)
+		standings := rankedStandings(leaderboard, s.partition.Year)
+		for idx, member := range standings {
+			if idx == 10 {
+				fmt.Fprintf(&sb, "…and %d more\n", len(standings)-idx)
+				break
+			}
+			fmt.Fprintf(&sb, "%2d. %-24s %3d ⭐ %9s\n", idx+1, displayName(member), member.Stars, scoreText(member.LocalScore))
+		}
+		sb.WriteString("```")
+		return sb.String()
+
+	case args[0] == "day" && len(args) == 2:
+		day, dayErr := strconv.Atoi(args[1])
+		if dayErr != nil || day < 1 || day > eventDays(s.partition.Year) {
+			return fmt.Sprintf("%s has days 1 through %d.", s.partition.Year, eventDays(s.partition.Year))
+		}
+
+		unlock := dayUnlock(s.partition.Year, day)
+		finishers := dayFinishers(leaderboard, day, 2)
+		started := len(dayFinishers(leaderboard, day, 1))
+		if started == 0 {
+			return fmt.Sprintf("Nobody has a star for day %d yet.", day)
+		}
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Day %d: %d finished, %d more with only the first star.\n", day, len(finishers), started-len(finishers))
+		if len(finishers) > 0 {
+			sb.WriteString("```\n")
+			ranks := finisherRanks(finishers)
+			for idx, f := range finishers {
+				if idx == 10 {
+					break
+				}
+				fmt.Fprintf(&sb, "%2s. %-24s %s\n", rankText(ranks[idx]), displayName(f.Member), formatElapsed(f.At.Sub(unlock)))
+			}
+			sb.WriteString("```")
+		}
+		return strings.TrimSpace(sb.String())
+
+	case args[0] == "me" && len(args) == 1:
+		member := chatMember(leaderboard, userID, userName)
+		if member == nil {
+			return "I don't know which leaderboard member you are. Ask the admin to add you to `chatMembers`."
+		}
+		rank, score := 0, 0
+		standings := rankedStandings(leaderboard, s.partition.Year)
+		for idx, m := range standings {
+			if m.ID == member.ID {
+				rank, score = idx+1, m.LocalScore
+			}
+		}
+		lastStar := "no stars yet"
+		if member.LastStarTimestamp > 0 {
+			lastStar = "last star " + time.Unix(int64(member.LastStarTimestamp), 0).In(s.board.Location).Format("Jan 2 3:04pm MST")
+		}
+		if rank == 0 {
+			return fmt.Sprintf("%s: not ranked, with %d stars (%s). As of %s.", displayName(*member), member.Stars, lastStar, updated)
+		}
+		return fmt.Sprintf("%s: rank %d of %d with %d stars and %s (%s). As of %s.", displayName(*member), rank, len(standings), member.Stars, scoreText(score), lastStar, updated)
+	}
+
+	return slashUsage
+}
+
+// readSignedBody reads a request's body and checks its timestamp, leaving verifying the signature to the caller.
+func readSignedBody(w http.ResponseWriter, r *http.Request, timestamp string) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	ts, tsErr := strconv.ParseInt(timestamp, 10, 64)
+	if tsErr != nil || time.Since(time.Unix(ts, 0)).Abs() > slashRequestMaxAge {
+		http.Error(w, "invalid request timestamp", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	body, readErr := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if readErr != nil {
+		http.Error(w, "error reading request", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return body, true
+}
+
+// handleSlackCommand answers Slack slash commands after checking they're signed with the app's signing secret.
+func (s *server) handleSlackCommand(w http.ResponseWriter, r *http.Request) {
+	if len(*slackSigningSecretArg) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	body, ok := readSignedBody(w, r, timestamp)
+	if !ok {
+		return
+	}
+
+	mac := hmac.New(sha256.New, []byte(*slackSigningSecretArg))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Slack-Signature"))) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, parseErr := url.ParseQuery(string(body))
+	if parseErr != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	answer := s.answerSlashCommand(strings.Fields(form.Get("text")), form.Get("user_id"), form.Get("user_name"))
+	writeJSON(w, http.StatusOK, map[string]string{"response_type": "ephemeral", "text": answer})
+}
+
+// discordInteraction is the part of a Discord interaction that the /aoc command needs.
+type discordInteraction struct {
+	Type int `json:"type"`
+	Data struct {
+		Options []discordOption `json:"options"`
+	} `json:"data"`
+	// Member is set for interactions in a server, and User for ones in a DM.
+	Member *struct {
+		User discordUser `json:"user"`
+	} `json:"member"`
+	User *discordUser `json:"user"`
+}
+
+type discordOption struct {
+	Name    string          `json:"name"`
+	Value   json.RawMessage `json:"value"`
+	Options []discordOption `json:"options"`
+}
+
+type discordUser struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+}
+
+const (
+	discordPing               = 1
+	discordApplicationCommand = 2
+	discordPong               = 1
+	discordMessageResponse    = 4
+	discordEphemeralFlag      = 1 << 6
+)
+
+// handleDiscordInteraction answers Discord interactions after checking they're signed with the application's key.
+// The /aoc command is expected to have standings, day (with a day number option), and me subcommands.
+func (s *server) handleDiscordInteraction(w http.ResponseWriter, r *http.Request) {
+	publicKey, keyErr := hex.DecodeString(*discordPublicKeyArg)
+	if len(*discordPublicKeyArg) == 0 || keyErr != nil || len(publicKey) != ed25519.PublicKeySize {
+		if len(*discordPublicKeyArg) > 0 {
+			logError("discordPublicKey isn't a valid hex-encoded ed25519 public key")
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	timestamp := r.Header.Get("X-Signature-Timestamp")
+	body, ok := readSignedBody(w, r, timestamp)
+	if !ok {
+		return
+	}
+
+	signature, sigErr := hex.DecodeString(r.Header.Get("X-Signature-Ed25519"))
+	if sigErr != nil || !ed25519.Verify(publicKey, append([]byte(timestamp), body...), signature) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var interaction discordInteraction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		http.Error(w, "invalid interaction", http.StatusBadRequest)
+		return
+	}
+
+	switch interaction.Type {
+	case discordPing:
+		writeJSON(w, http.StatusOK, map[string]int{"type": discordPong})
+		return
+	case discordApplicationCommand:
+	default:
+		http.Error(w, "unsupported interaction", http.StatusBadRequest)
+		return
+	}
+
+	var args []string
+	if len(interaction.Data.Options) > 0 {
+		sub := interaction.Data.Options[0]
+		args = append(args, sub.Name)
+		for _, opt := range sub.Options {
+			args = append(args, strings.Trim(string(opt.Value), `"`))
+		}
+	}
+
+	user := interaction.User
+	if interaction.Member != nil {
+		user = &interaction.Member.User
+	}
+	var userID, userName string
+	if user != nil {
+		userID, userName = user.ID, user.GlobalName
+		if len(userName) == 0 {
+			userName = user.Username
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"type": discordMessageResponse,
+		"data": map[string]any{"content": s.answerSlashCommand(args, userID, userName), "flags": discordEphemeralFlag},
+	})
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// stateArchiveVersion is the version of the archives `state export` writes. Version 1 archives held a single
+// partition at the top of the archive; they can still be imported.
+const stateArchiveVersion = 2
+
+// firstEventYear is the first year Advent of Code ran.
+const firstEventYear = 2015
+
+// stateArchiveManifest describes a state archive written by `state export`.
+type stateArchiveManifest struct {
+	Version    int                     `json:"version"`
+	Partitions []stateArchivePartition `json:"partitions,omitempty"`
+	// Year and Leaderboard are the only partition in a version 1 archive.
+	Year        string `json:"year,omitempty"`
+	Leaderboard string `json:"leaderboard,omitempty"`
+	ExportedAt  int64  `json:"exported_at"`
+}
+
+// stateArchivePartition is one partition in a state archive. Its state and snapshots are under its dir.
+type stateArchivePartition struct {
+	Year        string `json:"year"`
+	Leaderboard string `json:"leaderboard"`
+	Snapshots   int    `json:"snapshots"`
+}
+
+func (p stateArchivePartition) partition() statePartition {
+	return statePartition{Year: p.Year, Leaderboard: p.Leaderboard}
+}
+
+// dir is where the partition's files are in the archive.
+func (p stateArchivePartition) dir() string {
+	return p.Year + "/" + p.Leaderboard + "/"
+}
+
+// openConfiguredStore opens the configured store along with the partition for the configured year and leaderboard.
+func openConfiguredStore() (stateStore, statePartition, error) {
+	if len(primaryLeaderboard()) == 0 {
+		return nil, statePartition{}, errors.New("no leaderboard ID provided")
+	}
+
+	// commands that work with a single leaderboard use the first one configured
+	partition := statePartition{Year: *yearArg, Leaderboard: primaryLeaderboard()}
+	store, storeErr := openStore(*storeArg)
+	return store, partition, storeErr
+}
+
+func runStateCommand(args []string) error {
+	if len(args) != 2 || (args[0] != "export" && args[0] != "import") {
+		return errors.New("usage: state export <file.tar.gz> | state import <file.tar.gz>")
+	}
+
+	store, _, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+
+	if args[0] == "export" {
+		return exportState(store, exportablePartitions(), args[1])
+	}
+
+	return importState(store, args[1])
+}
+
+// exportablePartitions is every partition the store could be holding for this configuration: each configured
+// leaderboard and each federated board, for every year from the first event to this one or the configured year.
+func exportablePartitions() []statePartition {
+	lastYear := time.Now().Year()
+	if year, err := strconv.Atoi(*yearArg); err == nil {
+		lastYear = max(lastYear, year)
+	}
+
+	var partitions []statePartition
+	for year := firstEventYear; year <= lastYear; year++ {
+		for _, board := range configuredLeaderboards() {
+			partitions = append(partitions, statePartition{Year: strconv.Itoa(year), Leaderboard: board})
+		}
+		for _, board := range federatedBoards() {
+			partitions = append(partitions, federatedPartition(strconv.Itoa(year), board))
+		}
+	}
+	return partitions
+}
+
+func writeTarFile(tw *tar.Writer, name string, modTime time.Time, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: modTime}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("error writing %s to archive: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("error writing %s to archive: %w", name, err)
+	}
+
+	return nil
+}
+
+// exportState writes the cached state and leaderboard history of each of the given partitions that the store has
+// anything for, along with the delivery ledger, to a gzipped tarball.
+func exportState(store stateStore, partitions []statePartition, dest string) error {
+	f, createErr := os.Create(dest)
+	if createErr != nil {
+		return fmt.Errorf("error creating %s: %w", dest, createErr)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	manifest := stateArchiveManifest{Version: stateArchiveVersion, ExportedAt: now.Unix()}
+	history := historyFor(store)
+
+	numDeliveries, numSnapshots := 0, 0
+	var exported []string
+	for _, partition := range partitions {
+		state, loadErr := store.Load(partition)
+		if loadErr != nil {
+			return loadErr
+		}
+
+		var snaps []snapshot
+		if history != nil {
+			var listErr error
+			if snaps, listErr = history.Snapshots(partition); listErr != nil {
+				return listErr
+			}
+		}
+
+		if state.LastRead == 0 && len(state.Outbox) == 0 && len(state.Delivered) == 0 && len(snaps) == 0 {
+			continue
+		}
+
+		entry := stateArchivePartition{Year: partition.Year, Leaderboard: partition.Leaderboard, Snapshots: len(snaps)}
+		stateBytes, marshalErr := marshalState(state)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		if err := writeTarFile(tw, entry.dir()+"state.json", now, stateBytes); err != nil {
+			return err
+		}
+
+		for _, listed := range snaps {
+			snap, snapErr := history.LoadSnapshot(listed.ID)
+			if snapErr != nil {
+				return snapErr
+			}
+			if err := writeTarFile(tw, fmt.Sprintf("%ssnapshots/%d.json", entry.dir(), snap.FetchedAt.Unix()), snap.FetchedAt, snap.Body); err != nil {
+				return err
+			}
+		}
+
+		manifest.Partitions = append(manifest.Partitions, entry)
+		exported = append(exported, partition.String())
+		numDeliveries += len(state.Delivered)
+		numSnapshots += len(snaps)
+	}
+
+	if len(manifest.Partitions) == 0 {
+		f.Close()
+		os.Remove(dest)
+		return errors.New("the configured store has nothing saved for the configured leaderboards")
+	}
+
+	// a store that keeps its own ledger keeps it for every partition at once; otherwise each partition's deliveries are
+	// in its state
+	deliveries := map[string]int64{}
+	if lister, ok := store.(ledgerLister); ok {
+		var listErr error
+		if deliveries, listErr = lister.Deliveries(); listErr != nil {
+			return listErr
+		}
+		numDeliveries = len(deliveries)
+	}
+	ledgerBytes, _ := json.Marshal(deliveries)
+	if err := writeTarFile(tw, "ledger.json", now, ledgerBytes); err != nil {
+		return err
+	}
+
+	manifestBytes, _ := json.Marshal(manifest)
+	if err := writeTarFile(tw, "manifest.json", now, manifestBytes); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("error finishing archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error finishing archive: %w", err)
+	}
+
+	fmt.Printf("Exported state for %s, %d ledger entries, and %d snapshots to %s\n", strings.Join(exported, ", "), numDeliveries, numSnapshots, dest)
+	return f.Close()
+}
+
+// importState restores every partition in an archive written by exportState into the configured store. Snapshots
+// that the store's history already has are skipped, so importing the same archive twice is harmless.
+func importState(store stateStore, src string) error {
+	f, openErr := os.Open(src)
+	if openErr != nil {
+		return fmt.Errorf("error opening %s: %w", src, openErr)
+	}
+	defer f.Close()
+
+	gz, gzErr := gzip.NewReader(f)
+	if gzErr != nil {
+		return fmt.Errorf("error decompressing %s: %w", src, gzErr)
+	}
+
+	var manifest stateArchiveManifest
+	var deliveries map[string]int64
+	// the manifest isn't necessarily first, so files are kept by name until it's been read
+	files := map[string][]byte{}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, nextErr := tr.Next()
+		if errors.Is(nextErr, io.EOF) {
+			break
+		}
+		if nextErr != nil {
+			return fmt.Errorf("error reading %s: %w", src, nextErr)
+		}
+
+		data, readErr := io.ReadAll(tr)
+		if readErr != nil {
+			return fmt.Errorf("error reading %s from %s: %w", hdr.Name, src, readErr)
+		}
+
+		switch hdr.Name {
+		case "manifest.json":
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return fmt.Errorf("error parsing archive manifest: %w", err)
+			}
+		case "ledger.json":
+			if err := json.Unmarshal(data, &deliveries); err != nil {
+				return fmt.Errorf("error parsing archived ledger: %w", err)
+			}
+		default:
+			files[hdr.Name] = data
+		}
+	}
+
+	var dirs []string
+	switch manifest.Version {
+	case 1:
+		manifest.Partitions = []stateArchivePartition{{Year: manifest.Year, Leaderboard: manifest.Leaderboard}}
+		dirs = []string{""}
+	case stateArchiveVersion:
+		for _, entry := range manifest.Partitions {
+			dirs = append(dirs, entry.dir())
+		}
+	}
+	if len(manifest.Partitions) == 0 {
+		return fmt.Errorf("%s isn't a state archive this version can import", src)
+	}
+
+	states := make([]scanState, len(manifest.Partitions))
+	snaps := make([][]snapshot, len(manifest.Partitions))
+	for i, entry := range manifest.Partitions {
+		data, ok := files[dirs[i]+"state.json"]
+		if !ok {
+			return fmt.Errorf("%s is missing the state for %s", src, entry.partition())
+		}
+		parsed, parseErr := unmarshalState(data)
+		if parseErr != nil {
+			return parseErr
+		}
+		states[i] = parsed
+
+		for name, body := range files {
+			base, ok := strings.CutPrefix(name, dirs[i]+"snapshots/")
+			if !ok {
+				continue
+			}
+			fetchedAt, parseErr := strconv.ParseInt(strings.TrimSuffix(path.Base(base), ".json"), 10, 64)
+			if parseErr != nil {
+				return fmt.Errorf("unexpected snapshot %s in archive", name)
+			}
+			snaps[i] = append(snaps[i], snapshot{FetchedAt: time.Unix(fetchedAt, 0), Year: entry.Year, Leaderboard: entry.Leaderboard, Body: body})
+		}
+		sort.Slice(snaps[i], func(a, b int) bool { return snaps[i][a].FetchedAt.Before(snaps[i][b].FetchedAt) })
+	}
+
+	// a store with its own ledger gets the archive's ledger and every delivery recorded in the archived states; a store
+	// without one keeps the archive's ledger in each partition's state
+	numDeliveries := 0
+	if ledger, ok := store.(deliveryLedger); ok {
+		all := maps.Clone(deliveries)
+		if all == nil {
+			all = map[string]int64{}
+		}
+		for _, state := range states {
+			maps.Copy(all, state.Delivered)
+		}
+		if importer, ok := ledger.(ledgerImporter); ok {
+			if err := importer.ImportDeliveries(all); err != nil {
+				return err
+			}
+		} else {
+			for key, at := range all {
+				if err := ledger.MarkDelivered(key, time.Unix(at, 0)); err != nil {
+					return err
+				}
+			}
+		}
+		numDeliveries = len(all)
+	} else {
+		for i := range states {
+			if states[i].Delivered == nil && len(deliveries) > 0 {
+				states[i].Delivered = make(map[string]int64, len(deliveries))
+			}
+			maps.Copy(states[i].Delivered, deliveries)
+			numDeliveries += len(states[i].Delivered)
+		}
+	}
+
+	history := historyFor(store)
+	imported, skipped := 0, 0
+	var restored []string
+	for i, entry := range manifest.Partitions {
+		partition := entry.partition()
+		if err := store.Save(partition, states[i]); err != nil {
+			return err
+		}
+		restored = append(restored, partition.String())
+
+		if history == nil {
+			skipped += len(snaps[i])
+			continue
+		}
+		if len(snaps[i]) == 0 {
+			continue
+		}
+
+		existing, listErr := history.Snapshots(partition)
+		if listErr != nil {
+			return listErr
+		}
+		for _, snap := range snaps[i] {
+			if arrayContains(existing, func(e snapshot) bool { return e.FetchedAt.Unix() == snap.FetchedAt.Unix() }) {
+				continue
+			}
+			if err := history.SaveSnapshot(snap); err != nil {
+				return err
+			}
+			imported++
+		}
+	}
+	if skipped > 0 {
+		logWarn("The configured store doesn't keep history, so the archive's", skipped, "snapshots were not imported")
+	}
+
+	fmt.Printf("Imported state for %s, %d ledger entries, and %d snapshots from %s\n", strings.Join(restored, ", "), numDeliveries, imported, src)
+	return nil
+}
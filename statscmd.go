@@ -0,0 +1,196 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// memberStats are the per-member metrics reported by the stats command. Times are measured from each puzzle's unlock.
+type memberStats struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Stars int    `json:"stars"`
+	// AvgPart1 is the average time to the first star across every day the member has one for.
+	AvgPart1 time.Duration `json:"avg_part1_seconds"`
+	// AvgPart2Delta is the average time between the first and second star across every day the member has both for.
+	AvgPart2Delta time.Duration `json:"avg_part2_delta_seconds"`
+	// BestDay is the day the member earned both stars fastest, or 0 if they've never earned both on any day.
+	BestDay     int           `json:"best_day"`
+	BestDayTime time.Duration `json:"best_day_seconds"`
+	// LongestStreak is the most consecutive days the member earned both stars on.
+	LongestStreak int `json:"longest_streak"`
+	// Rating is the member's Elo rating from how they've finished each day against everyone else, or 0 if they're in
+	// excludeMembers.
+	Rating int `json:"rating"`
+	// Trend is how much the member's times compared to the leaderboard's median change each week, as a fraction where
+	// negative is speeding up, or nil if they haven't finished enough days.
+	Trend *float64 `json:"trend_per_week,omitempty"`
+}
+
+// MarshalJSON reports durations in whole seconds, which is friendlier to anything consuming the output than
+// nanoseconds.
+func (s memberStats) MarshalJSON() ([]byte, error) {
+	type plain memberStats
+	out := plain(s)
+	out.AvgPart1 /= time.Second
+	out.AvgPart2Delta /= time.Second
+	out.BestDayTime /= time.Second
+	return json.Marshal(out)
+}
+
+func computeMemberStats(member memberData, year string) memberStats {
+	stats := memberStats{ID: member.ID, Name: displayName(member), Stars: member.Stars}
+
+	var part1Total, deltaTotal time.Duration
+	var part1Count, deltaCount, streak int
+	for dayIdx, day := range member.CompletionDayLevel {
+		if day.Part1 == nil {
+			streak = 0
+			continue
+		}
+
+		unlock := dayUnlock(year, dayIdx+1)
+		part1Total += time.Unix(day.Part1.GotStarAt, 0).Sub(unlock)
+		part1Count++
+
+		if day.Part2 == nil {
+			streak = 0
+			continue
+		}
+
+		deltaTotal += time.Duration(day.Part2.GotStarAt-day.Part1.GotStarAt) * time.Second
+		deltaCount++
+
+		if elapsed := time.Unix(day.Part2.GotStarAt, 0).Sub(unlock); stats.BestDay == 0 || elapsed < stats.BestDayTime {
+			stats.BestDay = dayIdx + 1
+			stats.BestDayTime = elapsed
+		}
+
+		streak++
+		if streak > stats.LongestStreak {
+			stats.LongestStreak = streak
+		}
+	}
+
+	if part1Count > 0 {
+		stats.AvgPart1 = (part1Total / time.Duration(part1Count)).Round(time.Second)
+	}
+	if deltaCount > 0 {
+		stats.AvgPart2Delta = (deltaTotal / time.Duration(deltaCount)).Round(time.Second)
+	}
+
+	return stats
+}
+
+func runStatsCommand(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	asJSON := fs.Bool("json", false, "print the stats as json instead of a table")
+	day := fs.Int("day", 0, "instead of the per-member stats, show the median and quartiles of this day's solve times and each member's percentile")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *day < 0 || *day > eventDays(*yearArg) || fs.NArg() > 0 {
+		return fmt.Errorf("usage: stats [-cached] [-json] [-day 1-%d]", eventDays(*yearArg))
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	leaderboard, board, loadErr := loadStoredLeaderboard(store, partition, *cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+	if *day > 0 {
+		return printDayStats(computeDayStats(leaderboard, *yearArg, *day), board, *asJSON)
+	}
+	ratings, ratingErr := memberRatings(store, partition, leaderboard)
+	if ratingErr != nil {
+		return ratingErr
+	}
+
+	solves := relativeSolveTimes(leaderboard, *yearArg)
+	var stats []memberStats
+	for _, member := range listedStandings(leaderboard, *yearArg) {
+		row := computeMemberStats(member.memberData, *yearArg)
+		row.Rating = int(math.Round(ratings[member.ID]))
+		if trend, ok := solveTrend(solves[member.ID]); ok {
+			trend = math.Round(trend*1000) / 1000
+			row.Trend = &trend
+		}
+		stats = append(stats, row)
+	}
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(stats, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tStars\tAvg part 1\tAvg part 2 delta\tBest day\tLongest streak\tRating\tTrend")
+	for _, s := range stats {
+		bestDay := "-"
+		if s.BestDay > 0 {
+			bestDay = fmt.Sprintf("%d (%s)", s.BestDay, s.BestDayTime)
+		}
+		rating := "-"
+		if s.Rating > 0 {
+			rating = strconv.Itoa(s.Rating)
+		}
+		trend := "-"
+		if s.Trend != nil {
+			trend = formatTrend(*s.Trend)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d\t%s\t%s\n", s.Name, s.Stars, formatStat(s.AvgPart1), formatStat(s.AvgPart2Delta), bestDay, s.LongestStreak, rating, trend)
+	}
+	w.Flush()
+
+	return nil
+}
+
+// printDayStats prints the spread of a day's solve times and where each member fell in it.
+func printDayStats(stats daySolveStats, board leaderboardSettings, asJSON bool) error {
+	if asJSON {
+		out, _ := json.MarshalIndent(newAPIDayStats(stats, *yearArg, board.Location), "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Day %d\tSolvers\t25th percentile\tMedian\t75th percentile\n", stats.Day)
+	for idx, part := range []partSolveStats{stats.Part1, stats.Part2} {
+		fmt.Fprintf(w, "Part %d\t%d\t%s\t%s\t%s\n", idx+1, part.Solvers, formatStat(part.P25), formatStat(part.Median), formatStat(part.P75))
+	}
+	fmt.Fprintln(w)
+
+	placing := func(p *solvePlacing) (string, string) {
+		if p == nil {
+			return "-", "-"
+		}
+		return p.Time.String(), fmt.Sprintf("%.0f", p.Percentile)
+	}
+	fmt.Fprintln(w, "Name\tPart 1\tPercentile\tPart 2\tPercentile")
+	for _, member := range stats.Members {
+		time1, percentile1 := placing(member.Part1)
+		time2, percentile2 := placing(member.Part2)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", displayName(member.Member), time1, percentile1, time2, percentile2)
+	}
+	return w.Flush()
+}
+
+func formatStat(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+
+	return d.String()
+}
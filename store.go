@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+	"github.com/valyala/fastjson"
+)
+
+var (
+	storeArg      = flag.String("store", "file", "where to persist state between scans: file[:path], or an external store such as https://host/path")
+	storeTokenArg = flag.String("storeToken", "", "bearer token sent to an http(s) store")
+)
+
+const defaultCachePath = ".cache.json"
+
+// storeFactories creates a stateStore for a -store spec, keyed by the spec's scheme (the part before the first colon).
+var storeFactories = map[string]func(spec string) (stateStore, error){
+	"file":       newFileStore,
+	"http":       newHTTPStore,
+	"https":      newHTTPStore,
+	"sqlite":     newSQLiteStore,
+	"postgres":   newPostgresStore,
+	"postgresql": newPostgresStore,
+	"redis":      newRedisStore,
+	"rediss":     newRedisStore,
+	"bolt":       newBoltStore,
+	"s3":         newS3Store,
+	"gs":         newGCSStore,
+	"memory":     newMemoryStore,
+	"etcd":       newEtcdStore,
+	"etcds":      newEtcdStore,
+	"consul":     newConsulStore,
+	"consuls":    newConsulStore,
+}
+
+func openStore(spec string) (stateStore, error) {
+	scheme, _, _ := strings.Cut(spec, ":")
+	factory, ok := storeFactories[scheme]
+	if !ok {
+		var schemes []string
+		for s := range storeFactories {
+			schemes = append(schemes, s)
+		}
+		sort.Strings(schemes)
+		return nil, fmt.Errorf("unknown store %q; supported stores are: %s", spec, strings.Join(schemes, ", "))
+	}
+
+	return factory(spec)
+}
+
+// stateLocker is implemented by stores that can coordinate several scanner replicas sharing the same state, so that
+// only one of them fetches and notifies for a partition at a time.
+type stateLocker interface {
+	// TryLock takes the partition's lock without waiting. ok is false if another replica already holds it.
+	TryLock(p statePartition) (unlock func(), ok bool, err error)
+}
+
+// marshalState serializes state for the stores that keep it as a single blob. The leaderboard body is
+// gzip-compressed, since embedding it as an escaped json string gets large for big leaderboards.
+func marshalState(state scanState) ([]byte, error) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(state.LastBody); err != nil {
+		return nil, fmt.Errorf("failed to compress leaderboard body: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress leaderboard body: %w", err)
+	}
+
+	jsonBytes, marshalErr := json.Marshal(map[string]any{
+		"last_read":       state.LastRead,
+		"last_body_gz":    compressed.Bytes(),
+		"etag":            state.ETag,
+		"last_modified":   state.LastModified,
+		"failed_sessions": state.FailedSessions,
+		"delivered":       state.Delivered,
+		"outbox":          state.Outbox,
+		"failures":        state.Failures,
+		"last_failure":    state.LastFailure,
+	})
+	if marshalErr != nil {
+		return nil, fmt.Errorf("failed to marshal state into json: %w", marshalErr)
+	}
+
+	return sealBytes(jsonBytes)
+}
+
+func unmarshalState(data []byte) (scanState, error) {
+	var state scanState
+	data, openErr := openBytes(data)
+	if openErr != nil {
+		return state, openErr
+	}
+
+	obj, parseErr := fastjson.ParseBytes(data)
+	if parseErr != nil {
+		return state, fmt.Errorf("error parsing cached state: %w", parseErr)
+	}
+
+	state.LastRead = obj.GetInt64("last_read")
+	if compressed := obj.GetStringBytes("last_body_gz"); compressed != nil {
+		body, decompressErr := decompressBody(compressed)
+		if decompressErr != nil {
+			return state, decompressErr
+		}
+		state.LastBody = body
+	} else {
+		// caches written before compression was added hold the body as a plain string
+		state.LastBody = obj.GetStringBytes("last_body")
+	}
+	state.ETag = string(obj.GetStringBytes("etag"))
+	state.LastModified = string(obj.GetStringBytes("last_modified"))
+	for _, v := range obj.GetArray("failed_sessions") {
+		state.FailedSessions = append(state.FailedSessions, string(v.GetStringBytes()))
+	}
+	if delivered := obj.GetObject("delivered"); delivered != nil {
+		state.Delivered = make(map[string]int64, delivered.Len())
+		delivered.Visit(func(key []byte, v *fastjson.Value) {
+			state.Delivered[string(key)] = v.GetInt64()
+		})
+	}
+	for _, v := range obj.GetArray("outbox") {
+		state.Outbox = append(state.Outbox, outboxEntry{Key: string(v.GetStringBytes("key")), Content: string(v.GetStringBytes("content")), At: v.GetInt64("at"), Queued: v.GetInt64("queued")})
+	}
+	state.Failures = obj.GetInt("failures")
+	state.LastFailure = string(obj.GetStringBytes("last_failure"))
+
+	return state, nil
+}
+
+// decompressBody reverses marshalState's compression of a base64-encoded, gzip-compressed body.
+func decompressBody(encoded []byte) ([]byte, error) {
+	compressed := make([]byte, base64.StdEncoding.DecodedLen(len(encoded)))
+	n, decodeErr := base64.StdEncoding.Decode(compressed, encoded)
+	if decodeErr != nil {
+		return nil, fmt.Errorf("error decoding cached leaderboard body: %w", decodeErr)
+	}
+
+	gz, gzErr := gzip.NewReader(bytes.NewReader(compressed[:n]))
+	if gzErr != nil {
+		return nil, fmt.Errorf("error decompressing cached leaderboard body: %w", gzErr)
+	}
+	defer gz.Close()
+
+	body, readErr := io.ReadAll(gz)
+	if readErr != nil {
+		return nil, fmt.Errorf("error decompressing cached leaderboard body: %w", readErr)
+	}
+
+	return body, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path, syncs it, and renames it over path, so a crash can
+// never leave path truncated or half-written.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir, base := filepath.Split(path)
+	if len(dir) == 0 {
+		dir = "."
+	}
+
+	tmp, createErr := os.CreateTemp(dir, base+".tmp*")
+	if createErr != nil {
+		return createErr
+	}
+	// harmless once the rename has happened
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// make the rename itself durable. not every platform supports syncing a directory, so this is best-effort.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	return nil
+}
+
+// fileStore keeps state in a json file on the local filesystem. This is the default.
+type fileStore struct {
+	path string
+	// ledgerMu guards ledger and its file, since the scan workers share the store.
+	ledgerMu sync.Mutex
+	// ledger is the delivery ledger, loaded on first use.
+	ledger map[string]int64
+}
+
+func newFileStore(spec string) (stateStore, error) {
+	_, path, _ := strings.Cut(spec, ":")
+	path = strings.TrimPrefix(path, "//")
+	if len(path) == 0 {
+		path = defaultCachePath
+	}
+
+	return &fileStore{path: path}, nil
+}
+
+func (s *fileStore) Load(p statePartition) (scanState, error) {
+	path := p.Apply(s.path)
+	cache, readErr := os.ReadFile(path)
+	if errors.Is(readErr, os.ErrNotExist) {
+		return s.loadLegacy(p)
+	}
+	if readErr != nil {
+		return scanState{}, fmt.Errorf("error reading %s: %w", path, readErr)
+	}
+
+	return unmarshalState(cache)
+}
+
+// loadLegacy reads a cache written before state was partitioned, but only if it holds the requested partition's
+// leaderboard. A private leaderboard's ID is its owner's ID, so that's enough to tell.
+func (s *fileStore) loadLegacy(p statePartition) (scanState, error) {
+	cache, readErr := os.ReadFile(s.path)
+	if readErr != nil {
+		return scanState{}, nil
+	}
+
+	state, parseErr := unmarshalState(cache)
+	if parseErr != nil {
+		return scanState{}, nil
+	}
+
+	body, bodyErr := fastjson.ParseBytes(state.LastBody)
+	if bodyErr != nil || string(body.GetStringBytes("event")) != p.Year || strconv.Itoa(body.GetInt("owner_id")) != p.Leaderboard {
+		return scanState{}, nil
+	}
+
+	logInfo("Using unpartitioned cache", s.path, "for", p.String(), "; it will be saved to", p.Apply(s.path), "from now on")
+	return state, nil
+}
+
+func (s *fileStore) Save(p statePartition, state scanState) error {
+	jsonBytes, marshalErr := marshalState(state)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	path := p.Apply(s.path)
+	if writeErr := writeFileAtomic(path, jsonBytes, 0644); writeErr != nil {
+		return fmt.Errorf("error writing %s: %w", path, writeErr)
+	}
+
+	return nil
+}
+
+// httpStore keeps state in an external service that answers GET and PUT on a single URL, such as a WebDAV share or a
+// simple key/value service. Nothing is written to the local filesystem, so it's suitable for ephemeral runners.
+type httpStore struct {
+	url   string
+	token string
+}
+
+func newHTTPStore(spec string) (stateStore, error) {
+	return &httpStore{url: spec, token: *storeTokenArg}, nil
+}
+
+func (s *httpStore) newRequest(p statePartition, method string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, p.Apply(s.url), body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s request for store: %w", method, err)
+	}
+	if len(s.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	return req, nil
+}
+
+func (s *httpStore) Load(p statePartition) (scanState, error) {
+	req, reqErr := s.newRequest(p, http.MethodGet, nil)
+	if reqErr != nil {
+		return scanState{}, reqErr
+	}
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return scanState{}, fmt.Errorf("error reading state from store: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return scanState{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return scanState{}, fmt.Errorf("unexpected status code %d reading state from store", resp.StatusCode)
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		return scanState{}, fmt.Errorf("error reading store response body: %w", readErr)
+	}
+
+	return unmarshalState(body)
+}
+
+func (s *httpStore) Save(p statePartition, state scanState) error {
+	jsonBytes, marshalErr := marshalState(state)
+	if marshalErr != nil {
+		return marshalErr
+	}
+
+	req, reqErr := s.newRequest(p, http.MethodPut, bytes.NewReader(jsonBytes))
+	if reqErr != nil {
+		return reqErr
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("error writing state to store: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d writing state to store", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Memory keeps everything in memory for the lifetime of the process and never touches the filesystem. It's
+// meant for daemons that take a fresh baseline on startup and don't care about restarts.
+type Memory struct {
+	mu         sync.Mutex
+	states     map[Partition]State
+	delivered  map[string]int64
+	snapshots  []Snapshot
+	nextSnapID int64
+}
+
+// NewMemory returns an empty Memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		states:    make(map[Partition]State),
+		delivered: make(map[string]int64),
+	}
+}
+
+func (s *Memory) Load(p Partition) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.states[p], nil
+}
+
+func (s *Memory) Save(p Partition, state State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the caller keeps appending to and reslicing its outbox, so don't share its backing array
+	state.Outbox = append([]OutboxEntry(nil), state.Outbox...)
+	s.states[p] = state
+	return nil
+}
+
+func (s *Memory) HasDelivered(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.delivered[key]
+	return ok, nil
+}
+
+func (s *Memory) MarkDelivered(key string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.delivered[key] = at.Unix()
+	return nil
+}
+
+func (s *Memory) Deliveries() (map[string]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deliveries := make(map[string]int64, len(s.delivered))
+	for key, at := range s.delivered {
+		deliveries[key] = at
+	}
+
+	return deliveries, nil
+}
+
+func (s *Memory) SaveSnapshot(snap Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextSnapID++
+	snap.ID = s.nextSnapID
+	s.snapshots = append(s.snapshots, snap)
+	return nil
+}
+
+func (s *Memory) Snapshots(p Partition) ([]Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var snaps []Snapshot
+	for _, snap := range s.snapshots {
+		if snap.Year == p.Year && snap.Leaderboard == p.Leaderboard {
+			snap.Body = nil
+			snaps = append(snaps, snap)
+		}
+	}
+
+	return snaps, nil
+}
+
+func (s *Memory) LoadSnapshot(id int64) (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, snap := range s.snapshots {
+		if snap.ID == id {
+			return snap, nil
+		}
+	}
+
+	return Snapshot{}, fmt.Errorf("no snapshot with id %d", id)
+}
+
+func (s *Memory) DeleteSnapshots(ids []int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.snapshots[:0]
+	for _, snap := range s.snapshots {
+		if !slices.Contains(ids, snap.ID) {
+			kept = append(kept, snap)
+		}
+	}
+	s.snapshots = kept
+
+	return nil
+}
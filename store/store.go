@@ -0,0 +1,112 @@
+// Package store defines how the scanner's state is persisted between scans, along with a store that keeps it in
+// memory.
+package store
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// State is everything that's persisted between scans.
+type State struct {
+	LastRead int64
+	LastBody []byte
+	// ETag and LastModified are the validators of the response LastBody came from, which let the next download be
+	// skipped if the leaderboard hasn't changed.
+	ETag         string
+	LastModified string
+	// FailedSessions holds the fingerprints of session cookies that have been rejected.
+	FailedSessions []string
+	// Delivered is the delivery ledger (idempotency key to unix delivery time), for stores that don't keep their own.
+	Delivered map[string]int64
+	// Outbox holds detected events that haven't been delivered yet.
+	Outbox []OutboxEntry
+	// Failures counts the scans in a row that failed to download the leaderboard or deliver what it announced, and
+	// LastFailure says what went wrong the last time. A scan that succeeds clears both.
+	Failures    int
+	LastFailure string
+}
+
+// OutboxEntry is a detected event waiting to be delivered. Events are written to the outbox in the same save as the
+// leaderboard body they were detected in, so a crash between detecting and sending them can never lose one; the
+// delivery ledger then stops a crash between sending and clearing one from announcing it twice.
+type OutboxEntry struct {
+	// Key is the entry's idempotency key in the delivery ledger.
+	Key     string `json:"key"`
+	Content string `json:"content"`
+	// At is the unix time the event happened, which is the order entries are delivered in.
+	At int64 `json:"at"`
+	// Queued is the unix time the entry was added to the outbox.
+	Queued int64 `json:"queued"`
+}
+
+// Enqueue appends events to the state's outbox, skipping any that are already waiting to be delivered.
+func (state *State) Enqueue(events []OutboxEntry) {
+	now := time.Now().Unix()
+	for _, event := range events {
+		if slices.ContainsFunc(state.Outbox, func(e OutboxEntry) bool { return e.Key == event.Key }) {
+			continue
+		}
+		event.Queued = now
+		state.Outbox = append(state.Outbox, event)
+	}
+}
+
+// Partition identifies whose state is being persisted. Every store keeps each (year, leaderboard) pair apart so that
+// changing either one never diffs one board's data against another's.
+type Partition struct {
+	Year        string
+	Leaderboard string
+}
+
+func (p Partition) String() string {
+	return p.Year + "-" + p.Leaderboard
+}
+
+// Apply substitutes the partition into a file path or object key. Paths containing {year} or {leaderboard}
+// placeholders have them replaced; otherwise the partition is inserted before the extension, so .cache.json becomes
+// .cache-2023-1234567.json.
+func (p Partition) Apply(path string) string {
+	if strings.Contains(path, "{year}") || strings.Contains(path, "{leaderboard}") {
+		return strings.NewReplacer("{year}", p.Year, "{leaderboard}", p.Leaderboard).Replace(path)
+	}
+
+	dir, file := "", path
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		dir, file = path[:idx+1], path[idx+1:]
+	}
+	// a leading dot is a hidden file, not an extension
+	ext := ""
+	if idx := strings.LastIndex(file, "."); idx > 0 {
+		file, ext = file[:idx], file[idx:]
+	}
+
+	return dir + file + "-" + p.String() + ext
+}
+
+// Store loads and saves State for a partition. Load returns an empty state and no error when nothing has been saved
+// yet.
+type Store interface {
+	Load(p Partition) (State, error)
+	Save(p Partition, state State) error
+}
+
+// Snapshot is a single leaderboard download as recorded by a History.
+type Snapshot struct {
+	// ID identifies the snapshot within the store that recorded it.
+	ID          int64
+	FetchedAt   time.Time
+	Year        string
+	Leaderboard string
+	// Body is the raw leaderboard json. It's left empty when listing snapshots.
+	Body []byte
+}
+
+// History is implemented by stores that keep every scan rather than only the most recent one.
+type History interface {
+	SaveSnapshot(snap Snapshot) error
+	// Snapshots lists every snapshot recorded for a partition, oldest first, without their bodies.
+	Snapshots(p Partition) ([]Snapshot, error)
+	LoadSnapshot(id int64) (Snapshot, error)
+}
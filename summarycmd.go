@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"pernicious.games/advent-of-code-scanner/aocclient"
+)
+
+// loadLeaderboard returns the configured leaderboard for commands that only read it. It uses the cached copy when
+// asked to or when the cache is recent enough that downloading again would be too soon; otherwise it downloads a fresh
+// copy. A fresh copy is never written back to the store, so the next scan still sees (and announces) everything that
+// changed since the last scan.
+func loadLeaderboard(cachedOnly bool) (*leaderboardData, leaderboardSettings, error) {
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return nil, leaderboardSettings{}, storeErr
+	}
+
+	return loadStoredLeaderboard(store, partition, cachedOnly)
+}
+
+// loadStoredLeaderboard is loadLeaderboard for a store that's already open.
+func loadStoredLeaderboard(store stateStore, partition statePartition, cachedOnly bool) (*leaderboardData, leaderboardSettings, error) {
+	board, boardErr := configuredBoard(partition.Leaderboard)
+	if boardErr != nil {
+		return nil, board, boardErr
+	}
+
+	state, loadErr := store.Load(partition)
+	if loadErr != nil {
+		return nil, board, loadErr
+	}
+
+	body := state.LastBody
+	fresh := time.Since(time.Unix(state.LastRead, 0)) < *minFetchIntervalArg
+	if !cachedOnly && !fresh {
+		if len(*sessionArg) == 0 {
+			return nil, board, errors.New("no session code provided to download the leaderboard with; use -cached to show the cached copy")
+		}
+
+		logDebug("Cached leaderboard is stale; downloading a fresh copy")
+		sessions := newSessionPool(*sessionArg, state.FailedSessions)
+		var downloadErr error
+		if body, _, downloadErr = sessions.download(context.Background(), partition.Year, partition.Leaderboard, aocclient.Validators{}); downloadErr != nil {
+			return nil, board, downloadErr
+		}
+	}
+	if len(body) == 0 {
+		return nil, board, fmt.Errorf("no cached leaderboard data for %s", partition)
+	}
+
+	leaderboard, buildErr := buildLeaderboard(body)
+	if buildErr != nil {
+		return nil, board, buildErr
+	}
+
+	return &leaderboard, board, nil
+}
+
+func runSummaryCommand(args []string) error {
+	fs := flag.NewFlagSet("summary", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "show the cached leaderboard instead of downloading a fresh copy")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	leaderboard, board, loadErr := loadLeaderboard(*cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	printSummary(leaderboard, board)
+	return nil
+}
+
+// printSummary writes the leaderboard's standings to stdout as an aligned table.
+func printSummary(leaderboard *leaderboardData, board leaderboardSettings) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Rank\tName\tStars\tScore\tLast star")
+	for _, member := range listedStandings(leaderboard, *yearArg) {
+		lastStar := "-"
+		if member.LastStarTimestamp > 0 {
+			lastStar = time.Unix(int64(member.LastStarTimestamp), 0).In(board.Location).Format("Jan 2 3:04:05pm")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", rankText(member.Rank), displayName(member.memberData), member.Stars, listedScoreText(member), lastStar)
+	}
+	w.Flush()
+}
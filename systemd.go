@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/daemon"
+)
+
+// notifySystemd tells systemd about the daemon's state when it's running as a Type=notify service, and does nothing
+// otherwise.
+func notifySystemd(state string) {
+	if _, err := daemon.SdNotify(false, state); err != nil {
+		logDebug("Error notifying systemd:", err)
+	}
+}
+
+// systemdReady tells systemd that the daemon has started, and pings the service's watchdog, if it has one, until ctx is
+// done. The pings stop once scheduler hasn't checked in for a whole watchdog interval, e.g. because a scan is stuck,
+// so that systemd restarts the daemon. A nil scheduler is always considered alive.
+func systemdReady(ctx context.Context, scheduler *scanScheduler) {
+	notifySystemd("READY=1")
+
+	interval, err := daemon.SdWatchdogEnabled(false)
+	if err != nil {
+		logWarn("Unable to read systemd's watchdog settings:", err)
+		return
+	}
+	if interval <= 0 {
+		return
+	}
+	if interval <= maxTickerSleep {
+		logWarn("systemd's watchdog interval of", interval, "is shorter than the scanner checks in; it'll be restarted over and over")
+	}
+
+	go func() {
+		// systemd recommends pinging twice as often as it requires
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		stuck := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if scheduler != nil {
+				if since := time.Since(scheduler.scans.lastCheckIn()); since > interval {
+					if !stuck {
+						logWarn("The scanner hasn't checked in for", since.Round(time.Second), "; letting systemd's watchdog restart it")
+					}
+					stuck = true
+					continue
+				}
+				stuck = false
+			}
+			notifySystemd("WATCHDOG=1")
+		}
+	}()
+}
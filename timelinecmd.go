@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// timelineStar is one star in the timeline export, which has a row for every star anyone has earned so that it loads
+// straight into a data frame.
+type timelineStar struct {
+	MemberID          int       `json:"member_id"`
+	Member            string    `json:"member"`
+	Day               int       `json:"day"`
+	Part              int       `json:"part"`
+	Timestamp         time.Time `json:"timestamp"`
+	SecondsFromUnlock int64     `json:"seconds_from_unlock"`
+	// Rank is where the member finished the part among everyone who has, starting from 1, or 0 for a member in
+	// excludeMembers, who doesn't count toward anyone else's.
+	Rank int `json:"rank"`
+}
+
+// mergeHistory adds every star and member in the stored snapshots of the leaderboard to it, so that members who've
+// left the leaderboard or been removed from it are still accounted for. A member's name comes from the latest copy of
+// the leaderboard they're in.
+func mergeHistory(history historyStore, partition statePartition, leaderboard *leaderboardData) (*leaderboardData, error) {
+	snaps, listErr := history.Snapshots(partition)
+	if listErr != nil {
+		return nil, listErr
+	}
+
+	merged := *leaderboard
+	merged.Members = append([]memberData(nil), leaderboard.Members...)
+	index := map[int]int{}
+	for idx, member := range merged.Members {
+		member.CompletionDayLevel = append([]completionDayData(nil), member.CompletionDayLevel...)
+		merged.Members[idx] = member
+		index[member.ID] = idx
+	}
+
+	// newest first, so that a name is only taken from a snapshot when nothing later has the member
+	for idx := len(snaps) - 1; idx >= 0; idx-- {
+		_, past, loadErr := loadSnapshotLeaderboard(history, snaps[idx].ID)
+		if loadErr != nil {
+			return nil, loadErr
+		}
+
+		for _, member := range past.Members {
+			at, ok := index[member.ID]
+			if !ok {
+				member.CompletionDayLevel = append([]completionDayData(nil), member.CompletionDayLevel...)
+				index[member.ID] = len(merged.Members)
+				merged.Members = append(merged.Members, member)
+				continue
+			}
+
+			days := merged.Members[at].CompletionDayLevel
+			for dayIdx := 0; dayIdx < len(member.CompletionDayLevel) && dayIdx < len(days); dayIdx++ {
+				if days[dayIdx].Part1 == nil {
+					days[dayIdx].Part1 = member.CompletionDayLevel[dayIdx].Part1
+				}
+				if days[dayIdx].Part2 == nil {
+					days[dayIdx].Part2 = member.CompletionDayLevel[dayIdx].Part2
+				}
+			}
+		}
+	}
+
+	return &merged, nil
+}
+
+// timelineStars lists every star on the leaderboard by day, part, and the order they were earned in.
+func timelineStars(leaderboard *leaderboardData, year string, board leaderboardSettings) []timelineStar {
+	var stars []timelineStar
+	for day := 1; day <= eventDays(year); day++ {
+		unlock := dayUnlock(year, day)
+		for part := 1; part <= 2; part++ {
+			finishers := dayFinishers(leaderboard, day, part)
+			ranks := finisherRanks(finishers)
+			for idx, f := range finishers {
+				stars = append(stars, timelineStar{
+					MemberID:          f.Member.ID,
+					Member:            displayName(f.Member),
+					Day:               day,
+					Part:              part,
+					Timestamp:         f.At.In(board.Location),
+					SecondsFromUnlock: int64(f.At.Sub(unlock) / time.Second),
+					Rank:              ranks[idx],
+				})
+			}
+		}
+	}
+
+	return stars
+}
+
+func timelineCSV(w io.Writer, stars []timelineStar) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"member_id", "member", "day", "part", "timestamp", "seconds_from_unlock", "rank"})
+	for _, star := range stars {
+		cw.Write([]string{
+			strconv.Itoa(star.MemberID),
+			star.Member,
+			strconv.Itoa(star.Day),
+			strconv.Itoa(star.Part),
+			star.Timestamp.Format(time.RFC3339),
+			strconv.FormatInt(star.SecondsFromUnlock, 10),
+			strconv.Itoa(star.Rank),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// timelineJSONLines writes a json object per line, which is what pandas' read_json(lines=True), R's
+// jsonlite::stream_in, and tools that convert to Parquet expect of a large dataset.
+func timelineJSONLines(w io.Writer, stars []timelineStar) error {
+	bw := bufio.NewWriter(w)
+	for _, star := range stars {
+		line, _ := json.Marshal(star)
+		bw.Write(line)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+func runTimelineCommand(args []string) error {
+	fs := flag.NewFlagSet("timeline", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	format := fs.String("format", "csv", "output format: csv or jsonl")
+	out := fs.String("o", "", "file to write to instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var write func(io.Writer, []timelineStar) error
+	switch *format {
+	case "csv":
+		write = timelineCSV
+	case "jsonl":
+		write = timelineJSONLines
+	default:
+		return fmt.Errorf("unknown timeline format %q; expected csv or jsonl", *format)
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	leaderboard, board, loadErr := loadStoredLeaderboard(store, partition, *cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+	if history := historyFor(store); history != nil {
+		if leaderboard, loadErr = mergeHistory(history, partition, leaderboard); loadErr != nil {
+			return loadErr
+		}
+	} else {
+		logInfo("The configured store doesn't keep history, so members who have left the leaderboard won't be included")
+	}
+
+	stars := timelineStars(leaderboard, *yearArg, board)
+
+	if len(*out) == 0 {
+		return write(os.Stdout, stars)
+	}
+
+	f, createErr := os.Create(*out)
+	if createErr != nil {
+		return fmt.Errorf("error creating %s: %w", *out, createErr)
+	}
+	if err := write(f, stars); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+func runTopCommand(args []string) error {
+	fs := flag.NewFlagSet("top", flag.ContinueOnError)
+	cachedOnly := fs.Bool("cached", false, "use the cached leaderboard instead of downloading a fresh copy")
+	day := fs.Int("day", 0, "the day to rank (1-25); defaults to the latest day anyone has a star for")
+	part := fs.Int("part", 2, "the part to rank (1 or 2)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *day < 0 || *day > 25 {
+		return fmt.Errorf("day %d is out of range; expected 1-25", *day)
+	}
+	if *part != 1 && *part != 2 {
+		return fmt.Errorf("part %d is out of range; expected 1 or 2", *part)
+	}
+
+	leaderboard, board, loadErr := loadLeaderboard(*cachedOnly)
+	if loadErr != nil {
+		return loadErr
+	}
+
+	if *day == 0 {
+		if *day = exportedDays(leaderboard); *day == 0 {
+			return errors.New("nobody on the leaderboard has any stars yet")
+		}
+	}
+
+	finishers := dayFinishers(leaderboard, *day, *part)
+	if len(finishers) == 0 {
+		fmt.Printf("Nobody has completed day %d part %d yet.\n", *day, *part)
+		return nil
+	}
+
+	unlock := dayUnlock(*yearArg, *day)
+	fmt.Printf("Day %d part %d:\n", *day, *part)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Rank\tName\tTime\tCompleted at")
+	ranks := finisherRanks(finishers)
+	for idx, finisher := range finishers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rankText(ranks[idx]), displayName(finisher.Member), formatElapsed(finisher.At.Sub(unlock)), finisher.At.In(board.Location).Format("Jan 2 3:04:05pm"))
+	}
+	w.Flush()
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// minTrendDays is how many days a member has to have finished for a trend in their times to mean anything.
+	minTrendDays = 3
+	// minImprovedDays is how many days a member has to have finished both during a week and before it to be the
+	// week's most improved.
+	minImprovedDays = 2
+)
+
+// relativeSolve is how long a member took to finish a day compared to how long the leaderboard usually took, as log2
+// of their time over the median of everyone who finished it: 0 is the median, -1 twice as fast, and 1 twice as slow.
+type relativeSolve struct {
+	Day      int
+	Unlock   time.Time
+	Relative float64
+}
+
+// relativeSolveTimes is every day each member finished both parts of, compared to the leaderboard's median for it,
+// by member ID and in day order. Comparing to the median rather than using the times themselves keeps harder days
+// from looking like a member slowing down.
+func relativeSolveTimes(leaderboard *leaderboardData, year string) map[int][]relativeSolve {
+	solves := map[int][]relativeSolve{}
+	for day := 1; day <= eventDays(year); day++ {
+		unlock := dayUnlock(year, day)
+		finishers := dayFinishers(leaderboard, day, 2)
+		times := make([]time.Duration, len(finishers))
+		for idx, f := range finishers {
+			times[idx] = f.At.Sub(unlock)
+		}
+		median := summarizeSolveTimes(times).Median
+		if median <= 0 {
+			continue
+		}
+
+		for idx, f := range finishers {
+			if times[idx] <= 0 {
+				continue
+			}
+			solves[f.Member.ID] = append(solves[f.Member.ID], relativeSolve{
+				Day:      day,
+				Unlock:   unlock,
+				Relative: math.Log2(float64(times[idx]) / float64(median)),
+			})
+		}
+	}
+	return solves
+}
+
+// solveTrend is how much a member's times relative to the median changed each week over the days they finished, as
+// a fraction: -0.1 is getting 10% faster a week, and 0.25 is getting 25% slower. It's fitted to every day rather than
+// comparing the first to the last, so one bad or lucky day doesn't make the trend. ok is false if there aren't enough
+// days to tell.
+func solveTrend(solves []relativeSolve) (perWeek float64, ok bool) {
+	if len(solves) < minTrendDays {
+		return 0, false
+	}
+
+	var meanDay, meanRelative float64
+	for _, solve := range solves {
+		meanDay += float64(solve.Day)
+		meanRelative += solve.Relative
+	}
+	meanDay /= float64(len(solves))
+	meanRelative /= float64(len(solves))
+
+	var covariance, variance float64
+	for _, solve := range solves {
+		covariance += (float64(solve.Day) - meanDay) * (solve.Relative - meanRelative)
+		variance += (float64(solve.Day) - meanDay) * (float64(solve.Day) - meanDay)
+	}
+	slope := covariance / variance
+	return math.Exp2(slope*7) - 1, true
+}
+
+// formatTrend describes a trend from solveTrend.
+func formatTrend(perWeek float64) string {
+	if perWeek < 0 {
+		return fmt.Sprintf("%.0f%% faster/wk", -100*perWeek)
+	}
+	return fmt.Sprintf("%.0f%% slower/wk", 100*perWeek)
+}
+
+// mostImproved finds the member whose times relative to the median improved the most from the days before since to
+// the days that unlocked after it, along with how many times the median they took before and after, on average. ok
+// is false if nobody finished enough days in both or nobody improved. Members in excludeMembers aren't in the running.
+func mostImproved(leaderboard *leaderboardData, year string, since time.Time) (member memberData, before, after float64, ok bool) {
+	solves := relativeSolveTimes(leaderboard, year)
+	best := 0.0
+	// going through the standings keeps ties going to whoever's ahead
+	for _, candidate := range sortedStandings(rankedLeaderboard(leaderboard)) {
+		var earlier, recent []float64
+		for _, solve := range solves[candidate.ID] {
+			if solve.Unlock.Before(since) {
+				earlier = append(earlier, solve.Relative)
+			} else {
+				recent = append(recent, solve.Relative)
+			}
+		}
+		if len(earlier) < minImprovedDays || len(recent) < minImprovedDays {
+			continue
+		}
+
+		earlierMean, recentMean := mean(earlier), mean(recent)
+		improvement := earlierMean - recentMean
+		if improvement <= best {
+			continue
+		}
+		best = improvement
+		member, before, after, ok = candidate, math.Exp2(earlierMean), math.Exp2(recentMean), true
+	}
+	return member, before, after, ok
+}
+
+func mean(values []float64) float64 {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// mostImprovedSection names the week's most improved member for a weekly recap, or is empty if there isn't one.
+func mostImprovedSection(leaderboard *leaderboardData, year string, weekAgo time.Time) string {
+	member, before, after, ok := mostImproved(leaderboard, year, weekAgo)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("\n:chart_with_upwards_trend: Most improved: %s, who took %.1f× the median time to finish a day before this week and %.1f× this week.\n", displayName(member), before, after)
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// tuiRefreshInterval is how often the tui re-reads the store to pick up new scans.
+	tuiRefreshInterval = 5 * time.Second
+	tuiMaxEvents       = 10
+)
+
+var (
+	tuiTitleStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#00cc00"))
+	tuiHeaderStyle = lipgloss.NewStyle().Bold(true)
+	tuiDimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	tuiGoldStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#ffff66"))
+	tuiSilverStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#9999cc"))
+	tuiErrorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555"))
+
+	// notifications are markdown; the tui shows them as plain text
+	markdownLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	emojiPattern        = regexp.MustCompile(`:[a-z_]+: ?`)
+)
+
+type tuiEvent struct {
+	At   time.Time
+	Text string
+}
+
+type tuiModel struct {
+	store     stateStore
+	partition statePartition
+	board     leaderboardSettings
+
+	state       scanState
+	leaderboard *leaderboardData
+	events      []tuiEvent
+	loaded      bool
+	err         error
+
+	// day is the day whose finishers are shown, or 0 to show the overall standings
+	day int
+}
+
+type tuiTickMsg time.Time
+
+type tuiLoadedMsg struct {
+	state  scanState
+	events []tuiEvent
+	err    error
+}
+
+func plainText(content string) string {
+	return strings.TrimSpace(emojiPattern.ReplaceAllString(markdownLinkPattern.ReplaceAllString(content, "$1"), ""))
+}
+
+func tuiTick() tea.Cmd {
+	return tea.Tick(tuiRefreshInterval, func(t time.Time) tea.Msg { return tuiTickMsg(t) })
+}
+
+// load re-reads the store. On the first load, recent events are rebuilt from the last few snapshots of history so
+// that there's something to show before the next scan.
+func (m tuiModel) load(first bool) tea.Cmd {
+	return func() tea.Msg {
+		state, loadErr := m.store.Load(m.partition)
+		if loadErr != nil || !first {
+			return tuiLoadedMsg{state: state, err: loadErr}
+		}
+
+		var events []tuiEvent
+		if history := historyFor(m.store); history != nil {
+			if snaps, listErr := history.Snapshots(m.partition); listErr == nil {
+				if len(snaps) > tuiMaxEvents {
+					snaps = snaps[len(snaps)-tuiMaxEvents:]
+				}
+
+				var prev *leaderboardData
+				for _, listed := range snaps {
+					_, curr, snapErr := loadSnapshotLeaderboard(history, listed.ID)
+					if snapErr != nil {
+						continue
+					}
+					if prev != nil {
+						for _, event := range detectEvents(prev, curr, m.partition.Year, m.board) {
+							events = append(events, tuiEvent{At: listed.FetchedAt, Text: plainText(event.Content)})
+						}
+					}
+					prev = curr
+				}
+			}
+		}
+
+		return tuiLoadedMsg{state: state, events: events}
+	}
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return tea.Batch(m.load(true), tuiTick())
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "right", "l":
+			if m.day < 25 {
+				m.day++
+			}
+		case "left", "h":
+			if m.day > 0 {
+				m.day--
+			}
+		case "esc", "0":
+			m.day = 0
+		}
+
+	case tuiTickMsg:
+		return m, tea.Batch(m.load(false), tuiTick())
+
+	case tuiLoadedMsg:
+		m.err = msg.err
+		if msg.err != nil {
+			return m, nil
+		}
+
+		for _, event := range msg.events {
+			m.events = append([]tuiEvent{event}, m.events...)
+		}
+
+		if len(msg.state.LastBody) > 0 && (!m.loaded || !bytes.Equal(msg.state.LastBody, m.state.LastBody)) {
+			leaderboard, buildErr := buildLeaderboard(msg.state.LastBody)
+			if buildErr != nil {
+				m.err = buildErr
+				return m, nil
+			}
+
+			if m.leaderboard != nil {
+				for _, event := range detectEvents(m.leaderboard, &leaderboard, m.partition.Year, m.board) {
+					m.events = append([]tuiEvent{{At: time.Unix(msg.state.LastRead, 0), Text: plainText(event.Content)}}, m.events...)
+				}
+			}
+			m.leaderboard = &leaderboard
+		}
+		if len(m.events) > tuiMaxEvents {
+			m.events = m.events[:tuiMaxEvents]
+		}
+		m.state = msg.state
+		m.loaded = true
+	}
+
+	return m, nil
+}
+
+// nextScan estimates when the daemon will next download the leaderboard: its next scheduled tick that isn't too soon
+// after the last download.
+func (m tuiModel) nextScan(now time.Time) time.Time {
+	after := now
+	if earliest := time.Unix(m.state.LastRead, 0).Add(fetchInterval(m.partition.Year, now)); earliest.After(after) {
+		after = earliest.Add(-time.Second)
+	}
+
+	return nextScanTick(m.partition.Year, after)
+}
+
+func (m tuiModel) View() string {
+	var sb strings.Builder
+	now := time.Now()
+
+	sb.WriteString(tuiTitleStyle.Render(fmt.Sprintf("Advent of Code %s · leaderboard %s", m.partition.Year, m.partition.Leaderboard)) + "\n")
+	if m.state.LastRead > 0 {
+		sb.WriteString(tuiDimStyle.Render(fmt.Sprintf("Last scan %s ago · next scan around %s",
+			now.Sub(time.Unix(m.state.LastRead, 0)).Round(time.Second),
+			m.nextScan(now).In(m.board.Location).Format("3:04pm"),
+		)) + "\n")
+	}
+	if m.err != nil {
+		sb.WriteString(tuiErrorStyle.Render("Error: "+m.err.Error()) + "\n")
+	}
+	sb.WriteString("\n")
+
+	switch {
+	case !m.loaded:
+		sb.WriteString("Loading...\n")
+	case m.leaderboard == nil:
+		sb.WriteString("No leaderboard data has been scanned yet.\n")
+	case m.day == 0:
+		m.viewStandings(&sb)
+	default:
+		m.viewDay(&sb)
+	}
+
+	sb.WriteString("\n" + tuiHeaderStyle.Render("Recent events") + "\n")
+	if len(m.events) == 0 {
+		sb.WriteString(tuiDimStyle.Render("Nothing yet") + "\n")
+	}
+	for _, event := range m.events {
+		sb.WriteString(tuiDimStyle.Render(event.At.In(m.board.Location).Format("Jan 2 3:04pm")) + "  " + event.Text + "\n")
+	}
+
+	sb.WriteString("\n" + tuiDimStyle.Render("←/→ pick a day · esc standings · q quit") + "\n")
+	return sb.String()
+}
+
+func (m tuiModel) viewStandings(sb *strings.Builder) {
+	days := exportedDays(m.leaderboard)
+	dayHeader := ""
+	for day := 1; day <= days; day++ {
+		dayHeader += fmt.Sprint(day % 10)
+	}
+
+	sb.WriteString(tuiHeaderStyle.Render(fmt.Sprintf("%4s  %-24s %5s %8s  %s", "Rank", "Name", "Stars", "Score", dayHeader)) + "\n")
+	for _, member := range listedStandings(m.leaderboard, m.partition.Year) {
+		var stars strings.Builder
+		for dayIdx := 0; dayIdx < days; dayIdx++ {
+			day := member.CompletionDayLevel[dayIdx]
+			switch {
+			case day.Part2 != nil:
+				stars.WriteString(tuiGoldStyle.Render("*"))
+			case day.Part1 != nil:
+				stars.WriteString(tuiSilverStyle.Render("*"))
+			default:
+				stars.WriteString(tuiDimStyle.Render("."))
+			}
+		}
+
+		name := []rune(displayName(member.memberData))
+		if len(name) > 24 {
+			name = append(name[:23], '…')
+		}
+		fmt.Fprintf(sb, "%4s  %-24s %5d %8s  %s\n", rankText(member.Rank), string(name), member.Stars, listedScoreText(member), stars.String())
+	}
+}
+
+func (m tuiModel) viewDay(sb *strings.Builder) {
+	unlock := dayUnlock(m.partition.Year, m.day)
+	sb.WriteString(tuiHeaderStyle.Render(fmt.Sprintf("Day %d", m.day)) + "\n")
+	if unlock.After(time.Now()) {
+		sb.WriteString(fmt.Sprintf("Unlocks %s\n", unlock.In(m.board.Location).Format("Jan 2 3:04pm")))
+		return
+	}
+
+	part1 := dayFinishers(m.leaderboard, m.day, 1)
+	part2 := dayFinishers(m.leaderboard, m.day, 2)
+	if len(part1) == 0 {
+		sb.WriteString("Nobody has a star for this day yet.\n")
+		return
+	}
+
+	sb.WriteString(tuiHeaderStyle.Render(fmt.Sprintf("%4s  %-24s %10s %10s", "Rank", "Name", "Part 1", "Part 2")) + "\n")
+	// ranked by part 2 first, then by part 1 for anyone who only has the first star
+	ranked := append([]dayFinisher{}, part2...)
+	for _, finisher := range part1 {
+		if !arrayContains(ranked, func(f dayFinisher) bool { return f.Member.ID == finisher.Member.ID }) {
+			ranked = append(ranked, finisher)
+		}
+	}
+	ranks := finisherRanks(ranked)
+	for idx, finisher := range ranked {
+		p1 := arrayFind(part1, func(f dayFinisher) bool { return f.Member.ID == finisher.Member.ID })
+		p2 := arrayFind(part2, func(f dayFinisher) bool { return f.Member.ID == finisher.Member.ID })
+		p2Time := "-"
+		if p2 != nil {
+			p2Time = formatElapsed(p2.At.Sub(unlock))
+		}
+		fmt.Fprintf(sb, "%4s  %-24s %10s %10s\n", rankText(ranks[idx]), displayName(finisher.Member), formatElapsed(p1.At.Sub(unlock)), p2Time)
+	}
+}
+
+func runTUICommand(args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: tui")
+	}
+
+	store, partition, storeErr := openConfiguredStore()
+	if storeErr != nil {
+		return storeErr
+	}
+	if _, inMemory := store.(*memoryStore); inMemory {
+		return errors.New("the tui reads what a separately running scanner has stored, so it can't be used with the memory store")
+	}
+
+	board, boardErr := configuredBoard(partition.Leaderboard)
+	if boardErr != nil {
+		return boardErr
+	}
+
+	_, runErr := tea.NewProgram(tuiModel{store: store, partition: partition, board: board}, tea.WithAltScreen()).Run()
+	return runErr
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// the site's header shows the logged-in user as <div class="user">name <span class="star-count">...
+	sessionUserPattern = regexp.MustCompile(`<div class="user">([^<]*)`)
+	// each private leaderboard the user can view is linked from its row on the private leaderboards page
+	privateBoardPattern = regexp.MustCompile(`(?s)/leaderboard/private/view/(\d+)"[^>]*>\[View\](.*?)</div>`)
+	htmlTagPattern      = regexp.MustCompile(`<[^>]*>`)
+)
+
+// privateBoard is a private leaderboard that a session can view.
+type privateBoard struct {
+	ID   string
+	Name string
+}
+
+// checkSession makes a single request to the private leaderboards page as the given session, and returns the account
+// it belongs to and the private leaderboards it can view.
+func checkSession(year, session string) (string, []privateBoard, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("https://adventofcode.com/%s/leaderboard/private", year), nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("error creating request for private leaderboards: %w", err)
+	}
+	req.AddCookie(&http.Cookie{Name: "session", Value: session})
+
+	resp, reqErr := httpClient().Do(req)
+	if reqErr != nil {
+		return "", nil, fmt.Errorf("error requesting private leaderboards: %w", reqErr)
+	}
+	defer resp.Body.Close()
+
+	page, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		return "", nil, fmt.Errorf("error reading response body: %w", readErr)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("unexpected status code %d requesting private leaderboards", resp.StatusCode)
+	}
+
+	user := sessionUserPattern.FindSubmatch(page)
+	if user == nil {
+		return "", nil, errSessionRejected
+	}
+
+	var boards []privateBoard
+	for _, match := range privateBoardPattern.FindAllSubmatch(page, -1) {
+		name := strings.Join(strings.Fields(html.UnescapeString(htmlTagPattern.ReplaceAllString(string(match[2]), " "))), " ")
+		boards = append(boards, privateBoard{ID: string(match[1]), Name: name})
+	}
+
+	return strings.TrimSpace(html.UnescapeString(string(user[1]))), boards, nil
+}
+
+func runValidateSessionCommand(args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: validate-session")
+	}
+
+	sessions := newSessionPool(*sessionArg, nil).sessions
+	if len(sessions) == 0 {
+		return errors.New("no session code provided")
+	}
+
+	numInvalid := 0
+	for idx, session := range sessions {
+		if len(sessions) > 1 {
+			fmt.Printf("Session %d of %d (%s):\n", idx+1, len(sessions), sessionFingerprint(session))
+		}
+
+		account, boards, checkErr := checkSession(*yearArg, session)
+		if checkErr != nil {
+			numInvalid++
+			if errors.Is(checkErr, errSessionRejected) {
+				fmt.Println("  Invalid: adventofcode.com doesn't recognize this session; it may have expired.")
+			} else {
+				fmt.Println("  Unable to check:", checkErr)
+			}
+			continue
+		}
+
+		fmt.Printf("  Valid: logged in as %s\n", account)
+		if len(boards) == 0 {
+			fmt.Println("  No private leaderboards are visible for", *yearArg)
+		}
+		for _, board := range boards {
+			configured := ""
+			if slices.Contains(configuredLeaderboards(), board.ID) {
+				configured = " (configured)"
+			}
+			fmt.Printf("  Can view leaderboard %s: %s%s\n", board.ID, board.Name, configured)
+		}
+		for _, id := range configuredLeaderboards() {
+			if !arrayContains(boards, func(b privateBoard) bool { return b.ID == id }) {
+				fmt.Printf("  Warning: the configured leaderboard %s isn't one of them\n", id)
+			}
+		}
+	}
+
+	if numInvalid > 0 {
+		return fmt.Errorf("%d of %d sessions aren't usable", numInvalid, len(sessions))
+	}
+
+	return nil
+}
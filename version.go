@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// These are set at build time, e.g.
+// go build -ldflags "-X main.version=1.2.3 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// buildMetadata returns the version, commit, and build date, falling back to what the go toolchain embedded for
+// anything that wasn't set at build time (where the commit time stands in for the build date).
+func buildMetadata() (string, string, string) {
+	v, c, d := version, commit, buildDate
+	dirty := false
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "dev" && len(info.Main.Version) > 0 && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && len(c) == 0:
+				c = setting.Value
+			case setting.Key == "vcs.time" && len(d) == 0:
+				d = setting.Value
+			case setting.Key == "vcs.modified":
+				dirty = setting.Value == "true"
+			}
+		}
+	}
+	if dirty && len(commit) == 0 && len(c) > 0 {
+		c += "-dirty"
+	}
+	if len(c) == 0 {
+		c = "unknown"
+	}
+	if len(d) == 0 {
+		d = "unknown"
+	}
+
+	return v, c, d
+}
+
+func printVersion() {
+	v, c, d := buildMetadata()
+	fmt.Printf("advent-of-code-scanner %s\ncommit: %s\nbuilt: %s\ngo: %s %s/%s\n", v, c, d, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
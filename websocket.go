@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsStandingsInterval is how often a websocket client is sent the full standings, on top of when it connects.
+	wsStandingsInterval = time.Minute
+	wsPingInterval      = 30 * time.Second
+	wsWriteTimeout      = 10 * time.Second
+)
+
+var wsUpgrader = websocket.Upgrader{CheckOrigin: wsCheckOrigin}
+
+// wsCheckOrigin lets scoreboard pages hosted anywhere connect to an open server, since the feed has nothing the api
+// doesn't. A browser sends any basic auth it has cached for the server along with a websocket handshake, whatever page
+// started it, so once a token is needed only pages on the server itself may connect; programs that aren't browsers
+// don't send an Origin and aren't affected.
+func wsCheckOrigin(r *http.Request) bool {
+	if len(*readTokenArg) == 0 && len(*adminTokenArg) == 0 {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return true
+	}
+	u, parseErr := url.Parse(origin)
+	if parseErr != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// wsFrame is a message sent to websocket clients: either a live event or the full standings.
+type wsFrame struct {
+	// Type is "event" or "standings".
+	Type      string          `json:"type"`
+	Event     *liveEvent      `json:"event,omitempty"`
+	Standings *apiLeaderboard `json:"standings,omitempty"`
+}
+
+// handleWebSocket pushes the same live events as /events to a websocket client, along with the full standings when it
+// connects and every wsStandingsInterval after.
+func (s *server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, upgradeErr := wsUpgrader.Upgrade(w, r, nil)
+	if upgradeErr != nil {
+		// the upgrader has already responded with the error
+		logDebug("Error upgrading websocket connection:", upgradeErr)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := s.events.subscribe()
+	defer unsubscribe()
+
+	// clients aren't expected to send anything, but reading is what notices them closing the connection
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(frame wsFrame) bool {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := conn.WriteJSON(frame); err != nil {
+			logDebug("Error writing to websocket client:", err)
+			return false
+		}
+		return true
+	}
+	sendStandings := func() bool {
+		leaderboard, state, loadErr := s.cachedLeaderboard()
+		if loadErr != nil {
+			logError("Error loading leaderboard for websocket client:", loadErr)
+			return true
+		}
+		if leaderboard == nil {
+			return true
+		}
+		standings := s.apiLeaderboard(leaderboard, time.Unix(state.LastRead, 0))
+		return send(wsFrame{Type: "standings", Standings: &standings})
+	}
+
+	if !sendStandings() {
+		return
+	}
+
+	standingsTicker := time.NewTicker(wsStandingsInterval)
+	defer standingsTicker.Stop()
+	pingTicker := time.NewTicker(wsPingInterval)
+	defer pingTicker.Stop()
+	for {
+		ok := true
+		select {
+		case <-closed:
+			return
+		case <-r.Context().Done():
+			return
+		case event := <-events:
+			ok = send(wsFrame{Type: "event", Event: &event})
+		case <-standingsTicker.C:
+			ok = sendStandings()
+		case <-pingTicker.C:
+			ok = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)) == nil
+		}
+		if !ok {
+			return
+		}
+	}
+}